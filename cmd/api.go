@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/logging"
+	"github.com/nu0ma/spalidate/internal/report"
+	"github.com/nu0ma/spalidate/internal/server"
+	"github.com/nu0ma/spalidate/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var apiListen string
+
+var apiCmd = &cobra.Command{
+	Use:   "api",
+	Short: "Run an HTTP server that validates configs submitted by clients",
+	Long: `Starts an HTTP server with a POST /validate endpoint. The request body is a YAML or JSON
+config; the response is the JSON report (200 when passing, 422 when validation fails,
+400 for an invalid config). An optional ?dataset= query parameter selects a dataset.
+GET /report.json and /healthz reflect the most recent request; /livez and /readyz
+are probes. On SIGTERM in-flight requests complete before the server exits.`,
+	Args: cobra.NoArgs,
+	RunE: runAPI,
+}
+
+func init() {
+	apiCmd.Flags().StringVar(&apiListen, "listen", "localhost:8080", "HTTP listen address; submitted configs are not authenticated")
+	rootCmd.AddCommand(apiCmd)
+}
+
+func runAPI(cmd *cobra.Command, args []string) error {
+	if cleanup != nil {
+		defer cleanup()
+	}
+	ctx, stop := signalContext()
+	defer stop()
+
+	spannerClient, err := newSpannerClient(context.Background())
+	if err != nil {
+		return fmt.Errorf("creating spanner client: %w", err)
+	}
+	defer spannerClient.Close()
+
+	srv := server.New()
+	srv.Validate = func(ctx context.Context, cfg *config.Config) *report.Report {
+		start := time.Now()
+		v := validator.NewValidator(cfg, spannerClient, validator.WithShowMatches(showMatches), validator.WithMemoryStats(true),
+			validator.WithAnchor(anchorTime))
+		res := v.Run(ctx)
+		logging.L().Info("Validated submitted config", "tables", len(res.Tables), "passed", res.Err() == nil)
+		r := report.FromResult(res, start, time.Since(start))
+		r.Metadata = runMetadata(cfg, database)
+		return r
+	}
+
+	httpServer, errCh := startHTTP(srv, apiListen)
+	logging.L().Info("Serving validation API", "listen", apiListen)
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("http server: %w", err)
+	case <-ctx.Done():
+		shutdownHTTP(srv, httpServer)
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nu0ma/spalidate/internal/logging"
+	"github.com/nu0ma/spalidate/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	baselineOut    string
+	baselineTables []string
+	baselinePath   string
+)
+
+var recordCmd = &cobra.Command{
+	Use:   "record --out <baseline.json>",
+	Short: "Record per-table row counts and hashes as a baseline",
+	Long: `Captures the row count and a hash of every row for each table (or the tables given with --table)
+and writes them to a JSON baseline. Use "spalidate check" later to detect data drift.`,
+	Args: cobra.NoArgs,
+	RunE: runRecord,
+}
+
+var checkCmd = &cobra.Command{
+	Use:   "check --baseline <baseline.json>",
+	Short: "Report data drift since a recorded baseline",
+	Long: `Compares the current content of the baseline's tables with the baseline and reports tables
+whose rows were added, removed or modified, or that no longer exist.`,
+	Args: cobra.NoArgs,
+	RunE: runCheck,
+}
+
+func init() {
+	recordCmd.Flags().StringVar(&baselineOut, "out", "", "File to write the baseline to (required)")
+	recordCmd.Flags().StringSliceVar(&baselineTables, "table", nil, "Tables to record (default: all tables)")
+	if err := recordCmd.MarkFlagRequired("out"); err != nil {
+		panic(fmt.Sprintf("failed to mark out flag as required: %v", err))
+	}
+	checkCmd.Flags().StringVar(&baselinePath, "baseline", "", "Baseline file written by record (required)")
+	if err := checkCmd.MarkFlagRequired("baseline"); err != nil {
+		panic(fmt.Sprintf("failed to mark baseline flag as required: %v", err))
+	}
+	rootCmd.AddCommand(recordCmd, checkCmd)
+}
+
+func runRecord(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	spannerClient, err := newSpannerClient(ctx)
+	if err != nil {
+		return fmt.Errorf("creating spanner client: %w", err)
+	}
+	defer spannerClient.Close()
+
+	b, err := validator.RecordBaseline(ctx, spannerClient, baselineTables)
+	if err != nil {
+		return fmt.Errorf("recording baseline: %w", err)
+	}
+	if err := b.WriteFile(baselineOut); err != nil {
+		return fmt.Errorf("writing baseline: %w", err)
+	}
+	logging.L().Info("Recorded baseline", "tables", len(b.Tables), "out", baselineOut)
+	fmt.Printf("Recorded baseline of %d tables to %s\n", len(b.Tables), baselineOut)
+	return nil
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	base, err := validator.LoadBaseline(baselinePath)
+	if err != nil {
+		return err
+	}
+
+	spannerClient, err := newSpannerClient(ctx)
+	if err != nil {
+		return fmt.Errorf("creating spanner client: %w", err)
+	}
+	defer spannerClient.Close()
+
+	logging.L().Info("Checking for drift", "baseline", baselinePath, "recordedAt", base.RecordedAt)
+	drifts, err := validator.CheckBaseline(ctx, spannerClient, base)
+	if err != nil {
+		return fmt.Errorf("checking baseline: %w", err)
+	}
+	out, closeReport, err := openReport()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = closeReport() }()
+	if err := validator.ReportDrift(out, drifts); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, "No drift since baseline")
+	return err
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nu0ma/spalidate/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchRows        []int
+	benchColumns     int
+	benchJSON        bool
+	benchMaxNsPerRow int64
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure decoding and comparison speed on synthetic tables",
+	Long: `Generates tables of the given sizes in memory, decodes their rows as queries do and
+validates them against matching expectations, printing the time spent per phase. With
+--max-ns-per-row it fails when a table takes longer per row, as a regression gate. No
+database connection is made.`,
+	Args:        cobra.NoArgs,
+	Hidden:      true,
+	Annotations: map[string]string{offlineAnnotation: "true"},
+	RunE:        runBench,
+}
+
+func init() {
+	benchCmd.Flags().IntSliceVar(&benchRows, "rows", []int{10_000, 100_000}, "Row counts of the generated tables")
+	benchCmd.Flags().IntVar(&benchColumns, "columns", 8, "Columns per table, including the primary key")
+	benchCmd.Flags().BoolVar(&benchJSON, "json", false, "Add a JSON column to each table")
+	benchCmd.Flags().Int64Var(&benchMaxNsPerRow, "max-ns-per-row", 0, "Fail when decoding and comparing take longer per row (0 disables)")
+	rootCmd.AddCommand(benchCmd)
+}
+
+func runBench(cmd *cobra.Command, _ []string) error {
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ROWS\tCOLUMNS\tDECODE\tCOMPARE\tNS/ROW")
+	var slow []string
+	for _, rows := range benchRows {
+		if rows < 1 {
+			return fmt.Errorf("--rows must be positive, got %d", rows)
+		}
+		res, err := validator.Bench(cmd.Context(), validator.SyntheticTable{Rows: rows, Columns: benchColumns, JSON: benchJSON})
+		if err != nil {
+			return err
+		}
+		perRow := (res.Decode + res.Compare).Nanoseconds() / int64(rows)
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%d\n", res.Rows, res.Columns,
+			res.Decode.Round(time.Millisecond), res.Compare.Round(time.Millisecond), perRow)
+		if benchMaxNsPerRow > 0 && perRow > benchMaxNsPerRow {
+			slow = append(slow, fmt.Sprintf("%d rows: %d ns/row", rows, perRow))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if len(slow) > 0 {
+		return fmt.Errorf("slower than %d ns/row: %v", benchMaxNsPerRow, slow)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/logging"
+	"github.com/nu0ma/spalidate/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var csvTable string
+
+var compareCSVCmd = &cobra.Command{
+	Use:   "compare-csv --table <table> [csv-file]",
+	Short: "Validate a table's content against a CSV export",
+	Long: `Compares every row of a table with a CSV file whose first line is a header of column names.
+Rows are paired by primary key and CSV cells are coerced to the column types before comparison.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCompareCSV,
+}
+
+func init() {
+	compareCSVCmd.Flags().StringVar(&csvTable, "table", "", "Table to compare (required)")
+	if err := compareCSVCmd.MarkFlagRequired("table"); err != nil {
+		panic(fmt.Sprintf("failed to mark table flag as required: %v", err))
+	}
+	rootCmd.AddCommand(compareCSVCmd)
+}
+
+func runCompareCSV(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	csvPath := args[0]
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	f, err := os.Open(csvPath)
+	if err != nil {
+		return fmt.Errorf("opening CSV: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	spannerClient, err := newSpannerClient(ctx)
+	if err != nil {
+		return fmt.Errorf("creating spanner client: %w", err)
+	}
+	defer spannerClient.Close()
+
+	logging.L().Info("Comparing table with CSV", "table", csvTable, "csv", csvPath)
+	v := validator.NewValidator(&config.Config{}, spannerClient)
+	out, closeReport, err := openReport()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = closeReport() }()
+	if err := v.CompareCSV(ctx, csvTable, f); err != nil {
+		fmt.Fprint(out, validator.ReportOf(err))
+		logging.L().Error("CSV comparison failed", "error", err)
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	_, err = fmt.Fprintf(out, "Table %s matches %s\n", csvTable, csvPath)
+	return err
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nu0ma/spalidate/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var compareReportsCmd = &cobra.Command{
+	Use:   "compare-reports [old.json] [new.json]",
+	Short: "Show which tables started or stopped failing between two JSON reports",
+	Long: `Compares two JSON reports written by --report-dir, serve or the validation API and
+lists the tables that are newly failing, newly passing, still failing, added or removed.
+Exits non-zero when any table is newly failing. No database connection is made.`,
+	Args:        cobra.ExactArgs(2),
+	Annotations: map[string]string{offlineAnnotation: "true"},
+	RunE:        runCompareReports,
+}
+
+func init() {
+	rootCmd.AddCommand(compareReportsCmd)
+}
+
+func runCompareReports(cmd *cobra.Command, args []string) error {
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	oldReport, err := report.Load(args[0])
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", args[0], err)
+	}
+	newReport, err := report.Load(args[1])
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", args[1], err)
+	}
+
+	changes := report.Compare(oldReport, newReport)
+	out, closeReport, err := openReport()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = closeReport() }()
+	if err := report.WriteChanges(out, changes); err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+	if n := report.NewlyFailing(changes); n > 0 {
+		return fmt.Errorf("%d tables newly failing", n)
+	}
+	return nil
+}
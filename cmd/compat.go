@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// legacyArgs rewrites Go flag-style long flags (-project p, -port=9010) to the GNU form
+// cobra parses, so scripts written for single-dash flags keep working. Without it
+// -project p would parse as the shorthand -p with the value "roject". Only names of
+// defined long flags are rewritten, and neither flag values (-where -1) nor anything after
+// "--". Each rewrite is reported to w.
+func legacyArgs(root *cobra.Command, args []string, w io.Writer) []string {
+	// long and short map flag names to whether the flag takes a value
+	long, short := map[string]bool{}, map[byte]bool{}
+	var collect func(c *cobra.Command)
+	collect = func(c *cobra.Command) {
+		add := func(f *pflag.Flag) {
+			takesValue := f.NoOptDefVal == ""
+			long[f.Name] = takesValue
+			if f.Shorthand != "" {
+				short[f.Shorthand[0]] = takesValue
+			}
+		}
+		c.Flags().VisitAll(add)
+		c.PersistentFlags().VisitAll(add)
+		for _, sub := range c.Commands() {
+			collect(sub)
+		}
+	}
+	collect(root)
+
+	out := make([]string, len(args))
+	copy(out, args)
+	for i := 0; i < len(out); i++ {
+		arg := out[i]
+		if arg == "--" {
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			continue
+		}
+		name, _, inline := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		takesValue, isLong := long[name]
+		switch {
+		case strings.HasPrefix(arg, "--"):
+		case len(name) > 1 && isLong:
+			out[i] = "-" + arg
+			fmt.Fprintf(w, "spalidate: -%s is deprecated, use --%s\n", name, name)
+		default:
+			// shorthands, possibly combined (-vp value); the first one taking a value
+			// consumes the rest of the argument, or the next one
+			takesValue, inline = false, false
+			for j := 1; j < len(arg); j++ {
+				if short[arg[j]] {
+					takesValue, inline = true, j+1 < len(arg)
+					break
+				}
+			}
+		}
+		if takesValue && !inline {
+			i++
+		}
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestLegacyArgs(t *testing.T) {
+	root := &cobra.Command{Use: "spalidate"}
+	root.PersistentFlags().StringP("project", "p", "", "")
+	root.PersistentFlags().Int("port", 0, "")
+	root.PersistentFlags().BoolP("verbose", "v", false, "")
+	sub := &cobra.Command{Use: "check"}
+	sub.Flags().String("baseline", "", "")
+	root.AddCommand(sub)
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"long flag with value", []string{"-project", "p", "-port=9010"}, []string{"--project", "p", "--port=9010"}},
+		{"bool flag", []string{"-verbose", "config.yaml"}, []string{"--verbose", "config.yaml"}},
+		{"subcommand flag", []string{"check", "-baseline", "b.json"}, []string{"check", "--baseline", "b.json"}},
+		{"value of a legacy flag", []string{"-project", "-port"}, []string{"--project", "-port"}},
+		{"value of a long flag", []string{"--project", "-verbose"}, []string{"--project", "-verbose"}},
+		{"value of a shorthand", []string{"-p", "-project", "-verbose"}, []string{"-p", "-project", "--verbose"}},
+		{"combined shorthands", []string{"-vp", "-port", "-port", "1"}, []string{"-vp", "-port", "--port", "1"}},
+		{"inline shorthand value", []string{"-pmy-project", "-port", "1"}, []string{"-pmy-project", "--port", "1"}},
+		{"after --", []string{"--", "-project"}, []string{"--", "-project"}},
+		{"unknown flag", []string{"-unknown", "x"}, []string{"-unknown", "x"}},
+		{"stdin", []string{"-"}, []string{"-"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := legacyArgs(root, tt.args, io.Discard); !slices.Equal(got, tt.want) {
+				t.Errorf("legacyArgs(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nu0ma/spalidate/internal/logging"
+	"github.com/nu0ma/spalidate/internal/spanner"
+	"github.com/nu0ma/spalidate/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	againstProject      string
+	againstInstance     string
+	againstDatabase     string
+	againstEmulatorHost string
+	commonReadTimestamp bool
+)
+
+var consistencyCmd = &cobra.Command{
+	Use:   "consistency [config-file]",
+	Short: "Compare validation results between two Spanner connections",
+	Long: `Runs the same configuration against the primary connection (--project/--instance/--database)
+and a second one (--against-*), reporting tables whose validation outcome or content differ.
+Typical use is checking that an emulator snapshot still matches a staging database.
+
+--as-of reads both sides at the same timestamp. --common-read-timestamp picks that
+timestamp when the check starts, so writes made during the comparison do not skew it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConsistency,
+}
+
+func init() {
+	consistencyCmd.Flags().StringVar(&againstProject, "against-project", "", "Project ID of the second connection (defaults to --project)")
+	consistencyCmd.Flags().StringVar(&againstInstance, "against-instance", "", "Instance ID of the second connection (defaults to --instance)")
+	consistencyCmd.Flags().StringVar(&againstDatabase, "against-database", "", "Database ID of the second connection (required)")
+	consistencyCmd.Flags().StringVar(&againstEmulatorHost, "against-emulator-host", "", "Emulator host:port of the second connection; empty connects to Cloud Spanner")
+	consistencyCmd.Flags().BoolVar(&commonReadTimestamp, "common-read-timestamp", false, "Read both sides at the time the check starts (ignored with --as-of)")
+	if err := consistencyCmd.MarkFlagRequired("against-database"); err != nil {
+		panic(fmt.Sprintf("failed to mark against-database flag as required: %v", err))
+	}
+	rootCmd.AddCommand(consistencyCmd)
+}
+
+func runConsistency(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	configPath := args[0]
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	ts, err := asOfTimestamp()
+	if err != nil {
+		return err
+	}
+	if ts.IsZero() && commonReadTimestamp {
+		ts = time.Now()
+	}
+
+	primary, err := spanner.NewClient(ctx, project, instance, database, connectionOptions(ts))
+	if err != nil {
+		return fmt.Errorf("creating primary spanner client: %w", err)
+	}
+	defer primary.Close()
+
+	p, i := againstProject, againstInstance
+	if p == "" {
+		p = project
+	}
+	if i == "" {
+		i = instance
+	}
+	secondary, err := spanner.NewClient(ctx, p, i, againstDatabase,
+		spanner.Options{
+			EmulatorHost:              againstEmulatorHost,
+			CredentialsFile:           credentials,
+			ImpersonateServiceAccount: impersonate,
+			QueryTimeout:              queryTimeout,
+			ReadTimestamp:             ts,
+		})
+	if err != nil {
+		return fmt.Errorf("creating secondary spanner client: %w", err)
+	}
+	defer secondary.Close()
+
+	if !ts.IsZero() {
+		for side, c := range map[string]*spanner.Client{"primary": primary, "secondary": secondary} {
+			if err := checkRetention(ctx, c, ts); err != nil {
+				return fmt.Errorf("%s: %w", side, err)
+			}
+		}
+	}
+
+	logging.L().Info("Starting consistency check",
+		"config", configPath,
+		"primary", fmt.Sprintf("%s/%s/%s", project, instance, database),
+		"secondary", fmt.Sprintf("%s/%s/%s", p, i, againstDatabase),
+		"readTimestamp", ts,
+	)
+
+	results, err := validator.CheckConsistency(ctx, cfg, primary, secondary)
+	if err != nil {
+		return fmt.Errorf("consistency check failed: %w", err)
+	}
+	out, closeReport, err := openReport()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = closeReport() }()
+	if err := validator.ReportDivergences(out, results); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, "Connections are consistent for all tables")
+	return err
+}
+
+// checkRetention refuses a read timestamp older than the database keeps versions for, which
+// Spanner would otherwise reject mid-comparison.
+func checkRetention(ctx context.Context, c *spanner.Client, ts time.Time) error {
+	retention, err := c.VersionRetention(ctx)
+	if err != nil {
+		return err
+	}
+	if age := time.Since(ts); age > retention {
+		return fmt.Errorf("read timestamp %s is %s old, beyond the version retention period of %s",
+			ts.Format(time.RFC3339), age.Round(time.Second), retention)
+	}
+	return nil
+}
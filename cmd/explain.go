@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain [config-file]",
+	Short: "Print the fully resolved configuration",
+	Long: `Prints the configuration as it will be validated: definitions, external sources and seed
+fixtures are expanded, --dataset is applied, when conditions are evaluated and comparison
+options are merged per table with defaults filled in. No database connection is made.`,
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{offlineAnnotation: "true"},
+	RunE:        runExplain,
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	cfg, err := loadConfig(args[0])
+	if err != nil {
+		return err
+	}
+	effective, err := cfg.Effective()
+	if err != nil {
+		return fmt.Errorf("resolving config: %w", err)
+	}
+	enc := yaml.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent(2)
+	if err := enc.Encode(effective); err != nil {
+		return fmt.Errorf("encoding config: %w", err)
+	}
+	return enc.Close()
+}
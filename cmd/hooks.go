@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nu0ma/spalidate/internal/logging"
+	"github.com/nu0ma/spalidate/internal/spanner"
+)
+
+// HookError reports a failed before/after statement, separately from validation failures.
+type HookError struct {
+	Phase     string
+	Index     int
+	Statement string
+	Err       error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("%s hook %d failed: %v", e.Phase, e.Index+1, e.Err)
+}
+
+func (e *HookError) Unwrap() error { return e.Err }
+
+var ddlKeywords = []string{"CREATE", "ALTER", "DROP", "GRANT", "REVOKE", "ANALYZE", "RENAME"}
+
+// isDDL reports whether the statement changes the schema rather than the data.
+func isDDL(stmt string) bool {
+	fields := strings.Fields(stmt)
+	if len(fields) == 0 {
+		return false
+	}
+	first := strings.ToUpper(fields[0])
+	for _, kw := range ddlKeywords {
+		if first == kw {
+			return true
+		}
+	}
+	return false
+}
+
+// runHooks runs the statements of one hook phase in order and stops at the first failure.
+// Hooks write to the database, so they are only run against the emulator.
+func runHooks(ctx context.Context, client *spanner.Client, phase string, statements []string) error {
+	if len(statements) == 0 {
+		return nil
+	}
+	if !client.IsEmulator() {
+		return &HookError{Phase: phase, Err: fmt.Errorf("hooks are only run against emulator targets")}
+	}
+	for i, stmt := range statements {
+		var err error
+		if isDDL(stmt) {
+			err = client.UpdateDDL(ctx, []string{stmt})
+			logging.L().Debug("Ran hook", "phase", phase, "index", i+1, "kind", "ddl")
+		} else {
+			var n int64
+			n, err = client.ExecuteDML(ctx, stmt)
+			logging.L().Debug("Ran hook", "phase", phase, "index", i+1, "kind", "dml", "rows", n)
+		}
+		if err != nil {
+			herr := &HookError{Phase: phase, Index: i, Statement: stmt, Err: err}
+			logging.L().Error("Hook failed", "phase", phase, "index", i+1, "statement", stmt, "error", err)
+			return herr
+		}
+	}
+	return nil
+}
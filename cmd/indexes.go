@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nu0ma/spalidate/internal/logging"
+	"github.com/nu0ma/spalidate/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var checkIndexesCmd = &cobra.Command{
+	Use:   "check-indexes [config-file]",
+	Short: "Check that secondary indexes hold the same rows as their tables",
+	Long: `Reads the primary keys of every table in the configuration from the table itself and
+through each of its secondary indexes (FORCE_INDEX), and reports keys found on one side
+only. Useful after bulk imports, notably on the emulator.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCheckIndexes,
+}
+
+func init() {
+	rootCmd.AddCommand(checkIndexesCmd)
+}
+
+func runCheckIndexes(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	configPath := args[0]
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	client, err := newSpannerClient(ctx)
+	if err != nil {
+		return fmt.Errorf("creating spanner client: %w", err)
+	}
+	defer client.Close()
+
+	logging.L().Info("Starting index check", "config", configPath, "database", database)
+	results, err := validator.CheckIndexes(ctx, cfg, client)
+	if err != nil {
+		return fmt.Errorf("index check failed: %w", err)
+	}
+	out, closeReport, err := openReport()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = closeReport() }()
+	if err := validator.ReportIndexDivergences(out, results); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "%d indexes are consistent with their tables\n", len(results))
+	return err
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nu0ma/spalidate/internal/cache"
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/logging"
+	"github.com/nu0ma/spalidate/internal/report"
+)
+
+// parseLabels turns --label key=value pairs into a map.
+func parseLabels(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	m := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		k, v, ok := strings.Cut(p, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid --label %q: want key=value", p)
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+// runMetadata describes a validation run of cfg against databaseID for JSON reports.
+func runMetadata(cfg *config.Config, databaseID string) *report.Metadata {
+	m := &report.Metadata{
+		Labels:        runLabels,
+		Database:      fmt.Sprintf("projects/%s/instances/%s/databases/%s", project, instance, databaseID),
+		ReadTimestamp: asOf,
+		Restore:       restoreMetadata(databaseID),
+	}
+	m.Hostname, _ = os.Hostname()
+	if cfg != nil {
+		hash, err := cfg.Hash()
+		if err != nil {
+			logging.L().Warn("Could not hash config", "error", err)
+		}
+		m.ConfigHash = hash
+	}
+	return m
+}
+
+// runCacheKey identifies a run for --cache-dir by the config hash, the database path and
+// host, the --as-of read timestamp, the --anchor and the --ddl schema. It is empty, and
+// the run is not cached, without --as-of, as the key would not capture the data; when the
+// run writes to the database through seed fixtures or hooks, which a skipped run would
+// leave undone; when the config cannot be hashed; and when its outcome depends on the
+// time of the run.
+func runCacheKey(cfg *config.Config, databaseID string) string {
+	switch {
+	case asOf == "":
+		logging.L().Info("Not caching, no --as-of read timestamp pins the data")
+		return ""
+	case cfg.Seed != nil || len(cfg.Before) > 0 || len(cfg.After) > 0:
+		logging.L().Info("Not caching, the config seeds the database or runs hooks")
+		return ""
+	case cfg.TimeRelative():
+		logging.L().Info("Not caching, the config uses time-relative params or matchers")
+		return ""
+	}
+	m := runMetadata(cfg, databaseID)
+	if m.ConfigHash == "" {
+		return ""
+	}
+	var anchor string
+	if !anchorTime.IsZero() {
+		anchor = anchorTime.UTC().Format(time.RFC3339Nano)
+	}
+	var schema []byte
+	if ddlPath != "" {
+		var err error
+		if schema, err = os.ReadFile(ddlPath); err != nil {
+			logging.L().Warn("Not caching, could not read schema file", "error", err)
+			return ""
+		}
+	}
+	return cache.Key(m.ConfigHash, m.Database, endpoint, emulatorHost(), m.ReadTimestamp, anchor, string(schema))
+}
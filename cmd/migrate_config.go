@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+var migrateWrite bool
+
+var migrateConfigCmd = &cobra.Command{
+	Use:   "migrate-config [config-file]",
+	Short: "Rewrite a configuration file into the current format version",
+	Long: `Rewrites a configuration written for an older format version into the current one,
+keeping comments and key order, and prints the result. With --write the file is replaced
+instead. Each change is logged. No database connection is made.`,
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{offlineAnnotation: "true"},
+	RunE:        runMigrateConfig,
+}
+
+func init() {
+	migrateConfigCmd.Flags().BoolVar(&migrateWrite, "write", false, "Replace the file instead of printing the migrated configuration")
+	rootCmd.AddCommand(migrateConfigCmd)
+}
+
+func runMigrateConfig(cmd *cobra.Command, args []string) error {
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	path := args[0]
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+	migrated, changes, err := config.Migrate(data)
+	if err != nil {
+		return fmt.Errorf("migrating %s: %w", path, err)
+	}
+	for _, c := range changes {
+		logging.L().Info("Migrated config", "file", path, "change", c)
+	}
+	if len(changes) == 0 {
+		logging.L().Info("Config is already current", "file", path, "version", config.CurrentVersion)
+	}
+	// the migrated file must load, so a migration never produces a broken config
+	if _, err := config.Parse(migrated, filepath.Dir(path)); err != nil {
+		return fmt.Errorf("migrated config does not load: %w", err)
+	}
+
+	if !migrateWrite {
+		_, err := cmd.OutOrStdout().Write(migrated)
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, migrated, info.Mode().Perm())
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var optionsCmd = &cobra.Command{
+	Use:   "options",
+	Short: "List the supported configuration keys and matcher tags",
+	Long: `Prints every configuration key with its type and default, followed by the YAML tags and
+reserved keys usable in expected rows. <name> stands for a map key and [] for a list item.`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{offlineAnnotation: "true"},
+	RunE:        runOptions,
+}
+
+func init() {
+	rootCmd.AddCommand(optionsCmd)
+}
+
+func runOptions(cmd *cobra.Command, args []string) error {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tTYPE\tDEFAULT")
+	for _, k := range config.Keys() {
+		def := k.Default
+		if def == "" {
+			def = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", k.Key, k.Type, def)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "MATCHER\tMEANING")
+	for _, m := range config.Matchers {
+		fmt.Fprintf(w, "%s\t%s\n", m.Syntax, m.Doc)
+	}
+	return w.Flush()
+}
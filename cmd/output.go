@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Reports are written to stdout (or --report-file) and logs to stderr, so a pipeline can
+// capture the result of a run without log lines mixed in.
+
+// openReport returns the destination of the command's report and a function that closes it.
+func openReport() (io.Writer, func() error, error) {
+	if reportFile == "" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(reportFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating report file: %w", err)
+	}
+	return f, f.Close, nil
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nu0ma/spalidate/internal/logging"
+	"github.com/nu0ma/spalidate/internal/report"
+	"github.com/nu0ma/spalidate/internal/spanner"
+)
+
+// restores holds the backups verified with --restored-from, keyed by database ID, so the
+// reports of a restore drill name the backup they validated.
+var restores = map[string]*spanner.RestoreInfo{}
+
+// checkRestore verifies that the database was restored from the --restored-from backup.
+func checkRestore(ctx context.Context, client *spanner.Client, databaseID string) error {
+	info, err := client.RestoreInfo(ctx)
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		return fmt.Errorf("database %s was not restored from a backup", databaseID)
+	}
+	if !matchesBackup(info.Backup, restoredFrom) {
+		return fmt.Errorf("database %s was restored from %s, not %s", databaseID, info.Backup, restoredFrom)
+	}
+	restores[databaseID] = info
+	logging.L().Info("Verified restored database", "database", databaseID, "backup", info.Backup, "versionTime", info.VersionTime)
+	return nil
+}
+
+// matchesBackup compares a backup path with a --restored-from value, which is either a
+// full backup path or a backup ID.
+func matchesBackup(path, want string) bool {
+	return path == want || strings.HasSuffix(path, "/backups/"+want)
+}
+
+// restoreMetadata returns the report tag of a database verified with --restored-from, or nil.
+func restoreMetadata(databaseID string) *report.Restore {
+	info := restores[databaseID]
+	if info == nil {
+		return nil
+	}
+	return &report.Restore{
+		Backup:         info.Backup,
+		SourceDatabase: info.SourceDatabase,
+		VersionTime:    info.VersionTime.UTC().Format(time.RFC3339Nano),
+	}
+}
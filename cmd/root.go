@@ -2,11 +2,21 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/nu0ma/spalidate/internal/cache"
 	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/errkind"
 	"github.com/nu0ma/spalidate/internal/logging"
+	"github.com/nu0ma/spalidate/internal/report"
+	"github.com/nu0ma/spalidate/internal/repro"
+	"github.com/nu0ma/spalidate/internal/seed"
 	"github.com/nu0ma/spalidate/internal/spanner"
 	"github.com/nu0ma/spalidate/internal/validator"
 	"github.com/spf13/cobra"
@@ -15,30 +25,68 @@ import (
 const version = "v1.0.0"
 
 var (
-	project  string
-	instance string
-	database string
-	port     int
-	verbose  bool
-	cleanup  func()
+	project    string
+	instance   string
+	database   string
+	port       int
+	verbose    bool
+	dataset    string
+	reproDir   string
+	reportDir  string
+	reportFile string
+	asOf       string
+	ddlPath    string
+	cleanup    func()
+
+	queryTimeout time.Duration
+	labels       []string
+	runLabels    map[string]string
+	cacheDir     string
+	showMatches  bool
+	ascii        bool
+	strictTypes  bool
+	restoredFrom string
+	endpoint     string
+	credentials  string
+	impersonate  string
+	dialectFlag  string
+	sqlDialect   spanner.Dialect
+	anchor       string
+	anchorTime   time.Time
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "spalidate [config-file]",
+	Use:   "spalidate [config-file | -]",
 	Short: "Validate Google Cloud Spanner data against YAML configuration",
 	Long: `Spalidate is a CLI tool for validating Google Cloud Spanner database data 
-against YAML configuration files. It connects to Spanner emulator instances 
-and performs comprehensive data validation with flexible type comparison.`,
+against YAML configuration files. It connects to the Spanner emulator, or to Cloud
+Spanner with --endpoint or --credentials-file, and performs comprehensive data
+validation with flexible type comparison.`,
 	Args:          cobra.ExactArgs(1),
 	Version:       version,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireConnectionFlags(cmd); err != nil {
+			return err
+		}
 		c, err := logging.Init(verbose)
 		if err != nil {
 			return err
 		}
 		cleanup = c
+		validator.SetASCII(ascii || asciiTerminal())
+		if runLabels, err = parseLabels(labels); err != nil {
+			return err
+		}
+		if sqlDialect, err = spanner.ParseDialect(dialectFlag); err != nil {
+			return fmt.Errorf("invalid --dialect: %w", err)
+		}
+		if anchor != "" {
+			if anchorTime, err = time.Parse(time.RFC3339Nano, anchor); err != nil {
+				return fmt.Errorf("invalid --anchor timestamp: %w", err)
+			}
+		}
 		return nil
 	},
 	RunE: run,
@@ -47,28 +95,210 @@ and performs comprehensive data validation with flexible type comparison.`,
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&project, "project", "p", "", "Spanner project ID (required)")
 	rootCmd.PersistentFlags().StringVarP(&instance, "instance", "i", "", "Spanner instance ID (required)")
-	rootCmd.PersistentFlags().StringVarP(&database, "database", "d", "", "Spanner database ID (required)")
-	rootCmd.PersistentFlags().IntVar(&port, "port", 9010, "Spanner emulator port")
+	rootCmd.PersistentFlags().StringVarP(&database, "database", "d", "", "Spanner database ID (required); the root command accepts a comma-separated list")
+	rootCmd.PersistentFlags().IntVar(&port, "port", 9010, "Spanner emulator port; 0 connects to Cloud Spanner")
+	rootCmd.PersistentFlags().StringVar(&endpoint, "endpoint", "", "Connect to Cloud Spanner at this host:port (TLS, authenticated) instead of the emulator")
+	rootCmd.PersistentFlags().StringVar(&credentials, "credentials-file", "", "Connect to Cloud Spanner with this service account key file instead of Application Default Credentials")
+	rootCmd.PersistentFlags().StringVar(&impersonate, "impersonate-service-account", "", "Connect to Cloud Spanner as this service account (email), authenticating the impersonation with the other credentials")
+	rootCmd.PersistentFlags().StringVar(&dialectFlag, "dialect", "", "SQL dialect of the database: googlesql or postgresql (default: detected)")
+	rootCmd.PersistentFlags().StringVar(&dataset, "dataset", "", "Dataset to select for tables that define datasets")
+	rootCmd.Flags().StringVar(&reproDir, "repro-dir", "", "On failure, write a repro bundle (config, actual rows, schema, report) to this directory")
+	rootCmd.Flags().StringVar(&reportDir, "report-dir", "", "Write report-<database>.json per database and an index.json summary to this directory")
+	rootCmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Skip databases whose identical --as-of run (config, database, read timestamp) passed before")
+	rootCmd.Flags().StringVar(&restoredFrom, "restored-from", "", "Restore drill: require each database to be restored from this backup (ID or full path) and tag the reports with it")
+	rootCmd.Flags().StringVar(&ddlPath, "ddl", "", "Emulator only: create the database from this schema file if it does not exist")
+	rootCmd.PersistentFlags().StringVar(&asOf, "as-of", "", "Read data as of this RFC3339 timestamp (stale read within the version retention period)")
+	rootCmd.PersistentFlags().StringVar(&anchor, "anchor", "", "Start of the run under test (RFC3339), bound to @runStart in where filters")
+	rootCmd.PersistentFlags().DurationVar(&queryTimeout, "timeout-per-query", 0, "Abort any single query that runs longer than this (e.g. 30s); 0 disables the limit")
+	rootCmd.PersistentFlags().BoolVar(&showMatches, "show-matches", false, "Log every matching column (independent of --verbose)")
+	rootCmd.PersistentFlags().StringVar(&reportFile, "report-file", "", "Write the report to this file instead of stdout (logs always go to stderr)")
+	rootCmd.PersistentFlags().StringArrayVar(&labels, "label", nil, "Attach a key=value label to JSON reports (repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&strictTypes, "strict-types", false, "Disable implicit type coercions in comparisons (same as options.strictTypes)")
+	rootCmd.PersistentFlags().BoolVar(&ascii, "ascii", false, "Use plain ASCII markers instead of emoji in reports (auto-enabled for non-UTF-8 terminals)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose logging (sets level=debug)")
 
-	if err := rootCmd.MarkPersistentFlagRequired("project"); err != nil {
-		panic(fmt.Sprintf("failed to mark project flag as required: %v", err))
+}
+
+// offlineAnnotation marks commands that work on the config file alone and therefore do not
+// need --project, --instance and --database.
+const offlineAnnotation = "spalidate/offline"
+
+// requireConnectionFlags reports the connection flags missing for commands that talk to Spanner.
+func requireConnectionFlags(cmd *cobra.Command) error {
+	if cmd.Annotations[offlineAnnotation] == "true" {
+		return nil
 	}
-	if err := rootCmd.MarkPersistentFlagRequired("instance"); err != nil {
-		panic(fmt.Sprintf("failed to mark instance flag as required: %v", err))
+	var missing []string
+	for _, name := range []string{"database", "instance", "project"} {
+		if f := cmd.Flags().Lookup(name); f == nil || !f.Changed {
+			missing = append(missing, name)
+		}
 	}
-	if err := rootCmd.MarkPersistentFlagRequired("database"); err != nil {
-		panic(fmt.Sprintf("failed to mark database flag as required: %v", err))
+	if len(missing) > 0 {
+		return fmt.Errorf(`required flag(s) "%s" not set`, strings.Join(missing, `", "`))
 	}
+	return nil
 }
 
+// Exit codes of failed commands.
+const (
+	exitFailure    = 1 // validation failed, or any other error
+	exitConfig     = 2 // the configuration is invalid
+	exitConnection = 3 // Spanner could not be reached
+)
+
 func Execute() {
+	rootCmd.SetArgs(legacyArgs(rootCmd, os.Args[1:], os.Stderr))
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
-func run(cmd *cobra.Command, args []string) error {
+// exitCode classifies a command error by its errkind.
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, errkind.ErrConfig):
+		return exitConfig
+	case errors.Is(err, errkind.ErrConnection):
+		return exitConnection
+	}
+	return exitFailure
+}
+
+// stdinConfig is the config path that reads the configuration from standard input.
+const stdinConfig = "-"
+
+// loadConfig loads the config file (or stdin for "-"), selects the --dataset and logs lint warnings.
+// Relative paths in a config read from stdin are resolved against the working directory.
+func loadConfig(configPath string) (*config.Config, error) {
+	var cfg *config.Config
+	var err error
+	if configPath == stdinConfig {
+		var data []byte
+		if data, err = io.ReadAll(os.Stdin); err != nil {
+			return nil, fmt.Errorf("reading config from stdin: %w", err)
+		}
+		cfg, err = config.Parse(data, ".")
+	} else {
+		cfg, err = config.LoadConfig(configPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	if err := cfg.SelectDataset(dataset); err != nil {
+		return nil, fmt.Errorf("selecting dataset: %w", err)
+	}
+	if strictTypes {
+		cfg.Options.StrictTypes = &strictTypes
+	}
+	for _, w := range cfg.Warnings {
+		logging.L().Warn(w)
+	}
+	return cfg, nil
+}
+
+// emulatorHost returns the emulator address selected by --port, or "" when the
+// SPANNER_EMULATOR_HOST environment variable (or no emulator) is used instead.
+// --endpoint, --credentials-file and --impersonate-service-account select Cloud Spanner.
+func emulatorHost() string {
+	if endpoint != "" || credentials != "" || impersonate != "" {
+		return ""
+	}
+	if port != 0 && os.Getenv("SPANNER_EMULATOR_HOST") == "" {
+		return fmt.Sprintf("localhost:%d", port)
+	}
+	return ""
+}
+
+// newSpannerClient connects to the database selected by the global flags.
+func newSpannerClient(ctx context.Context) (*spanner.Client, error) {
+	return newTargetClient(ctx, database)
+}
+
+// newTargetClient connects to databaseID in the project and instance selected by the global flags.
+func newTargetClient(ctx context.Context, databaseID string) (*spanner.Client, error) {
+	ts, err := asOfTimestamp()
+	if err != nil {
+		return nil, err
+	}
+	return spanner.NewClient(ctx, project, instance, databaseID, connectionOptions(ts))
+}
+
+// connectionOptions returns the client options selected by the global connection flags.
+func connectionOptions(ts time.Time) spanner.Options {
+	return spanner.Options{
+		EmulatorHost:              emulatorHost(),
+		Endpoint:                  endpoint,
+		CredentialsFile:           credentials,
+		QueryTimeout:              queryTimeout,
+		ImpersonateServiceAccount: impersonate,
+		ReadTimestamp:             ts,
+		Dialect:                   sqlDialect,
+	}
+}
+
+// asOfTimestamp parses --as-of, returning zero for strong reads.
+func asOfTimestamp() (time.Time, error) {
+	if asOf == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, asOf)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --as-of timestamp: %w", err)
+	}
+	return t, nil
+}
+
+// applySeed writes the config's seed rows. Seeding is refused outside the emulator so a
+// scenario file can never write into a real database.
+func applySeed(ctx context.Context, client *spanner.Client, s *config.Seed) error {
+	if !client.IsEmulator() {
+		return fmt.Errorf("seed is only applied to emulator targets")
+	}
+	ms, err := seed.Mutations(s)
+	if err != nil {
+		return err
+	}
+	if err := client.Apply(ctx, ms); err != nil {
+		return err
+	}
+	logging.L().Info("Applied seed", "mutations", len(ms))
+	return nil
+}
+
+// ensureDatabase creates the emulator database from the --ddl schema file when it does not exist.
+func ensureDatabase(ctx context.Context, databaseID string) error {
+	host := emulatorHost()
+	if host == "" && os.Getenv("SPANNER_EMULATOR_HOST") == "" {
+		return fmt.Errorf("--ddl requires an emulator target")
+	}
+	schema, err := os.ReadFile(ddlPath)
+	if err != nil {
+		return fmt.Errorf("failed to read schema file: %w", err)
+	}
+	created, err := spanner.EnsureDatabase(ctx, project, instance, databaseID, host, sqlDialect, spanner.ParseDDL(string(schema)))
+	if err != nil {
+		return err
+	}
+	if created {
+		logging.L().Info("Created database", "database", databaseID, "ddl", ddlPath)
+	}
+	return nil
+}
+
+func writeReproBundle(ctx context.Context, client *spanner.Client, cfg *config.Config, res *validator.Result, dir string) {
+	ddl, err := client.DatabaseDDL(ctx)
+	if err != nil {
+		logging.L().Warn("Could not read schema for repro bundle", "error", err)
+	}
+	if err := repro.Write(dir, cfg, res, ddl); err != nil {
+		logging.L().Error("Failed to write repro bundle", "dir", dir, "error", err)
+		return
+	}
+	logging.L().Info("Wrote repro bundle", "dir", dir)
+}
+
+func run(cmd *cobra.Command, args []string) (err error) {
 	ctx := context.Background()
 	configPath := args[0]
 	if cleanup != nil {
@@ -80,32 +310,186 @@ func run(cmd *cobra.Command, args []string) error {
 		"instance", instance,
 		"database", database,
 		"port", port,
+		"endpoint", endpoint,
+		"asOf", asOf,
 	)
 
-	cfg, err := config.LoadConfig(configPath)
+	cfg, err := loadConfig(configPath)
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return err
 	}
 
 	logging.L().Debug("Loaded config", "tables", len(cfg.Tables))
 
-	opts := spanner.Options{}
-	if port != 0 && os.Getenv("SPANNER_EMULATOR_HOST") == "" {
-		opts.EmulatorHost = fmt.Sprintf("localhost:%d", port)
+	out, closeReport, err := openReport()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := closeReport(); cerr != nil && err == nil {
+			err = fmt.Errorf("writing report file: %w", cerr)
+		}
+	}()
+
+	// --database accepts a comma-separated list; each database is validated in turn.
+	targets := strings.Split(database, ",")
+	if reportDir != "" {
+		if err := os.MkdirAll(reportDir, 0o755); err != nil {
+			return fmt.Errorf("creating report directory: %w", err)
+		}
+	}
+	index := &report.Index{}
+	var lastErr error
+	var failed []string
+	for _, db := range targets {
+		start := time.Now()
+		dir := reproDir
+		if dir != "" && len(targets) > 1 {
+			dir = filepath.Join(reproDir, db)
+		}
+		res, err := runTarget(ctx, cfg, db, dir)
+		if werr := writeTargetText(out, db, len(targets) > 1, res, err); werr != nil {
+			return fmt.Errorf("writing report: %w", werr)
+		}
+		if err != nil {
+			if len(targets) > 1 {
+				logging.L().Error("Target failed", "database", db, "error", err)
+			}
+			lastErr = err
+			failed = append(failed, db)
+		}
+		if reportDir != "" {
+			if werr := writeTargetReport(index, cfg, db, res, err, start); werr != nil {
+				return werr
+			}
+		}
+	}
+	if reportDir != "" {
+		if err := index.WriteFile(filepath.Join(reportDir, report.IndexFileName)); err != nil {
+			return fmt.Errorf("writing report index: %w", err)
+		}
+	}
+	switch {
+	case len(targets) == 1 && lastErr != nil:
+		return lastErr
+	case len(failed) > 0:
+		return fmt.Errorf("validation failed for databases: %s", strings.Join(failed, ", "))
+	}
+
+	_, err = fmt.Fprintln(out, "Validation passed for all tables")
+	return err
+}
+
+// writeTargetText writes the text report of one database, under a heading when several
+// databases are validated. Setup failures without a result are reported by their error.
+func writeTargetText(w io.Writer, databaseID string, heading bool, res *validator.Result, runErr error) error {
+	if heading {
+		if _, err := fmt.Fprintf(w, "database %s:\n", databaseID); err != nil {
+			return err
+		}
+	}
+	if r := restoreMetadata(databaseID); r != nil {
+		if _, err := fmt.Fprintf(w, "restored from backup %s (version time %s)\n", r.Backup, r.VersionTime); err != nil {
+			return err
+		}
+	}
+	if res == nil {
+		if runErr == nil {
+			_, err := fmt.Fprintln(w, "skipped: an identical run passed before (--cache-dir)")
+			return err
+		}
+		_, err := fmt.Fprintf(w, "%v\n", runErr)
+		return err
+	}
+	return res.WriteText(w)
+}
+
+// runTarget prepares and validates one database. The result is nil when the run failed
+// before validation started, or was skipped by --cache-dir (after the --ddl setup). A
+// non-nil error reports setup, hook or validation failures.
+func runTarget(ctx context.Context, cfg *config.Config, databaseID, reproDir string) (*validator.Result, error) {
+	if ddlPath != "" {
+		if err := ensureDatabase(ctx, databaseID); err != nil {
+			return nil, fmt.Errorf("setting up database: %w", err)
+		}
 	}
 
-	spannerClient, err := spanner.NewClient(ctx, project, instance, database, opts)
+	var key string
+	if cacheDir != "" {
+		key = runCacheKey(cfg, databaseID)
+	}
+	if key != "" {
+		hit, err := cache.Passed(cacheDir, key)
+		if err != nil {
+			logging.L().Warn("Ignoring cache", "error", err)
+		}
+		if hit {
+			logging.L().Info("Skipping validation, an identical run passed before", "database", databaseID)
+			return nil, nil
+		}
+	}
+
+	spannerClient, err := newTargetClient(ctx, databaseID)
 	if err != nil {
-		return fmt.Errorf("creating spanner client: %w", err)
+		return nil, fmt.Errorf("creating spanner client: %w", err)
+	}
+	defer spannerClient.Close()
+
+	if restoredFrom != "" {
+		if err := checkRestore(ctx, spannerClient, databaseID); err != nil {
+			return nil, fmt.Errorf("checking restore: %w", err)
+		}
+	}
+
+	if cfg.Seed != nil {
+		if err := applySeed(ctx, spannerClient, cfg.Seed); err != nil {
+			return nil, fmt.Errorf("seeding: %w", err)
+		}
 	}
 
-	v := validator.NewValidator(cfg, spannerClient)
-	if err := v.Validate(); err != nil {
-		logging.L().Error("Validation failed", "error", err)
-		return fmt.Errorf("validation failed: %w", err)
+	if err := runHooks(ctx, spannerClient, "before", cfg.Before); err != nil {
+		return nil, err
 	}
-	logging.L().Info("Validation completed successfully")
 
-	fmt.Println("Validation passed for all tables")
+	v := validator.NewValidator(cfg, spannerClient, validator.WithShowMatches(showMatches),
+		validator.WithMemoryStats(verbose || reportDir != ""), validator.WithAnchor(anchorTime))
+	res := v.Run(ctx)
+	// after hooks run even when validation fails, e.g. to clean up temporary rows
+	afterErr := runHooks(ctx, spannerClient, "after", cfg.After)
+	if err := res.Err(); err != nil {
+		logging.L().Error("Validation failed", "database", databaseID, "error", err)
+		if reproDir != "" {
+			writeReproBundle(ctx, spannerClient, cfg, res, reproDir)
+		}
+		// a failing after hook is reported too, as cleanup may be left undone
+		return res, errors.Join(fmt.Errorf("validation failed: %w", err), afterErr)
+	}
+	if afterErr != nil {
+		return res, afterErr
+	}
+	logging.L().Info("Validation completed successfully", "database", databaseID)
+	if key != "" {
+		if err := cache.RecordPass(cacheDir, key); err != nil {
+			logging.L().Warn("Could not record passing run", "error", err)
+		}
+	}
+	return res, nil
+}
+
+// writeTargetReport writes report-<database>.json to --report-dir and records it in index.
+func writeTargetReport(index *report.Index, cfg *config.Config, databaseID string, res *validator.Result, runErr error, start time.Time) error {
+	entry := report.IndexEntry{Database: databaseID, Passed: runErr == nil}
+	if runErr != nil {
+		entry.Error = runErr.Error()
+	}
+	if res != nil {
+		entry.File = report.TargetFileName(databaseID)
+		r := report.FromResult(res, start, time.Since(start))
+		r.Metadata = runMetadata(cfg, databaseID)
+		if err := r.WriteFile(filepath.Join(reportDir, entry.File)); err != nil {
+			return fmt.Errorf("writing report for %s: %w", databaseID, err)
+		}
+	}
+	index.Add(entry)
 	return nil
 }
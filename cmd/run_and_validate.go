@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nu0ma/spalidate/internal/logging"
+	"github.com/nu0ma/spalidate/internal/spanner"
+	"github.com/nu0ma/spalidate/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dmlPath          string
+	partitionedDML   bool
+	allowNonEmulator bool
+)
+
+var runAndValidateCmd = &cobra.Command{
+	Use:   "run-and-validate --dml script.sql [config-file]",
+	Short: "Execute a DML script, then validate the configuration",
+	Long: `Executes the statements of a DML script in order, reporting the rows each one affected,
+then validates the configuration against the result: a one-shot migration test. Seed data
+and before hooks run first, after hooks last. The script only runs against the emulator
+unless --allow-non-emulator is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRunAndValidate,
+}
+
+func init() {
+	runAndValidateCmd.Flags().StringVar(&dmlPath, "dml", "", "DML script to execute, statements separated by semicolons (required)")
+	runAndValidateCmd.Flags().BoolVar(&partitionedDML, "partitioned", false, "Execute each statement as partitioned DML (affected counts are lower bounds)")
+	runAndValidateCmd.Flags().BoolVar(&allowNonEmulator, "allow-non-emulator", false, "Allow executing the script against Cloud Spanner")
+	if err := runAndValidateCmd.MarkFlagRequired("dml"); err != nil {
+		panic(fmt.Sprintf("failed to mark dml flag as required: %v", err))
+	}
+	rootCmd.AddCommand(runAndValidateCmd)
+}
+
+func runRunAndValidate(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	cfg, err := loadConfig(args[0])
+	if err != nil {
+		return err
+	}
+	script, err := os.ReadFile(dmlPath)
+	if err != nil {
+		return fmt.Errorf("reading DML script: %w", err)
+	}
+	statements := spanner.SplitStatements(string(script))
+	if len(statements) == 0 {
+		return fmt.Errorf("DML script %s has no statements", dmlPath)
+	}
+
+	client, err := newSpannerClient(ctx)
+	if err != nil {
+		return fmt.Errorf("creating spanner client: %w", err)
+	}
+	defer client.Close()
+	if !client.IsEmulator() && !allowNonEmulator {
+		return fmt.Errorf("refusing to run a DML script against Cloud Spanner without --allow-non-emulator")
+	}
+
+	out, closeReport, err := openReport()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = closeReport() }()
+
+	if cfg.Seed != nil {
+		if err := applySeed(ctx, client, cfg.Seed); err != nil {
+			return fmt.Errorf("seeding: %w", err)
+		}
+	}
+	if err := runHooks(ctx, client, "before", cfg.Before); err != nil {
+		return err
+	}
+	if err := runScript(ctx, out, client, statements); err != nil {
+		return err
+	}
+
+	v := validator.NewValidator(cfg, client, validator.WithShowMatches(showMatches), validator.WithAnchor(anchorTime))
+	res := v.Run(ctx)
+	afterErr := runHooks(ctx, client, "after", cfg.After)
+	if err := res.WriteText(out); err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+	if err := res.Err(); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	if afterErr != nil {
+		return afterErr
+	}
+	_, err = fmt.Fprintln(out, "Validation passed for all tables")
+	return err
+}
+
+// runScript executes the DML statements in order, each in its own transaction, writing
+// the affected row count of each to w. It stops at the first failure.
+func runScript(ctx context.Context, w io.Writer, client *spanner.Client, statements []string) error {
+	var total int64
+	for i, stmt := range statements {
+		var (
+			n   int64
+			err error
+		)
+		if partitionedDML {
+			n, err = client.ExecutePartitionedDML(ctx, stmt)
+		} else {
+			n, err = client.ExecuteDML(ctx, stmt)
+		}
+		if err != nil {
+			logging.L().Error("DML statement failed", "index", i+1, "statement", stmt, "error", err)
+			return fmt.Errorf("DML statement %d failed: %w", i+1, err)
+		}
+		logging.L().Debug("Ran DML statement", "index", i+1, "rows", n)
+		if _, err := fmt.Fprintf(w, "statement %d: %d rows affected\n", i+1, n); err != nil {
+			return err
+		}
+		total += n
+	}
+	_, err := fmt.Fprintf(w, "%d statements, %d rows affected\n", len(statements), total)
+	return err
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nu0ma/spalidate/internal/logging"
+	"github.com/nu0ma/spalidate/internal/report"
+	"github.com/nu0ma/spalidate/internal/server"
+	"github.com/nu0ma/spalidate/internal/spanner"
+	"github.com/nu0ma/spalidate/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveInterval time.Duration
+	serveListen   string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve [config-file]",
+	Short: "Validate continuously and expose the latest result over HTTP",
+	Long: `Re-runs validation at a fixed interval and serves the latest result on
+/healthz (200 when passing, 503 otherwise) and /report.json.
+The config file is reloaded before every run. /livez and /readyz serve as liveness
+and readiness probes. On SIGTERM the current table is finished, in-flight requests
+complete and the Spanner client is closed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().DurationVar(&serveInterval, "interval", 5*time.Minute, "Time between validation runs")
+	serveCmd.Flags().StringVar(&serveListen, "listen", ":8080", "HTTP listen address")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	configPath := args[0]
+	if cleanup != nil {
+		defer cleanup()
+	}
+	if serveInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	if configPath == stdinConfig {
+		return fmt.Errorf("serve reloads the config before every run and cannot read it from stdin")
+	}
+	ctx, stop := signalContext()
+	defer stop()
+
+	spannerClient, err := newSpannerClient(context.Background())
+	if err != nil {
+		return fmt.Errorf("creating spanner client: %w", err)
+	}
+	defer spannerClient.Close()
+
+	srv := server.New()
+	httpServer, errCh := startHTTP(srv, serveListen)
+	logging.L().Info("Serving validation results", "listen", serveListen, "interval", serveInterval)
+
+	ticker := time.NewTicker(serveInterval)
+	defer ticker.Stop()
+	for {
+		// A signal during the run stops it after the current table; the partial report is kept.
+		srv.Update(validateOnce(ctx, configPath, spannerClient))
+		select {
+		case err := <-errCh:
+			return fmt.Errorf("http server: %w", err)
+		case <-ctx.Done():
+			shutdownHTTP(srv, httpServer)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// validateOnce loads the config and runs a full validation, returning its report.
+// Config errors are reported as a failed run rather than stopping the loop.
+func validateOnce(ctx context.Context, configPath string, client *spanner.Client) *report.Report {
+	start := time.Now()
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		logging.L().Error("Failed to load config", "config", configPath, "error", err)
+		res := &validator.Result{Tables: []validator.TableResult{{Table: "(config)", Err: err}}}
+		r := report.FromResult(res, start, time.Since(start))
+		r.Metadata = runMetadata(nil, database)
+		return r
+	}
+
+	v := validator.NewValidator(cfg, client, validator.WithShowMatches(showMatches), validator.WithMemoryStats(true),
+		validator.WithAnchor(anchorTime))
+	res := v.Run(ctx)
+	if err := res.Err(); err != nil {
+		logging.L().Error("Validation failed", "error", err)
+	} else {
+		logging.L().Info("Validation completed successfully")
+	}
+	r := report.FromResult(res, start, time.Since(start))
+	r.Metadata = runMetadata(cfg, database)
+	return r
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nu0ma/spalidate/internal/logging"
+	"github.com/nu0ma/spalidate/internal/server"
+)
+
+// shutdownTimeout bounds how long in-flight HTTP requests may take to finish on shutdown.
+const shutdownTimeout = 30 * time.Second
+
+// signalContext returns a context cancelled on SIGINT or SIGTERM. The signals are then
+// released, so a second one terminates the process.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	context.AfterFunc(ctx, stop)
+	return ctx, stop
+}
+
+// startHTTP serves srv on addr in the background. Listen errors are sent on the returned channel.
+func startHTTP(srv *server.Server, addr string) (*http.Server, <-chan error) {
+	httpServer := &http.Server{Addr: addr, Handler: srv.Handler()}
+	errCh := make(chan error, 1)
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+	return httpServer, errCh
+}
+
+// shutdownHTTP marks srv as not ready and waits for in-flight requests to complete.
+func shutdownHTTP(srv *server.Server, httpServer *http.Server) {
+	srv.SetDraining()
+	logging.L().Info("Shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil {
+		logging.L().Warn("HTTP server did not shut down cleanly", "error", err)
+	}
+}
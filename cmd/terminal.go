@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// asciiTerminal guesses whether the output cannot render emoji: classic Windows consoles
+// (outside Windows Terminal) and non-UTF-8 locales such as LANG=C.
+func asciiTerminal() bool {
+	if runtime.GOOS == "windows" {
+		return os.Getenv("WT_SESSION") == ""
+	}
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			v = strings.ToUpper(v)
+			return !strings.Contains(v, "UTF-8") && !strings.Contains(v, "UTF8")
+		}
+	}
+	return false
+}
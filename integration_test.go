@@ -10,13 +10,8 @@ import (
 	"time"
 
 	"cloud.google.com/go/spanner"
-	"github.com/apstndb/spanemuboost"
-	tcspanner "github.com/testcontainers/testcontainers-go/modules/gcloud/spanner"
-)
-
-var (
-	emulatorHost string
-	emulator     *tcspanner.Container
+	"github.com/nu0ma/spalidate/spalidate"
+	"github.com/nu0ma/spalidate/spalidatetest"
 )
 
 var schema = `
@@ -46,59 +41,8 @@ CREATE TABLE Books (
 ) PRIMARY KEY (BookID);
 `
 
-var ddls = parseSchemaStatements(string(schema))
-
 func TestMain(m *testing.M) {
-	ctx := context.Background()
-
-	var teardown func()
-	var err error
-	emulator, teardown, err = spanemuboost.NewEmulator(ctx,
-		spanemuboost.EnableInstanceAutoConfigOnly(),
-	)
-	if err != nil {
-		panic(fmt.Sprintf("Failed to create emulator: %v", err))
-	}
-
-	host, err := emulator.Host(ctx)
-	if err != nil {
-		panic(fmt.Sprintf("Failed to get emulator host: %v", err))
-	}
-	port, err := emulator.MappedPort(ctx, "9010/tcp")
-	if err != nil {
-		panic(fmt.Sprintf("Failed to get emulator port: %v", err))
-	}
-	emulatorHost = fmt.Sprintf("%s:%s", host, port.Port())
-	if err := os.Setenv("SPANNER_EMULATOR_HOST", emulatorHost); err != nil {
-		panic(fmt.Sprintf("Failed to set SPANNER_EMULATOR_HOST: %v", err))
-	}
-
-	code := m.Run()
-	teardown()
-	os.Exit(code)
-}
-
-func parseSchemaStatements(schema string) []string {
-	lines := strings.Split(schema, "\n")
-	var cleanLines []string
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if trimmed != "" && !strings.HasPrefix(trimmed, "--") {
-			cleanLines = append(cleanLines, line)
-		}
-	}
-	cleanContent := strings.Join(cleanLines, "\n")
-
-	statements := strings.Split(cleanContent, ";")
-	var result []string
-	for _, stmt := range statements {
-		trimmed := strings.TrimSpace(stmt)
-		if trimmed != "" {
-			result = append(result, trimmed)
-		}
-	}
-
-	return result
+	os.Exit(spalidatetest.Main(m, spalidatetest.WithSchema(schema)))
 }
 
 var fixedTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -121,7 +65,7 @@ func runSpalidateWithFile(filePath string, verbose bool, project, instance, data
 	}
 
 	cmd := exec.Command("./spalidate", args...)
-	cmd.Env = append(os.Environ(), fmt.Sprintf("SPANNER_EMULATOR_HOST=%s", emulatorHost))
+	cmd.Env = append(os.Environ(), fmt.Sprintf("SPANNER_EMULATOR_HOST=%s", spalidatetest.EmulatorHost()))
 
 	output, err := cmd.CombinedOutput()
 	return string(output), err
@@ -183,21 +127,12 @@ func TestCLIValidation(t *testing.T) {
 
 	t.Run("Test_ValidationSuccess", func(t *testing.T) {
 		t.Parallel()
-		clients, clientsTeardown, err := spanemuboost.NewClients(ctx, emulator,
-			spanemuboost.EnableDatabaseAutoConfigOnly(),
-			spanemuboost.WithRandomDatabaseID(),
-			spanemuboost.WithSetupDDLs(ddls),
-		)
-		if err != nil {
-			t.Fatal(err)
-		}
-		defer clientsTeardown()
-
-		if err := initializeTestData(ctx, clients.Client); err != nil {
+		db := spalidatetest.NewDatabase(t)
+		if err := initializeTestData(ctx, db.Client); err != nil {
 			t.Fatal(err)
 		}
 
-		output, err := runSpalidateWithFile("test_validation.yaml", true, clients.ProjectID, clients.InstanceID, clients.DatabaseID)
+		output, err := runSpalidateWithFile("test_validation.yaml", true, db.ProjectID, db.InstanceID, db.DatabaseID)
 		if err != nil {
 			t.Fatalf("Validation failed: %v\nOutput: %s", err, output)
 		}
@@ -209,21 +144,12 @@ func TestCLIValidation(t *testing.T) {
 
 	t.Run("Test_ValidationFailure", func(t *testing.T) {
 		t.Parallel()
-		clients, clientsTeardown, err := spanemuboost.NewClients(ctx, emulator,
-			spanemuboost.EnableDatabaseAutoConfigOnly(),
-			spanemuboost.WithRandomDatabaseID(),
-			spanemuboost.WithSetupDDLs(ddls),
-		)
-		if err != nil {
+		db := spalidatetest.NewDatabase(t)
+		if err := initializeTestData(ctx, db.Client); err != nil {
 			t.Fatal(err)
 		}
-		defer clientsTeardown()
 
-		if err := initializeTestData(ctx, clients.Client); err != nil {
-			t.Fatal(err)
-		}
-
-		output, err := runSpalidateWithFile("test_fail.yaml", true, clients.ProjectID, clients.InstanceID, clients.DatabaseID)
+		output, err := runSpalidateWithFile("test_fail.yaml", true, db.ProjectID, db.InstanceID, db.DatabaseID)
 		if err == nil {
 			t.Fatalf("should fail, got error: %v\nOutput: %s", err, output)
 		}
@@ -232,3 +158,15 @@ func TestCLIValidation(t *testing.T) {
 		}
 	})
 }
+
+func TestAssert(t *testing.T) {
+	t.Parallel()
+	db := spalidatetest.NewDatabase(t)
+	if err := initializeTestData(context.Background(), db.Client); err != nil {
+		t.Fatal(err)
+	}
+
+	spalidatetest.Assert(t, db.Client, "test_validation.yaml")
+	users := int64(3)
+	spalidatetest.AssertTable(t, db.Client, "Users", spalidate.TableConfig{Count: &users})
+}
@@ -0,0 +1,46 @@
+// Package cache remembers passing validation runs so unchanged runs can be skipped.
+//
+// An entry is an empty marker file named after the run key. Only passing runs are stored,
+// so a cache hit always means "this exact run passed before".
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Key derives a cache key from the parts that identify a run, such as the config hash,
+// the database path and the read timestamp.
+func Key(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Passed reports whether a passing run with key is recorded in dir.
+func Passed(dir, key string) (bool, error) {
+	_, err := os.Stat(filepath.Join(dir, key))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, os.ErrNotExist):
+		return false, nil
+	default:
+		return false, fmt.Errorf("reading cache: %w", err)
+	}
+}
+
+// RecordPass records a passing run with key in dir, creating dir if needed.
+func RecordPass(dir, key string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, key), nil, 0o644); err != nil {
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package cache
+
+import "testing"
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	key := Key("config-hash", "projects/p/instances/i/databases/d", "")
+	if key == Key("config-hash", "projects/p/instances/i/databases/d", "2025-01-01T00:00:00Z") {
+		t.Error("Expected the read timestamp to change the key")
+	}
+
+	if hit, err := Passed(dir, key); err != nil || hit {
+		t.Fatalf("Expected a miss in an empty cache, got %v, %v", hit, err)
+	}
+	if err := RecordPass(dir, key); err != nil {
+		t.Fatal(err)
+	}
+	if hit, err := Passed(dir, key); err != nil || !hit {
+		t.Errorf("Expected a hit after recording, got %v, %v", hit, err)
+	}
+}
@@ -0,0 +1,289 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+// maxAvroSize bounds the blocks and values of an Avro file, whose lengths are read from
+// the file before the data.
+const maxAvroSize = 256 << 20
+
+// readAvroRows reads every record of an Avro object container file as a column map.
+// Only the null and deflate codecs are supported. Records must be flat: fields may be
+// primitives, unions of null and a primitive, or the date/timestamp logical types.
+func readAvroRows(path string) ([]map[string]any, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	r := bufio.NewReader(f)
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, fmt.Errorf("reading avro header: %w", err)
+	}
+	if !bytes.Equal(magic, []byte{'O', 'b', 'j', 1}) {
+		return nil, errors.New("not an avro object container file")
+	}
+
+	meta, err := readAvroMap(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading avro metadata: %w", err)
+	}
+	sync := make([]byte, 16)
+	if _, err := io.ReadFull(r, sync); err != nil {
+		return nil, fmt.Errorf("reading avro sync marker: %w", err)
+	}
+
+	var schema avroSchema
+	if err := json.Unmarshal(meta["avro.schema"], &schema); err != nil {
+		return nil, fmt.Errorf("parsing avro schema: %w", err)
+	}
+	if schema.Type != "record" {
+		return nil, fmt.Errorf("avro schema must be a record, got %s", schema.Type)
+	}
+	fields := make([]avroField, len(schema.Fields))
+	for i, raw := range schema.Fields {
+		if fields[i], err = parseAvroField(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	codec := string(meta["avro.codec"])
+	var rows []map[string]any
+	for {
+		count, err := readAvroLong(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading avro block: %w", err)
+		}
+		size, err := readAvroLong(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading avro block: %w", err)
+		}
+		if err := checkAvroSize(size); err != nil {
+			return nil, fmt.Errorf("reading avro block: %w", err)
+		}
+		block := make([]byte, size)
+		if _, err := io.ReadFull(r, block); err != nil {
+			return nil, fmt.Errorf("reading avro block: %w", err)
+		}
+
+		var data io.ReadCloser
+		switch codec {
+		case "", "null":
+			data = io.NopCloser(bytes.NewReader(block))
+		case "deflate":
+			data = flate.NewReader(bytes.NewReader(block))
+		default:
+			return nil, fmt.Errorf("unsupported avro codec %q", codec)
+		}
+		rows, err = readAvroBlock(bufio.NewReader(data), count, fields, rows)
+		_ = data.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		marker := make([]byte, 16)
+		if _, err := io.ReadFull(r, marker); err != nil {
+			return nil, fmt.Errorf("reading avro sync marker: %w", err)
+		}
+		if !bytes.Equal(marker, sync) {
+			return nil, errors.New("avro sync marker mismatch")
+		}
+	}
+	return rows, nil
+}
+
+// readAvroBlock appends the count records of a decoded block to rows.
+func readAvroBlock(br *bufio.Reader, count int64, fields []avroField, rows []map[string]any) ([]map[string]any, error) {
+	for i := int64(0); i < count; i++ {
+		row := make(map[string]any, len(fields))
+		for _, fd := range fields {
+			v, err := fd.read(br)
+			if err != nil {
+				return nil, fmt.Errorf("decoding field %s: %w", fd.name, err)
+			}
+			row[fd.name] = v
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+type avroSchema struct {
+	Type   string            `json:"type"`
+	Fields []json.RawMessage `json:"fields"`
+}
+
+type avroField struct {
+	name string
+	// branches lists the types of a union in order; a plain field has a single branch.
+	branches []avroType
+}
+
+type avroType struct {
+	Type        string `json:"type"`
+	LogicalType string `json:"logicalType"`
+}
+
+func parseAvroField(raw json.RawMessage) (avroField, error) {
+	var f struct {
+		Name string          `json:"name"`
+		Type json.RawMessage `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return avroField{}, fmt.Errorf("parsing avro field: %w", err)
+	}
+	var union []json.RawMessage
+	if err := json.Unmarshal(f.Type, &union); err != nil {
+		union = []json.RawMessage{f.Type}
+	}
+	fd := avroField{name: f.Name}
+	for _, u := range union {
+		var t avroType
+		var name string
+		if err := json.Unmarshal(u, &name); err == nil {
+			t.Type = name
+		} else if err := json.Unmarshal(u, &t); err != nil {
+			return avroField{}, fmt.Errorf("parsing avro type of field %s: %w", f.Name, err)
+		}
+		fd.branches = append(fd.branches, t)
+	}
+	return fd, nil
+}
+
+func (f avroField) read(r *bufio.Reader) (any, error) {
+	t := f.branches[0]
+	if len(f.branches) > 1 {
+		idx, err := readAvroLong(r)
+		if err != nil {
+			return nil, err
+		}
+		if idx < 0 || int(idx) >= len(f.branches) {
+			return nil, fmt.Errorf("union index %d out of range", idx)
+		}
+		t = f.branches[idx]
+	}
+	return readAvroValue(r, t)
+}
+
+func readAvroValue(r *bufio.Reader, t avroType) (any, error) {
+	switch t.Type {
+	case "null":
+		return nil, nil
+	case "boolean":
+		b, err := r.ReadByte()
+		return b != 0, err
+	case "int", "long":
+		n, err := readAvroLong(r)
+		if err != nil {
+			return nil, err
+		}
+		switch t.LogicalType {
+		case "date":
+			return time.Unix(n*86400, 0).UTC().Format("2006-01-02"), nil
+		case "timestamp-millis":
+			return time.UnixMilli(n).UTC(), nil
+		case "timestamp-micros":
+			return time.UnixMicro(n).UTC(), nil
+		}
+		return n, nil
+	case "float":
+		var b [4]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b[:]))), nil
+	case "double":
+		var b [8]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b[:])), nil
+	case "string", "bytes":
+		b, err := readAvroBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		if t.Type == "bytes" {
+			return b, nil
+		}
+		return string(b), nil
+	}
+	return nil, fmt.Errorf("unsupported avro type %q", t.Type)
+}
+
+func readAvroLong(r *bufio.Reader) (int64, error) {
+	u, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, err
+	}
+	return int64(u>>1) ^ -int64(u&1), nil
+}
+
+func readAvroBytes(r *bufio.Reader) ([]byte, error) {
+	n, err := readAvroLong(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAvroSize(n); err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
+
+func checkAvroSize(n int64) error {
+	switch {
+	case n < 0:
+		return fmt.Errorf("negative length %d", n)
+	case n > maxAvroSize:
+		return fmt.Errorf("length %d exceeds the limit of %d bytes", n, maxAvroSize)
+	}
+	return nil
+}
+
+func readAvroMap(r *bufio.Reader) (map[string][]byte, error) {
+	m := make(map[string][]byte)
+	for {
+		count, err := readAvroLong(r)
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return m, nil
+		}
+		if count < 0 {
+			count = -count
+			if _, err := readAvroLong(r); err != nil {
+				return nil, err
+			}
+		}
+		for i := int64(0); i < count; i++ {
+			k, err := readAvroBytes(r)
+			if err != nil {
+				return nil, err
+			}
+			v, err := readAvroBytes(r)
+			if err != nil {
+				return nil, err
+			}
+			m[string(k)] = v
+		}
+	}
+}
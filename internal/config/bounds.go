@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Now is the bound value `!now` (also accepted quoted, `"!now"`): the time of the check.
+const Now = "!now"
+
+// Bound limits the values of a column (`columnBounds: {Price: {min: 0}}`), checked with
+// MIN and MAX queries. A nil limit is unchecked; both are inclusive.
+type Bound struct {
+	Min any `yaml:"min,omitempty"`
+	Max any `yaml:"max,omitempty"`
+}
+
+// UnmarshalYAML decodes the limits, accepting the `!now` tag.
+func (b *Bound) UnmarshalYAML(n *yaml.Node) error {
+	var raw struct {
+		Min yaml.Node `yaml:"min"`
+		Max yaml.Node `yaml:"max"`
+	}
+	if err := n.Decode(&raw); err != nil {
+		return err
+	}
+	// a zero Kind marks an absent limit
+	if raw.Min.Kind == 0 && raw.Max.Kind == 0 {
+		return fmt.Errorf("line %d: column bound needs min or max", n.Line)
+	}
+	for _, l := range []struct {
+		node *yaml.Node
+		dst  *any
+	}{{&raw.Min, &b.Min}, {&raw.Max, &b.Max}} {
+		switch {
+		case l.node.Kind == 0:
+		case l.node.Tag == Now:
+			*l.dst = Now
+		default:
+			if err := l.node.Decode(l.dst); err != nil {
+				return err
+			}
+			if *l.dst == nil {
+				return fmt.Errorf("line %d: column bound cannot be null", l.node.Line)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Change stream mod types.
+const (
+	ModInsert = "INSERT"
+	ModUpdate = "UPDATE"
+	ModDelete = "DELETE"
+)
+
+// ChangeStream asserts the data change records of a change stream over a window ending when
+// validation starts, e.g. to test a CDC pipeline end to end.
+type ChangeStream struct {
+	// Window is how far back the stream is read.
+	Window time.Duration `yaml:"window"`
+	// Records must each match a distinct data change record of the window; other records
+	// are ignored.
+	Records []ChangeRecord `yaml:"records"`
+}
+
+// ChangeRecord is an expected row change.
+type ChangeRecord struct {
+	Table string `yaml:"table"`
+	// ModType is INSERT, UPDATE or DELETE; empty matches any.
+	ModType string `yaml:"modType,omitempty" default:"any"`
+	// Keys are primary key values of the changed row; unlisted key columns match anything.
+	Keys map[string]any `yaml:"keys,omitempty"`
+}
+
+// String describes the record, e.g. `INSERT Orders {OrderID: 1}`.
+func (r ChangeRecord) String() string {
+	var b strings.Builder
+	if r.ModType != "" {
+		b.WriteString(r.ModType + " ")
+	}
+	b.WriteString(r.Table)
+	if len(r.Keys) > 0 {
+		parts := make([]string, 0, len(r.Keys))
+		for _, k := range slices.Sorted(maps.Keys(r.Keys)) {
+			parts = append(parts, fmt.Sprintf("%s: %v", k, r.Keys[k]))
+		}
+		fmt.Fprintf(&b, " {%s}", strings.Join(parts, ", "))
+	}
+	return b.String()
+}
+
+// streamNamePattern matches a change stream name, which is read through its READ_ function.
+var streamNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validate rejects incomplete change stream assertions and names that are not plain
+// identifiers, and normalizes mod types.
+func (s *ChangeStream) validate(name string) error {
+	if !streamNamePattern.MatchString(name) {
+		return fmt.Errorf("%q is not a plain change stream name", name)
+	}
+	if s.Window <= 0 {
+		return fmt.Errorf("window must be a positive duration")
+	}
+	if len(s.Records) == 0 {
+		return fmt.Errorf("no records expected")
+	}
+	for i := range s.Records {
+		r := &s.Records[i]
+		if r.Table == "" {
+			return fmt.Errorf("record %d: table is required", i+1)
+		}
+		r.ModType = strings.ToUpper(r.ModType)
+		switch r.ModType {
+		case "", ModInsert, ModUpdate, ModDelete:
+		default:
+			return fmt.Errorf("record %d: unknown modType %q", i+1, r.ModType)
+		}
+	}
+	return nil
+}
@@ -1,30 +1,709 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"math"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/nu0ma/spalidate/internal/errkind"
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
+	// Version is the config format version; see CurrentVersion. Absent means version 1.
+	Version int `yaml:"version,omitempty" default:"1"`
+	// Options are the comparison options applied to every table.
+	Options ComparisonOptions `yaml:"options,omitempty"`
+	// Defaults are table settings applied to every table that does not set them.
+	Defaults *TableDefaults `yaml:"defaults,omitempty"`
+	// Definitions are reusable rows referenced from tables with `!ref`.
+	Definitions Definitions `yaml:"definitions,omitempty"`
+	// Before and After are SQL statements (DML or DDL) run around validation.
+	Before []string `yaml:"before,omitempty"`
+	After  []string `yaml:"after,omitempty"`
+	// Seed holds rows written before the tables are validated.
+	Seed   *Seed                  `yaml:"seed,omitempty"`
 	Tables map[string]TableConfig `yaml:"tables"`
+	// ChangeStreams holds change record assertions, keyed by change stream name.
+	ChangeStreams map[string]ChangeStream `yaml:"changeStreams,omitempty"`
+	// DatabaseOptions asserts database options by name, as listed in
+	// INFORMATION_SCHEMA.DATABASE_OPTIONS (e.g. default_leader); "" asserts an unset option.
+	DatabaseOptions map[string]string `yaml:"databaseOptions,omitempty"`
+
+	// Warnings lists ambiguous expectations found while parsing; see lint.
+	Warnings []string `yaml:"-"`
+	// Path is the file the configuration was loaded from; empty when it was parsed from
+	// memory.
+	Path string `yaml:"-"`
+}
+
+// RowPosition locates an expected row in the configuration, e.g. "validation.yaml: line 12",
+// or returns "" for rows that were not read from it.
+func (c *Config) RowPosition(row map[string]any) string {
+	line := LineOf(row)
+	switch {
+	case line == 0:
+		return ""
+	case c.Path == "":
+		return fmt.Sprintf("line %d", line)
+	default:
+		return fmt.Sprintf("%s: line %d", c.Path, line)
+	}
+}
+
+// TableDefaults holds the table settings that can be given once for every table. They are
+// copied into the tables when the config is loaded.
+type TableDefaults struct {
+	// IgnoreColumns applies to tables without ignoreColumns; `ignoreColumns: []` opts out.
+	IgnoreColumns []string `yaml:"ignoreColumns,omitempty"`
+}
+
+// ComparisonOptions tune how actual values are compared with expected values.
+// Unset fields mean exact comparison; pointer fields are nil when unset, so a table can
+// override a global option with 0 or false.
+type ComparisonOptions struct {
+	// FloatTolerance is the maximum absolute difference for floating point comparisons.
+	FloatTolerance *float64 `yaml:"floatTolerance,omitempty"`
+	// RelativeTolerance is the maximum difference relative to the larger magnitude,
+	// e.g. 0.001 accepts values within 0.1% of each other.
+	RelativeTolerance *float64 `yaml:"relativeTolerance,omitempty"`
+	// NumericMode selects how NUMERIC values are compared: "exact" (default),
+	// "round(n)" to compare after rounding to n decimal places, or "tolerance"
+	// to apply the float tolerances.
+	NumericMode string `yaml:"numericMode,omitempty" default:"exact"`
+	// CoerceBooleans accepts the strings "true"/"false"/"1"/"0" as expected BOOL values.
+	CoerceBooleans *bool `yaml:"coerceBooleans,omitempty"`
+	// TimestampTruncateTo truncates both TIMESTAMP values to this precision (e.g. 1ms)
+	// before comparing them.
+	TimestampTruncateTo *time.Duration `yaml:"timestampTruncateTo,omitempty"`
+	// AllowUnorderedRows lets expected rows match actual rows in any order (the default).
+	// Set it to false to require the rows in primary key order.
+	AllowUnorderedRows *bool `yaml:"allowUnorderedRows,omitempty" default:"true"`
+	// AllowExtraColumns lets actual rows have columns that the expected rows do not list.
+	// The listed columns are still compared.
+	AllowExtraColumns *bool `yaml:"allowExtraColumns,omitempty"`
+	// StrictTypes disables implicit coercions: numbers must be written with the column's
+	// numeric type, and numeric, boolean and raw bytes strings are not converted.
+	StrictTypes *bool `yaml:"strictTypes,omitempty"`
+	// JSONArrayOrder selects how arrays inside JSON values are compared: "strict" (default)
+	// compares elements by position, "ignore" compares them as multisets.
+	JSONArrayOrder string `yaml:"jsonArrayOrder,omitempty" default:"strict"`
+	// ExtraRowExamples is how many unexpected rows a row count failure shows; 0 shows none.
+	ExtraRowExamples *int `yaml:"extraRowExamples,omitempty" default:"3"`
+}
+
+// DefaultExtraRowExamples is the number of unexpected rows shown without extraRowExamples.
+const DefaultExtraRowExamples = 3
+
+// ExtraRows returns the number of unexpected rows to show.
+func (o ComparisonOptions) ExtraRows() int {
+	if o.ExtraRowExamples == nil {
+		return DefaultExtraRowExamples
+	}
+	return *o.ExtraRowExamples
+}
+
+// JSON array orders for ComparisonOptions.JSONArrayOrder.
+const (
+	JSONArrayOrderStrict = "strict"
+	JSONArrayOrderIgnore = "ignore"
+)
+
+// validate rejects option values that are not understood.
+func (o *ComparisonOptions) validate() error {
+	if o == nil {
+		return nil
+	}
+	switch o.JSONArrayOrder {
+	case "", JSONArrayOrderStrict, JSONArrayOrderIgnore:
+	default:
+		return fmt.Errorf("unknown jsonArrayOrder %q", o.JSONArrayOrder)
+	}
+	if o.ExtraRowExamples != nil && *o.ExtraRowExamples < 0 {
+		return fmt.Errorf("extraRowExamples must not be negative")
+	}
+	_, _, err := ParseNumericMode(o.NumericMode)
+	return err
+}
+
+// ParseNumericMode splits a numericMode value into its mode ("exact", "round" or
+// "tolerance") and the decimal places of round(n).
+func ParseNumericMode(s string) (mode string, places int, err error) {
+	switch s {
+	case "", "exact":
+		return "exact", 0, nil
+	case "tolerance":
+		return "tolerance", 0, nil
+	}
+	if inner, ok := strings.CutPrefix(s, "round("); ok {
+		if digits, ok := strings.CutSuffix(inner, ")"); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(digits))
+			if err == nil && n >= 0 {
+				return "round", n, nil
+			}
+		}
+	}
+	return "", 0, fmt.Errorf("invalid numericMode %q (want exact, round(n) or tolerance)", s)
+}
+
+// UnorderedRows reports whether rows may match in any order.
+func (o ComparisonOptions) UnorderedRows() bool {
+	return o.AllowUnorderedRows == nil || *o.AllowUnorderedRows
+}
+
+// AbsTolerance returns FloatTolerance, 0 when unset.
+func (o ComparisonOptions) AbsTolerance() float64 {
+	if o.FloatTolerance == nil {
+		return 0
+	}
+	return *o.FloatTolerance
+}
+
+// RelTolerance returns RelativeTolerance, 0 when unset.
+func (o ComparisonOptions) RelTolerance() float64 {
+	if o.RelativeTolerance == nil {
+		return 0
+	}
+	return *o.RelativeTolerance
+}
+
+// TimestampPrecision returns TimestampTruncateTo, 0 (no truncation) when unset.
+func (o ComparisonOptions) TimestampPrecision() time.Duration {
+	if o.TimestampTruncateTo == nil {
+		return 0
+	}
+	return *o.TimestampTruncateTo
+}
+
+// BooleanCoercion reports whether strings are accepted as expected BOOL values.
+func (o ComparisonOptions) BooleanCoercion() bool {
+	return o.CoerceBooleans != nil && *o.CoerceBooleans
+}
+
+// ExtraColumns reports whether actual rows may have columns the expected rows omit.
+func (o ComparisonOptions) ExtraColumns() bool {
+	return o.AllowExtraColumns != nil && *o.AllowExtraColumns
+}
+
+// Strict reports whether implicit type coercions are disabled.
+func (o ComparisonOptions) Strict() bool {
+	return o.StrictTypes != nil && *o.StrictTypes
+}
+
+// Merge returns o with the fields set in override applied; a set pointer field, even to
+// zero or false, overrides.
+func (o ComparisonOptions) Merge(override *ComparisonOptions) ComparisonOptions {
+	if override == nil {
+		return o
+	}
+	if override.FloatTolerance != nil {
+		o.FloatTolerance = override.FloatTolerance
+	}
+	if override.RelativeTolerance != nil {
+		o.RelativeTolerance = override.RelativeTolerance
+	}
+	if override.NumericMode != "" {
+		o.NumericMode = override.NumericMode
+	}
+	if override.CoerceBooleans != nil {
+		o.CoerceBooleans = override.CoerceBooleans
+	}
+	if override.TimestampTruncateTo != nil {
+		o.TimestampTruncateTo = override.TimestampTruncateTo
+	}
+	if override.AllowUnorderedRows != nil {
+		o.AllowUnorderedRows = override.AllowUnorderedRows
+	}
+	if override.AllowExtraColumns != nil {
+		o.AllowExtraColumns = override.AllowExtraColumns
+	}
+	if override.StrictTypes != nil {
+		o.StrictTypes = override.StrictTypes
+	}
+	if override.JSONArrayOrder != "" {
+		o.JSONArrayOrder = override.JSONArrayOrder
+	}
+	if override.ExtraRowExamples != nil {
+		o.ExtraRowExamples = override.ExtraRowExamples
+	}
+	return o
+}
+
+// Seed ops select the mutation used to write seed rows.
+const (
+	SeedInsert         = "insert"
+	SeedInsertOrUpdate = "insertOrUpdate"
+	SeedReplace        = "replace"
+	SeedUpdate         = "update"
+)
+
+// Seed describes the data written before the assertions run, so a scenario can be
+// arranged and asserted from one file.
+type Seed struct {
+	// Fixtures are YAML files with a `mutations:` list, applied before the inline mutations.
+	Fixtures  []string       `yaml:"fixtures,omitempty"`
+	Mutations []SeedMutation `yaml:"mutations,omitempty"`
+}
+
+type SeedMutation struct {
+	Table string `yaml:"table"`
+	// Op is insert (default), insertOrUpdate, replace or update.
+	Op   string           `yaml:"op,omitempty" default:"insert"`
+	Rows []map[string]any `yaml:"rows"`
 }
 
 type TableConfig struct {
-    Columns []map[string]any `yaml:"columns,omitempty"`
+	// When is an optional condition; the table is skipped when it evaluates to false.
+	When string `yaml:"when,omitempty"`
+	// Where is an optional SQL condition restricting the rows read from the table, e.g.
+	// `TenantID = @tenant`. Its parameters are bound from Params.
+	Where   string           `yaml:"where,omitempty"`
+	Params  map[string]Param `yaml:"params,omitempty"`
+	Columns Rows             `yaml:"columns,omitempty"`
+	// RowsByKey writes the expected rows keyed by their primary key value, for tables with a
+	// single-column primary key. The loader moves them to Columns with the primaryKey
+	// strategy.
+	RowsByKey KeyedRows `yaml:"rowsByKey,omitempty"`
+	// Count asserts the number of rows (after Where) without listing them.
+	Count *int64 `yaml:"count,omitempty"`
+	// CountTolerance allows Count to be off by an absolute number of rows ("5") or a
+	// percentage of Count ("1%").
+	CountTolerance string `yaml:"countTolerance,omitempty"`
+	// Distribution asserts the number of rows per value of a column.
+	Distribution *Distribution `yaml:"distribution,omitempty"`
+	// ColumnBounds limits the values of columns, checked with MIN and MAX queries.
+	ColumnBounds map[string]Bound `yaml:"columnBounds,omitempty"`
+	// Monotonic asserts the order of a column's values over the rows.
+	Monotonic *Monotonic `yaml:"monotonic,omitempty"`
+	// GeneratedColumns asserts the expression of generated columns, read from the schema,
+	// e.g. `FullName: "CONCAT(First, ' ', Last) STORED"`.
+	GeneratedColumns map[string]string `yaml:"generatedColumns,omitempty"`
+	// CheckConstraints asserts that named CHECK constraints exist with these expressions,
+	// e.g. `PriceNonNegative: Price >= 0`.
+	CheckConstraints map[string]string `yaml:"checkConstraints,omitempty"`
+	// Datasets holds named alternative row sets; one is selected with SelectDataset.
+	Datasets map[string]Rows `yaml:"datasets,omitempty"`
+	// Source loads the expected rows from an external file instead of inline columns.
+	Source *SourceConfig `yaml:"source,omitempty"`
+	// Options override the global comparison options for this table.
+	Options *ComparisonOptions `yaml:"options,omitempty"`
+	// Strategy selects how expected rows are paired with actual rows; see RowStrategy.
+	Strategy string `yaml:"strategy,omitempty" default:"strict"`
+	// AllowMissingColumns lists columns that may be absent from the actual rows, e.g. in
+	// views that differ across schema versions, with the value to assume when they are.
+	AllowMissingColumns map[string]any `yaml:"allowMissingColumns,omitempty"`
+	// IgnoreColumns lists columns that are not read, e.g. audit or commit timestamp
+	// columns, so expected rows need not list them even under the strict column check.
+	IgnoreColumns []string `yaml:"ignoreColumns,omitempty"`
+	// SkipRows lists 1-based positions of expected rows to ignore, like `__ignore: true`.
+	SkipRows []int `yaml:"skipRows,omitempty"`
+	// ExpectedFailure marks the table as known to fail, e.g. with an issue key. Its failure
+	// does not fail the run; its passing is reported so the marker can be removed.
+	ExpectedFailure string `yaml:"expectedFailure,omitempty"`
+	// Message is prepended to the table's errors, to give failures domain context.
+	Message string `yaml:"message,omitempty"`
+}
+
+// ExpectedRows returns the table's expected rows with the SkipRows marked as ignored.
+func (t TableConfig) ExpectedRows() (Rows, error) {
+	if len(t.SkipRows) == 0 {
+		return t.Columns, nil
+	}
+	rows := make(Rows, len(t.Columns))
+	copy(rows, t.Columns)
+	for _, n := range t.SkipRows {
+		if n < 1 || n > len(rows) {
+			return nil, fmt.Errorf("skipRows: row %d out of range (1-%d)", n, len(rows))
+		}
+		if _, ok := AsAnyRow(rows[n-1]); ok {
+			continue
+		}
+		row := make(map[string]any, len(rows[n-1])+1)
+		for k, v := range rows[n-1] {
+			row[k] = v
+		}
+		row[ignoreKey] = true
+		rows[n-1] = row
+	}
+	return rows, nil
+}
+
+// Row strategies select how the expected rows of a table are paired with its actual rows.
+const (
+	// StrategyStrict matches every expected row to a distinct actual row in any order,
+	// with no rows left over.
+	StrategyStrict = "strict"
+	// StrategySubset only requires the expected rows to be present; other rows are ignored.
+	StrategySubset = "subset"
+	// StrategyPrimaryKey pairs rows by their primary key columns and reports differences per key.
+	StrategyPrimaryKey = "primaryKey"
+	// StrategyOrdered requires the rows in primary key order, position by position.
+	StrategyOrdered = "ordered"
+)
+
+// RowStrategy returns the table's strategy. Without an explicit strategy it is ordered
+// when opts disallow unordered rows, and strict otherwise.
+func (t TableConfig) RowStrategy(opts ComparisonOptions) string {
+	switch {
+	case t.Strategy != "":
+		return t.Strategy
+	case !opts.UnorderedRows():
+		return StrategyOrdered
+	default:
+		return StrategyStrict
+	}
+}
+
+type SourceConfig struct {
+	Path string `yaml:"path"`
+	// Format is "avro" or "prototext"; inferred from the file extension when empty.
+	Format string `yaml:"format,omitempty" default:"file extension"`
+	// Descriptor is a FileDescriptorSet (protoc --descriptor_set_out) used by prototext sources.
+	Descriptor string `yaml:"descriptor,omitempty"`
+	// Message is the fully-qualified message type of a prototext source.
+	Message string `yaml:"message,omitempty"`
+	// Field optionally names a repeated message field whose elements are the rows.
+	Field string `yaml:"field,omitempty"`
 }
 
+// LoadConfig reads and parses a configuration file. Its errors are errkind.ErrConfig.
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, errkind.Mark(fmt.Errorf("failed to read config file: %w", err), errkind.ErrConfig)
 	}
+	cfg, err := Parse(data, filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	cfg.Path = path
+	for i, w := range cfg.Warnings {
+		cfg.Warnings[i] = path + ": " + w
+	}
+	return cfg, nil
+}
 
-	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
+// Parse decodes a YAML (or JSON) configuration. Relative source paths are resolved against
+// baseDir. Its errors are errkind.ErrConfig.
+func Parse(data []byte, baseDir string) (*Config, error) {
+	config, err := parse(data, baseDir, false)
+	if err != nil {
+		return nil, errkind.Mark(err, errkind.ErrConfig)
+	}
+	return config, nil
+}
+
+// parse decodes a config; untrusted configs are checked with checkUntrusted before any file
+// is read.
+func parse(data []byte, baseDir string, untrusted bool) (*Config, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
+	// errs collects every problem found before the config is rejected, so one run reports them all
+	var errs Errors
+	var config Config
+	if err := root.Decode(&config); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		// type errors, including those of rows and matchers, leave the rest decoded
+		for _, msg := range typeErr.Errors {
+			errs = append(errs, errors.New(msg))
+		}
+	}
+	config.Warnings = lint(&root)
+	if err := config.checkVersion(&root); err != nil {
+		return nil, err
+	}
+
+	// checks of decoded values point at the key they come from
+	if err := config.Options.validate(); err != nil {
+		errs = append(errs, atLine(keyLine(&root, "options"), fmt.Errorf("options: %w", err)))
+	}
+	for _, name := range slices.Sorted(maps.Keys(config.Tables)) {
+		t := config.Tables[name]
+		line := func(key string) int { return keyLine(&root, "tables", name, key) }
+		if strings.TrimSpace(name) == "" {
+			errs = append(errs, atLine(keyLine(&root, "tables", name), errors.New("table name is empty")))
+		}
+		switch t.Strategy {
+		case "", StrategyStrict, StrategySubset, StrategyPrimaryKey, StrategyOrdered:
+		default:
+			errs = append(errs, atLine(line("strategy"), fmt.Errorf("table %s: unknown strategy %q", name, t.Strategy)))
+		}
+		if err := t.Options.validate(); err != nil {
+			errs = append(errs, atLine(line("options"), fmt.Errorf("table %s: options: %w", name, err)))
+		}
+		if t.IgnoreColumns == nil && config.Defaults != nil {
+			t.IgnoreColumns = slices.Clone(config.Defaults.IgnoreColumns)
+		}
+		if err := t.expandRowsByKey(); err != nil {
+			errs = append(errs, atLine(line("rowsByKey"), fmt.Errorf("table %s: %w", name, err)))
+		}
+		config.Tables[name] = t
+		if t.When != "" {
+			if _, err := parseWhen(t.When); err != nil {
+				errs = append(errs, atLine(line("when"), fmt.Errorf("table %s: %w", name, err)))
+			}
+		}
+		if t.CountTolerance != "" {
+			if t.Count == nil {
+				errs = append(errs, atLine(line("countTolerance"), fmt.Errorf("table %s: countTolerance requires count", name)))
+			} else if _, _, err := t.CountRange(); err != nil {
+				errs = append(errs, atLine(line("countTolerance"), fmt.Errorf("table %s: %w", name, err)))
+			}
+		}
+		if t.Monotonic != nil {
+			if err := t.Monotonic.validate(); err != nil {
+				errs = append(errs, atLine(line("monotonic"), fmt.Errorf("table %s: %w", name, err)))
+			}
+		}
+		for _, col := range slices.Sorted(maps.Keys(t.GeneratedColumns)) {
+			if strings.TrimSpace(t.GeneratedColumns[col]) == "" {
+				errs = append(errs, atLine(keyLine(&root, "tables", name, "generatedColumns", col),
+					fmt.Errorf("table %s: generated column %s has no expression", name, col)))
+			}
+		}
+		for _, check := range slices.Sorted(maps.Keys(t.CheckConstraints)) {
+			if strings.TrimSpace(t.CheckConstraints[check]) == "" {
+				errs = append(errs, atLine(keyLine(&root, "tables", name, "checkConstraints", check),
+					fmt.Errorf("table %s: check constraint %s has no expression", name, check)))
+			}
+		}
+		if len(t.Params) > 0 && t.Where == "" {
+			errs = append(errs, atLine(line("params"), fmt.Errorf("table %s: params require a where filter", name)))
+		}
+		for _, param := range slices.Sorted(maps.Keys(t.Params)) {
+			if param == RunStartParam || param == RunEndParam {
+				errs = append(errs, atLine(keyLine(&root, "tables", name, "params", param),
+					fmt.Errorf("table %s: param %s is built in and cannot be set", name, param)))
+				continue
+			}
+			if t.Params[param].Bound() == nil {
+				// YAML does not call UnmarshalYAML for null values
+				errs = append(errs, atLine(keyLine(&root, "tables", name, "params", param),
+					fmt.Errorf("table %s: param %s: NULL params are not supported; use IS NULL in the where filter", name, param)))
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(config.ChangeStreams)) {
+		s := config.ChangeStreams[name]
+		if err := s.validate(name); err != nil {
+			errs = append(errs, atLine(keyLine(&root, "changeStreams", name), fmt.Errorf("change stream %s: %w", name, err)))
+		}
+		config.ChangeStreams[name] = s
+	}
+	if untrusted {
+		errs = append(errs, config.checkUntrusted(&root)...)
+	}
+	// the remaining steps build on a valid config
+	if len(errs) > 0 {
+		return nil, errs.err()
+	}
+
+	if err := config.resolveRefs(); err != nil {
+		return nil, err
+	}
+
+	if err := config.loadSources(baseDir); err != nil {
+		return nil, err
+	}
+	if err := config.loadSeed(baseDir); err != nil {
+		return nil, err
+	}
+	if err := config.loadFiles(baseDir); err != nil {
+		return nil, err
+	}
 
 	return &config, nil
 }
+
+// Errors are the problems of a config that failed to load, in the order they were found.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e Errors) Unwrap() []error { return e }
+
+// err returns the single error as is, and several as Errors.
+func (e Errors) err() error {
+	if len(e) == 1 {
+		return e[0]
+	}
+	return e
+}
+
+// loadSources reads the rows of tables that reference an external source file.
+// Relative paths are resolved against baseDir.
+func (c *Config) loadSources(baseDir string) error {
+	for _, tableName := range slices.Sorted(maps.Keys(c.Tables)) {
+		table := c.Tables[tableName]
+		if table.Source == nil {
+			continue
+		}
+		if len(table.Columns) > 0 || len(table.Datasets) > 0 {
+			return fmt.Errorf("table %s defines both source and inline rows", tableName)
+		}
+		path := resolvePath(baseDir, table.Source.Path)
+		format := table.Source.Format
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		}
+
+		var rows []map[string]any
+		var err error
+		switch format {
+		case "avro":
+			rows, err = readAvroRows(path)
+		case "prototext", "txtpb", "textproto":
+			rows, err = readPrototextRows(path, resolvePath(baseDir, table.Source.Descriptor), table.Source)
+		default:
+			err = fmt.Errorf("unknown source format %q", format)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load source for table %s: %w", tableName, err)
+		}
+		table.Columns = rows
+		table.Source = nil
+		c.Tables[tableName] = table
+	}
+	return nil
+}
+
+// LoadSeedFixture reads the `mutations:` list of a seed fixture file.
+func LoadSeedFixture(path string) ([]SeedMutation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed fixture: %w", err)
+	}
+	var f Seed
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse seed fixture %s: %w", path, err)
+	}
+	return f.Mutations, nil
+}
+
+// loadSeed prepends the mutations of seed fixture files and checks every mutation.
+func (c *Config) loadSeed(baseDir string) error {
+	if c.Seed == nil {
+		return nil
+	}
+	var mutations []SeedMutation
+	for _, fixture := range c.Seed.Fixtures {
+		f, err := LoadSeedFixture(resolvePath(baseDir, fixture))
+		if err != nil {
+			return err
+		}
+		mutations = append(mutations, f...)
+	}
+	mutations = append(mutations, c.Seed.Mutations...)
+
+	for i, m := range mutations {
+		if m.Table == "" {
+			return fmt.Errorf("seed mutation %d has no table", i+1)
+		}
+		switch m.Op {
+		case "", SeedInsert, SeedInsertOrUpdate, SeedReplace, SeedUpdate:
+		default:
+			return fmt.Errorf("seed mutation %d (%s): unknown op %q", i+1, m.Table, m.Op)
+		}
+	}
+	c.Seed.Mutations = mutations
+	c.Seed.Fixtures = nil
+	return nil
+}
+
+// SelectDataset replaces the rows of every table that defines datasets with the named dataset.
+// Tables without datasets are left untouched.
+func (c *Config) SelectDataset(name string) error {
+	return errkind.Mark(c.selectDataset(name), errkind.ErrConfig)
+}
+
+func (c *Config) selectDataset(name string) error {
+	for tableName, table := range c.Tables {
+		if len(table.Datasets) == 0 {
+			continue
+		}
+		if name == "" {
+			return fmt.Errorf("table %s defines datasets but no dataset was selected", tableName)
+		}
+		rows, ok := table.Datasets[name]
+		if !ok {
+			return fmt.Errorf("table %s has no dataset %q", tableName, name)
+		}
+		if len(table.Columns) > 0 {
+			return fmt.Errorf("table %s defines both columns and datasets", tableName)
+		}
+		table.Columns = rows
+		table.Datasets = nil
+		c.Tables[tableName] = table
+	}
+	return nil
+}
+
+func resolvePath(baseDir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// CountRange returns the inclusive range of row counts accepted by Count and CountTolerance.
+func (t TableConfig) CountRange() (lo, hi int64, err error) {
+	if t.Count == nil {
+		return 0, 0, errors.New("no count")
+	}
+	var tol float64
+	if s := strings.TrimSpace(t.CountTolerance); s != "" {
+		pct, isPct := strings.CutSuffix(s, "%")
+		tol, err = strconv.ParseFloat(strings.TrimSpace(pct), 64)
+		if err != nil || tol < 0 {
+			return 0, 0, fmt.Errorf("invalid countTolerance %q (want e.g. 5 or 1%%)", t.CountTolerance)
+		}
+		if isPct {
+			tol = float64(*t.Count) * tol / 100
+		}
+	}
+	lo = *t.Count - int64(math.Floor(tol))
+	if lo < 0 {
+		lo = 0
+	}
+	return lo, *t.Count + int64(math.Floor(tol)), nil
+}
+
+// QueryParams returns the bound values of the table's where parameters.
+func (t TableConfig) QueryParams() map[string]any {
+	if len(t.Params) == 0 {
+		return nil
+	}
+	params := make(map[string]any, len(t.Params))
+	for name, p := range t.Params {
+		params[name] = p.Bound()
+	}
+	return params
+}
+
+// Enabled reports whether the table should be validated according to its when condition.
+func (t TableConfig) Enabled() (bool, error) {
+	if t.When == "" {
+		return true, nil
+	}
+	enabled, err := EvalWhen(t.When)
+	return enabled, errkind.Mark(err, errkind.ErrConfig)
+}
@@ -1,9 +1,23 @@
 package config
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"gopkg.in/yaml.v3"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -60,3 +74,852 @@ tables:
 	}
 
 }
+
+func TestEvalWhen(t *testing.T) {
+	t.Setenv("FEATURE_X", "on")
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`{{ env "FEATURE_X" }} == "on"`, true},
+		{`{{ env "FEATURE_X" }} == "off"`, false},
+		{`{{ env "FEATURE_X" }} != "off"`, true},
+		{`{{ env "FEATURE_UNSET" }} == ""`, true},
+		{`{{ env "FEATURE_X" }}`, true},
+		{`{{ env "FEATURE_UNSET" }}`, false},
+		{`false`, false},
+	}
+	for _, tt := range tests {
+		got, err := EvalWhen(tt.expr)
+		if err != nil {
+			t.Errorf("EvalWhen(%q) returned error: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("EvalWhen(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	if _, err := EvalWhen(`maybe`); err == nil {
+		t.Error("Expected error for non-boolean when expression")
+	}
+}
+
+func TestSelectDataset(t *testing.T) {
+	yamlContent := `
+tables:
+  Users:
+    datasets:
+      tenantA:
+        - UserID: "a-001"
+      tenantB:
+        - UserID: "b-001"
+        - UserID: "b-002"
+  Products:
+    columns:
+      - ProductID: "prod-001"
+`
+	tmpFile := filepath.Join(t.TempDir(), "datasets.yaml")
+	if err := os.WriteFile(tmpFile, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if err := config.SelectDataset(""); err == nil {
+		t.Error("Expected error when no dataset is selected")
+	}
+	if err := config.SelectDataset("tenantC"); err == nil {
+		t.Error("Expected error for unknown dataset")
+	}
+	if err := config.SelectDataset("tenantB"); err != nil {
+		t.Fatalf("SelectDataset failed: %v", err)
+	}
+
+	if got := len(config.Tables["Users"].Columns); got != 2 {
+		t.Errorf("Expected 2 Users rows, got %d", got)
+	}
+	if got := config.Tables["Products"].Columns[0]["ProductID"]; got != "prod-001" {
+		t.Errorf("Expected Products rows to be untouched, got %v", got)
+	}
+}
+
+func TestLoadConfigAvroSource(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	long := func(n int64) []byte {
+		return binary.AppendUvarint(nil, uint64((n<<1)^(n>>63)))
+	}
+	str := func(s string) []byte {
+		return append(long(int64(len(s))), s...)
+	}
+
+	schema := `{"type":"record","name":"User","fields":[
+		{"name":"UserID","type":"string"},
+		{"name":"Status","type":"long"},
+		{"name":"Email","type":["null","string"]}]}`
+	sync := bytes.Repeat([]byte{0xab}, 16)
+
+	var block []byte
+	block = append(block, str("user-001")...)
+	block = append(block, long(1)...)
+	block = append(block, long(1)...)
+	block = append(block, str("alice@example.com")...)
+	block = append(block, str("user-002")...)
+	block = append(block, long(2)...)
+	block = append(block, long(0)...)
+
+	var file []byte
+	file = append(file, 'O', 'b', 'j', 1)
+	file = append(file, long(2)...)
+	file = append(file, str("avro.schema")...)
+	file = append(file, str(schema)...)
+	file = append(file, str("avro.codec")...)
+	file = append(file, str("null")...)
+	file = append(file, long(0)...)
+	file = append(file, sync...)
+	file = append(file, long(2)...)
+	file = append(file, long(int64(len(block)))...)
+	file = append(file, block...)
+	file = append(file, sync...)
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "users.avro"), file, 0644); err != nil {
+		t.Fatal(err)
+	}
+	yamlContent := `
+tables:
+  Users:
+    source:
+      path: users.avro
+`
+	tmpFile := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(tmpFile, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	rows := config.Tables["Users"].Columns
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows))
+	}
+	if rows[0]["UserID"] != "user-001" || rows[0]["Status"] != int64(1) || rows[0]["Email"] != "alice@example.com" {
+		t.Errorf("Unexpected first row: %v", rows[0])
+	}
+	if rows[1]["Email"] != nil {
+		t.Errorf("Expected NULL Email in second row, got %v", rows[1]["Email"])
+	}
+
+	// corrupt block sizes are errors, not panics or huge allocations
+	header := file[:len(file)-len(block)-len(sync)-len(long(2))-len(long(int64(len(block))))]
+	for _, size := range []int64{-1, 1 << 40} {
+		corrupt := append(append(append([]byte(nil), header...), long(2)...), long(size)...)
+		if err := os.WriteFile(filepath.Join(tmpDir, "users.avro"), corrupt, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfig(tmpFile); err == nil || !strings.Contains(err.Error(), "length") {
+			t.Errorf("Expected a length error for block size %d, got %v", size, err)
+		}
+	}
+}
+
+func TestLoadConfigPrototextSource(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("users.proto"),
+		Package: proto.String("example"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("User"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("UserID"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+					{Name: proto.String("Status"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				},
+			},
+			{
+				Name: proto.String("Users"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("rows"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: proto.String(".example.User"), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()},
+				},
+			},
+		},
+	}
+	set, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{fdp}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"users.binpb": string(set),
+		"users.txtpb": `rows { UserID: "user-001" Status: 1 } rows { UserID: "user-002" Status: 2 }`,
+		"config.yaml": `
+tables:
+  Users:
+    source:
+      path: users.txtpb
+      descriptor: users.binpb
+      message: example.Users
+      field: rows
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config, err := LoadConfig(filepath.Join(tmpDir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	rows := config.Tables["Users"].Columns
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows))
+	}
+	if rows[1]["UserID"] != "user-002" || rows[1]["Status"] != int64(2) {
+		t.Errorf("Unexpected second row: %v", rows[1])
+	}
+}
+
+func TestComparisonOptionsMerge(t *testing.T) {
+	abs, rel, relOverride, zero := 0.1, 0.01, 0.5, 0.0
+	base := ComparisonOptions{FloatTolerance: &abs, RelativeTolerance: &rel}
+	got := base.Merge(&ComparisonOptions{RelativeTolerance: &relOverride})
+	if got.AbsTolerance() != 0.1 || got.RelTolerance() != 0.5 {
+		t.Errorf("Unexpected merge result: %+v", got)
+	}
+	// a table can turn a global tolerance or truncation off with 0
+	second, none := time.Second, time.Duration(0)
+	base.TimestampTruncateTo = &second
+	got = base.Merge(&ComparisonOptions{FloatTolerance: &zero, TimestampTruncateTo: &none})
+	if got.AbsTolerance() != 0 || got.RelTolerance() != 0.01 || got.TimestampPrecision() != 0 {
+		t.Errorf("Expected zero overrides to apply, got %+v", got)
+	}
+	if got := base.Merge(nil); got != base {
+		t.Errorf("Expected nil override to keep options, got %+v", got)
+	}
+
+	yes, no := true, false
+	strict := ComparisonOptions{StrictTypes: &yes, CoerceBooleans: &yes, AllowExtraColumns: &yes}
+	got = strict.Merge(&ComparisonOptions{StrictTypes: &no, CoerceBooleans: &no, AllowExtraColumns: &no})
+	if got.Strict() || got.BooleanCoercion() || got.ExtraColumns() {
+		t.Errorf("Expected a table to opt out of global boolean options, got %+v", got)
+	}
+	if got := strict.Merge(&ComparisonOptions{}); !got.Strict() || !got.BooleanCoercion() || !got.ExtraColumns() {
+		t.Errorf("Expected unset options to keep the global ones, got %+v", got)
+	}
+}
+
+func TestLintAmbiguousNulls(t *testing.T) {
+	yamlContent := `
+tables:
+  Users:
+    columns:
+      - UserID: "user-001"
+        Email: null
+        Name:
+      - UserID: "user-002"
+        Email: "bob@example.com"
+`
+	tmpFile := filepath.Join(t.TempDir(), "lint.yaml")
+	if err := os.WriteFile(tmpFile, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	if len(config.Warnings) != 2 {
+		t.Fatalf("Expected 2 warnings, got %d: %v", len(config.Warnings), config.Warnings)
+	}
+	if !strings.Contains(config.Warnings[0], "column Name has an empty value") {
+		t.Errorf("Unexpected first warning: %s", config.Warnings[0])
+	}
+	if !strings.Contains(config.Warnings[1], "row 2 (line 8) omits columns present in other rows (Name)") {
+		t.Errorf("Unexpected second warning: %s", config.Warnings[1])
+	}
+	for _, w := range config.Warnings {
+		if !strings.HasPrefix(w, tmpFile+": ") {
+			t.Errorf("Expected warnings to name the file, got %s", w)
+		}
+	}
+	if pos := config.RowPosition(config.Tables["Users"].Columns[1]); pos != tmpFile+": line 8" {
+		t.Errorf("Expected row 2 at %s: line 8, got %q", tmpFile, pos)
+	}
+	if out, err := yaml.Marshal(config.Tables["Users"].Columns); err != nil || strings.Contains(string(out), "__line") {
+		t.Errorf("Expected row lines to stay out of the encoded rows, got %s (%v)", out, err)
+	}
+
+	row := config.Tables["Users"].Columns[0]
+	if v, ok := row["Email"]; !ok || v != nil {
+		t.Errorf("Expected explicit null Email to be present and nil, got %v (present=%v)", v, ok)
+	}
+}
+
+func TestDefinitionRefs(t *testing.T) {
+	yamlContent := `
+definitions:
+  alice:
+    UserID: "user-001"
+    Name: "Alice"
+    Status: 1
+tables:
+  Users:
+    columns:
+      - !ref alice
+      - UserID: "user-002"
+        Name: "Bob"
+        Status: 2
+  Archive:
+    datasets:
+      old:
+        - !ref {name: alice, with: {Status: 9}}
+`
+	config, err := Parse([]byte(yamlContent), "")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(config.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", config.Warnings)
+	}
+
+	users := config.Tables["Users"].Columns
+	if users[0]["Name"] != "Alice" || users[0]["Status"] != 1 {
+		t.Errorf("Expected !ref alice to resolve, got %v", users[0])
+	}
+	archived := config.Tables["Archive"].Datasets["old"][0]
+	if archived["Name"] != "Alice" || archived["Status"] != 9 {
+		t.Errorf("Expected override to apply, got %v", archived)
+	}
+	if config.Definitions["alice"]["Status"] != 1 {
+		t.Error("Expected override not to modify the definition")
+	}
+
+	_, err = Parse([]byte("tables:\n  Users:\n    columns:\n      - !ref bob\n"), "")
+	if err == nil || !strings.Contains(err.Error(), `unknown definition "bob"`) {
+		t.Errorf("Expected unknown definition error, got %v", err)
+	}
+}
+
+func TestLoadConfigSeed(t *testing.T) {
+	dir := t.TempDir()
+	fixture := `
+mutations:
+  - table: Users
+    rows:
+      - UserID: "user-001"
+        Name: "Alice"
+`
+	if err := os.WriteFile(filepath.Join(dir, "users.seed.yaml"), []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	yamlContent := `
+seed:
+  fixtures: [users.seed.yaml]
+  mutations:
+    - table: Users
+      op: update
+      rows:
+        - UserID: "user-001"
+          Name: "Alicia"
+tables:
+  Users:
+    columns:
+      - UserID: "user-001"
+        Name: "Alicia"
+`
+	configPath := filepath.Join(dir, "scenario.yaml")
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	muts := config.Seed.Mutations
+	if len(muts) != 2 || muts[0].Op != "" || muts[1].Op != SeedUpdate {
+		t.Fatalf("Expected fixture mutation before inline mutation, got %+v", muts)
+	}
+	if config.Seed.Fixtures != nil {
+		t.Error("Expected fixtures to be cleared after loading")
+	}
+
+	_, err = Parse([]byte("seed:\n  mutations:\n    - table: Users\n      op: delete\n"), dir)
+	if err == nil || !strings.Contains(err.Error(), `unknown op "delete"`) {
+		t.Errorf("Expected unknown op error, got %v", err)
+	}
+}
+
+func TestEffective(t *testing.T) {
+	t.Setenv("SPALIDATE_TEST_FLAG", "off")
+	yamlContent := `
+options:
+  floatTolerance: 0.01
+definitions:
+  alice:
+    UserID: "user-001"
+tables:
+  Users:
+    options:
+      numericMode: round(2)
+    columns:
+      - !ref alice
+  Flags:
+    when: '{{ env "SPALIDATE_TEST_FLAG" }} == "on"'
+    columns:
+      - Name: "x"
+`
+	config, err := Parse([]byte(yamlContent), "")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	e, err := config.Effective()
+	if err != nil {
+		t.Fatalf("Effective failed: %v", err)
+	}
+
+	users := e.Tables["Users"]
+	if !users.Enabled || users.Options.AbsTolerance() != 0.01 || users.Options.NumericMode != "round(2)" {
+		t.Errorf("Unexpected Users table: %+v", users)
+	}
+	if users.Columns[0]["UserID"] != "user-001" {
+		t.Errorf("Expected resolved definition, got %v", users.Columns[0])
+	}
+	flags := e.Tables["Flags"]
+	if flags.Enabled || flags.Options.NumericMode != "exact" {
+		t.Errorf("Unexpected Flags table: %+v", flags)
+	}
+}
+
+func TestHash(t *testing.T) {
+	a := "tables:\n  Users:\n    columns:\n      - ID: 1\n        Name: \"Alice\"\n  Books:\n    columns: []\n"
+	b := "# same config, reformatted\ntables:\n  Books: {columns: []}\n  Users:\n    columns: [{Name: Alice, ID: 1}]\n"
+	c := "tables:\n  Users:\n    columns:\n      - ID: 2\n        Name: \"Alice\"\n"
+
+	hash := func(src string) string {
+		cfg, err := Parse([]byte(src), ".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, err := cfg.Hash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	if hash(a) != hash(b) {
+		t.Error("Expected formatting and key order not to change the hash")
+	}
+	if hash(a) == hash(c) {
+		t.Error("Expected different expectations to change the hash")
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "fixtures"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	logo := filepath.Join(dir, "fixtures", "logo.png")
+	if err := os.WriteFile(logo, []byte("PNG"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	src := `tables:
+  Assets:
+    columns:
+      - Data: !file fixtures/logo.png
+        Alt: !oneOf [!file fixtures/logo.png, null]
+`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	row := cfg.Tables["Assets"].Columns[0]
+	f, ok := row["Data"].(File)
+	if !ok {
+		t.Fatalf("Data = %T, want File", row["Data"])
+	}
+	// sha256("PNG")
+	want := File{Path: logo, Size: 3, SHA256: "796120837694d3f3f29259cfeb25091698c2a0aa87873658d840b4993ee889b3"}
+	if f != want {
+		t.Errorf("Data = %+v, want %+v", f, want)
+	}
+	if alt := row["Alt"].(OneOf).Values[0].(File); alt != f {
+		t.Errorf("Expected the !file inside !oneOf to be loaded, got %+v", alt)
+	}
+
+	h1, _ := cfg.Hash()
+	if err := os.WriteFile(logo, []byte("GIF"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h2, _ := cfg.Hash(); h1 == h2 {
+		t.Error("Expected changed file contents to change the config hash")
+	}
+
+	if err := os.Remove(logo); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("Expected a missing !file to fail the load")
+	}
+}
+
+func TestLoadTimeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"matcher", "tables:\n  Users:\n    columns:\n      - {Name: !strlen {min: 5, max: 1}}\n", "line 4: !strlen min 5 exceeds max 1"},
+		{"numericMode", "options:\n  numericMode: round(x)\ntables: {}\n", `line 1: options: invalid numericMode "round(x)"`},
+		{"table numericMode", "tables:\n  Users:\n    options: {numericMode: approx}\n", `line 3: table Users: options: invalid numericMode "approx"`},
+		{"when", "tables:\n  Users:\n    when: '{{ env \"X\" '\n", `line 3: table Users: invalid when expression`},
+		{"strategy", "tables:\n  Users:\n    strategy: fuzzy\n", `line 3: table Users: unknown strategy "fuzzy"`},
+		{"source", "tables:\n  B: {source: {path: b.parquet}}\n  A: {source: {path: a.parquet}}\n", `failed to load source for table A: unknown source format "parquet"`},
+		{"changeStream name", "tables: {}\nchangeStreams:\n  'S; DROP':\n    window: 1m\n", `line 3: change stream S; DROP: "S; DROP" is not a plain change stream name`},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadConfig(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.HasPrefix(err.Error(), path+": ") {
+				t.Errorf("Expected %s: ...%s..., got %v", path, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestParseErrorsAggregated(t *testing.T) {
+	src := `tables:
+  Users:
+    strategy: fuzzy
+    columns:
+      - {Name: !strlen {min: 5, max: 1}}
+      - {Name: !nope 1}
+  Orders:
+    count: many
+    generatedColumns: {Total: ""}
+`
+	_, err := Parse([]byte(src), ".")
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected Errors, got %v", err)
+	}
+	want := []string{
+		"line 5: !strlen min 5 exceeds max 1",
+		"line 6: unknown matcher tag !nope",
+		"line 8: cannot unmarshal !!str `many` into int64",
+		"line 9: table Orders: generated column Total has no expression",
+		`line 3: table Users: unknown strategy "fuzzy"`,
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(want), len(errs), err)
+	}
+	for i, w := range want {
+		if !strings.Contains(errs[i].Error(), w) {
+			t.Errorf("error %d = %q, want %q", i, errs[i], w)
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	v1 := `# legacy config
+options:
+  allowUnorderedRows: false
+tables:
+  Users:
+    columns:
+      - {ID: 1}
+  Orders:
+    options: {allowUnorderedRows: true, floatTolerance: 0.5}
+    columns:
+      - {ID: 1}
+  Items:
+    strategy: subset
+    columns:
+      - {ID: 1}
+`
+	out, changes, err := Migrate([]byte(v1))
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if len(changes) != 4 {
+		t.Errorf("Expected 4 changes, got %v", changes)
+	}
+	if !strings.HasPrefix(string(out), "# legacy config\nversion: 2\n") {
+		t.Errorf("Expected the version below the leading comment, got:\n%s", out)
+	}
+	cfg, err := Parse(out, ".")
+	if err != nil {
+		t.Fatalf("Expected the migrated config to load, got %v\n%s", err, out)
+	}
+	old, err := Parse([]byte(v1), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, table := range old.Tables {
+		want := table.RowStrategy(old.Options.Merge(table.Options))
+		if got := cfg.Tables[name].RowStrategy(cfg.Options.Merge(cfg.Tables[name].Options)); got != want {
+			t.Errorf("table %s: expected strategy %s after migration, got %s", name, want, got)
+		}
+	}
+	if cfg.Tables["Orders"].Options.AbsTolerance() != 0.5 {
+		t.Error("Expected other options to be kept")
+	}
+
+	if again, changes, err := Migrate(out); err != nil || len(changes) != 0 || string(again) != string(out) {
+		t.Errorf("Expected a current config to be left alone, got %v %v", changes, err)
+	}
+	for _, src := range []string{
+		"version: 2\noptions: {allowUnorderedRows: false}\ntables: {}\n",
+		"version: 2\ntables: {T: {options: {allowUnorderedRows: true}}}\n",
+		"version: 3\ntables: {}\n",
+	} {
+		if _, err := Parse([]byte(src), "."); err == nil {
+			t.Errorf("Expected %q to be rejected", src)
+		}
+	}
+}
+
+func TestTimeRelative(t *testing.T) {
+	tests := []struct {
+		yaml string
+		want bool
+	}{
+		{"tables:\n  Orders:\n    columns:\n      - ID: 1\n", false},
+		{"tables:\n  Orders:\n    where: CreatedAt >= @runStart\n    count: 1\n", true},
+		{"tables:\n  Orders:\n    columns:\n      - ID: 1\n        CreatedAt: !recent 10m\n", true},
+		{"tables:\n  Orders:\n    columns:\n      - Day: !oneOf [!today , 2024-01-01]\n", true},
+	}
+	for _, tt := range tests {
+		cfg, err := Parse([]byte(tt.yaml), ".")
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.yaml, err)
+		}
+		if got := cfg.TimeRelative(); got != tt.want {
+			t.Errorf("TimeRelative(%q) = %v, want %v", tt.yaml, got, tt.want)
+		}
+	}
+}
+
+func TestQueryParams(t *testing.T) {
+	src := `tables:
+  Orders:
+    where: TenantID = @tenant
+    params:
+      tenant: "tenant-a"
+      limit: 10
+      ratio: 0.5
+      paid: true
+      since: 2024-01-01T00:00:00Z
+      ids: [1, 2, 3]
+      mixed: [1, 2.5]
+      day: {type: DATE, value: 2024-01-01}
+      amounts: {type: numeric, value: ["1.50", "2"]}
+      empty: {type: STRING, value: []}
+`
+	cfg, err := Parse([]byte(src), ".")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	got := cfg.Tables["Orders"].QueryParams()
+	want := map[string]any{
+		"tenant":  "tenant-a",
+		"limit":   int64(10),
+		"ratio":   0.5,
+		"paid":    true,
+		"since":   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		"ids":     []int64{1, 2, 3},
+		"mixed":   []float64{1, 2.5},
+		"day":     civil.Date{Year: 2024, Month: 1, Day: 1},
+		"amounts": []big.Rat{*big.NewRat(3, 2), *big.NewRat(2, 1)},
+		"empty":   []string{},
+	}
+	for name, w := range want {
+		g := got[name]
+		if ts, ok := g.(time.Time); ok {
+			if !ts.Equal(w.(time.Time)) {
+				t.Errorf("%s = %v, want %v", name, g, w)
+			}
+			continue
+		}
+		if fmt.Sprint(g) != fmt.Sprint(w) || fmt.Sprintf("%T", g) != fmt.Sprintf("%T", w) {
+			t.Errorf("%s = %#v, want %#v", name, g, w)
+		}
+	}
+
+	for _, bad := range []string{
+		"tables:\n  T:\n    params:\n      a: 1\n",
+		"tables:\n  T:\n    where: A = @a\n    params:\n      a: null\n",
+		"tables:\n  T:\n    where: A = @a\n    params:\n      a: [1, \"x\"]\n",
+		"tables:\n  T:\n    where: A = @a\n    params:\n      a: {type: DATE, value: \"01/02/2024\"}\n",
+		"tables:\n  T:\n    where: A = @a\n    params:\n      a: {type: UUID, value: \"x\"}\n",
+	} {
+		if _, err := Parse([]byte(bad), "."); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	keys := make(map[string]KeyDoc)
+	for _, k := range Keys() {
+		keys[k.Key] = k
+	}
+	tests := []KeyDoc{
+		{Key: "options.numericMode", Type: "string", Default: "exact"},
+		{Key: "options.timestampTruncateTo", Type: "duration"},
+		{Key: "tables.<name>.options.allowUnorderedRows", Type: "bool", Default: "true"},
+		{Key: "tables.<name>.columns", Type: "rows"},
+		{Key: "tables.<name>.rowsByKey", Type: "map of key to row"},
+		{Key: "tables.<name>.params", Type: "map of params"},
+		{Key: "seed.mutations[].op", Type: "string", Default: "insert"},
+	}
+	for _, want := range tests {
+		if got, ok := keys[want.Key]; !ok || got != want {
+			t.Errorf("Keys()[%s] = %+v, want %+v", want.Key, got, want)
+		}
+	}
+	if _, ok := keys["warnings"]; ok {
+		t.Error("Expected fields without a yaml key to be omitted")
+	}
+}
+
+func TestTableDefaults(t *testing.T) {
+	cfg, err := Parse([]byte(`defaults:
+  ignoreColumns: [CreatedAt, UpdatedAt]
+tables:
+  Users: {}
+  Orders:
+    ignoreColumns: [ShippedAt]
+  Settings:
+    ignoreColumns: []
+`), ".")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	for table, want := range map[string][]string{
+		"Users":    {"CreatedAt", "UpdatedAt"},
+		"Orders":   {"ShippedAt"},
+		"Settings": {},
+	} {
+		if got := cfg.Tables[table].IgnoreColumns; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: ignoreColumns = %v, want %v", table, got, want)
+		}
+	}
+}
+
+func TestRowsByKey(t *testing.T) {
+	cfg, err := Parse([]byte(`definitions:
+  admin: {Role: admin}
+tables:
+  Users:
+    rowsByKey:
+      user-001: {Name: Alice}
+      user-002: !ref admin
+      42:
+`), ".")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	users := cfg.Tables["Users"]
+	if users.Strategy != StrategyPrimaryKey || users.RowsByKey != nil || len(users.Columns) != 3 {
+		t.Fatalf("Expected rowsByKey to expand into primaryKey columns, got %+v", users)
+	}
+
+	rows, err := users.Columns.WithKeys([]string{"UserID"})
+	if err != nil {
+		t.Fatalf("WithKeys: %v", err)
+	}
+	want := []map[string]any{
+		{"UserID": "user-001", "Name": "Alice"},
+		{"UserID": "user-002", "Role": "admin"},
+		{"UserID": 42},
+	}
+	for i, w := range want {
+		got := maps.Clone(rows[i])
+		delete(got, lineKey)
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("row %d = %v, want %v", i+1, got, w)
+		}
+	}
+	if _, ok := users.Columns[0][keyKey]; !ok {
+		t.Error("Expected WithKeys to leave the parsed rows unchanged")
+	}
+
+	if _, err := users.Columns.WithKeys([]string{"TenantID", "UserID"}); err == nil {
+		t.Error("Expected a composite primary key to be rejected")
+	}
+	if _, err := users.Columns.WithKeys([]string{"Name"}); err == nil || !strings.Contains(err.Error(), "line 6") {
+		t.Errorf("Expected a row repeating the key column to be rejected at its line, got %v", err)
+	}
+
+	for _, bad := range []string{
+		"tables:\n  T:\n    rowsByKey: {a: {X: 1}, \"a\": {X: 2}}\n",
+		"tables:\n  T:\n    strategy: strict\n    rowsByKey: {a: {X: 1}}\n",
+		"tables:\n  T:\n    columns: [{ID: a}]\n    rowsByKey: {b: {X: 1}}\n",
+		"tables:\n  T:\n    rowsByKey: [{ID: a}]\n",
+	} {
+		if _, err := Parse([]byte(bad), "."); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestParseUntrusted(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"plain", "tables:\n  Users:\n    columns:\n      - ID: 1\n", ""},
+		{"source", "tables:\n  Users:\n    source: {path: /etc/passwd, format: csv}\n", "line 3: table Users: source is not allowed"},
+		{"file", "tables:\n  Users:\n    columns:\n      - Avatar: !file /etc/passwd\n", "table Users: !file is not allowed"},
+		{"env", "tables:\n  Users:\n    when: '{{ env \"SECRET\" }}'\n    count: 1\n", "table Users: env in when is not allowed"},
+		{"where", "tables:\n  Users:\n    where: \"1=1\"\n    count: 1\n", "table Users: where is not allowed"},
+		{"seed", "seed:\n  fixtures: [/etc/passwd]\ntables: {}\n", "seed is not allowed"},
+		{"before", "before: [\"DELETE FROM Users WHERE true\"]\ntables: {}\n", "before is not allowed"},
+		{"table name", "tables:\n  \"Users; DROP TABLE Users\":\n    count: 1\n", "is not a plain table name"},
+		{"column name", "tables:\n  Users:\n    monotonic: {column: \"ID) --\"}\n", "monotonic: \"ID) --\" is not a plain column name"},
+		{"change stream name", "tables: {}\nchangeStreams:\n  \"S(x) --\":\n    window: 1m\n    records: [{table: Users}]\n", "change stream \"S(x) --\" is not a plain change stream name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseUntrusted([]byte(tt.yaml))
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	t.Setenv("SPALIDATE_TEST_SECRET", "hunter2")
+	_, err := EvalWhen(`{{ env "SPALIDATE_TEST_SECRET" }}`)
+	if err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("Expected an error without the rendered value, got %v", err)
+	}
+}
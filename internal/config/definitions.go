@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Definitions are named expected rows declared once under the top-level `definitions:`
+// block and referenced from table rows with `!ref`. Unlike YAML anchors they are resolved
+// after the whole config is loaded, so any table can use them.
+type Definitions map[string]map[string]any
+
+// UnmarshalYAML decodes each definition as an expected row, resolving matcher tags.
+func (d *Definitions) UnmarshalYAML(node *yaml.Node) error {
+	var raw map[string]yaml.Node
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	defs := make(Definitions, len(raw))
+	for name, n := range raw {
+		row, err := decodeRow(&n)
+		if err != nil {
+			return fmt.Errorf("definition %s: %w", name, err)
+		}
+		defs[name] = row
+	}
+	*d = defs
+	return nil
+}
+
+// Ref references a definition from a table row: `!ref alice`, or
+// `!ref {name: alice, with: {Status: 2}}` to override some of its columns.
+type Ref struct {
+	Name string         `yaml:"name"`
+	With map[string]any `yaml:"with,omitempty"`
+}
+
+func (r Ref) MarshalYAML() (any, error) {
+	if len(r.With) == 0 {
+		return taggedNode("!ref", r.Name)
+	}
+	type plain Ref
+	return taggedNode("!ref", plain(r))
+}
+
+// refKey holds the Ref of an unresolved reference entry in Rows.
+const refKey = "!ref"
+
+func decodeRef(n *yaml.Node) (Ref, error) {
+	var ref Ref
+	switch n.Kind {
+	case yaml.ScalarNode:
+		ref.Name = n.Value
+	case yaml.MappingNode:
+		name := mappingValue(n, "name")
+		if name == nil || name.Kind != yaml.ScalarNode {
+			return ref, fmt.Errorf("line %d: !ref expects a definition name", n.Line)
+		}
+		ref.Name = name.Value
+		if with := mappingValue(n, "with"); with != nil {
+			row, err := decodeRow(with)
+			if err != nil {
+				return ref, fmt.Errorf("line %d: invalid !ref overrides: %w", n.Line, err)
+			}
+			ref.With = row
+		}
+	default:
+		return ref, fmt.Errorf("line %d: !ref expects a definition name", n.Line)
+	}
+	if ref.Name == "" {
+		return ref, fmt.Errorf("line %d: !ref expects a definition name", n.Line)
+	}
+	return ref, nil
+}
+
+// resolveRefs replaces `!ref` entries in table rows and datasets with copies of their definitions.
+func (c *Config) resolveRefs() error {
+	for tableName, table := range c.Tables {
+		if err := c.resolveRows(table.Columns); err != nil {
+			return fmt.Errorf("table %s: %w", tableName, err)
+		}
+		for name, rows := range table.Datasets {
+			if err := c.resolveRows(rows); err != nil {
+				return fmt.Errorf("table %s dataset %s: %w", tableName, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Config) resolveRows(rows Rows) error {
+	for i, row := range rows {
+		ref, ok := row[refKey].(Ref)
+		if !ok {
+			continue
+		}
+		def, ok := c.Definitions[ref.Name]
+		if !ok {
+			return atLine(LineOf(row), fmt.Errorf("row %d references unknown definition %q", i+1, ref.Name))
+		}
+		resolved := maps.Clone(def)
+		maps.Copy(resolved, ref.With)
+		// errors point at the reference, not the definition
+		resolved[lineKey] = row[lineKey]
+		if key, ok := row[keyKey]; ok {
+			resolved[keyKey] = key
+		}
+		rows[i] = resolved
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Distribution asserts how the rows of a table spread over the values of a column
+// (`distribution: {column: Status, expect: {1: 2, 2: 1}}`), checked with a GROUP BY
+// instead of listing rows. Every value present must be listed with its exact count.
+type Distribution struct {
+	Column string   `yaml:"column"`
+	Expect []Bucket `yaml:"expect"`
+}
+
+// Bucket is the expected number of rows holding Value; a nil Value stands for NULL.
+type Bucket struct {
+	Value any
+	Count int64
+}
+
+// UnmarshalYAML decodes `expect` as a mapping from value to count, keeping the type of
+// each key (`1` is a number, `"1"` a string, `null` is NULL).
+func (d *Distribution) UnmarshalYAML(n *yaml.Node) error {
+	var raw struct {
+		Column string    `yaml:"column"`
+		Expect yaml.Node `yaml:"expect"`
+	}
+	if err := n.Decode(&raw); err != nil {
+		return err
+	}
+	if raw.Column == "" {
+		return fmt.Errorf("line %d: distribution needs a column", n.Line)
+	}
+	if raw.Expect.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: distribution expect must map values to counts", n.Line)
+	}
+	d.Column = raw.Column
+	d.Expect = nil
+	for i := 0; i+1 < len(raw.Expect.Content); i += 2 {
+		k, v := raw.Expect.Content[i], raw.Expect.Content[i+1]
+		var b Bucket
+		if err := k.Decode(&b.Value); err != nil {
+			return fmt.Errorf("line %d: invalid distribution value: %w", k.Line, err)
+		}
+		if err := v.Decode(&b.Count); err != nil || b.Count < 0 {
+			return fmt.Errorf("line %d: distribution count must be a non-negative integer", v.Line)
+		}
+		d.Expect = append(d.Expect, b)
+	}
+	return nil
+}
+
+func (d Distribution) MarshalYAML() (any, error) {
+	expect := &yaml.Node{Kind: yaml.MappingNode}
+	for _, b := range d.Expect {
+		var k, v yaml.Node
+		if err := k.Encode(b.Value); err != nil {
+			return nil, err
+		}
+		if err := v.Encode(b.Count); err != nil {
+			return nil, err
+		}
+		expect.Content = append(expect.Content, &k, &v)
+	}
+	return map[string]any{"column": d.Column, "expect": expect}, nil
+}
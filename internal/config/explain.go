@@ -0,0 +1,72 @@
+package config
+
+// Effective is the fully resolved form of a configuration: definitions and sources are
+// expanded, the dataset is selected, `when` conditions are evaluated and comparison
+// options are merged per table with defaults filled in.
+type Effective struct {
+	Before        []string                  `yaml:"before,omitempty"`
+	After         []string                  `yaml:"after,omitempty"`
+	Seed          *Seed                     `yaml:"seed,omitempty"`
+	Tables        map[string]EffectiveTable `yaml:"tables"`
+	ChangeStreams map[string]ChangeStream   `yaml:"changeStreams,omitempty"`
+	// DatabaseOptions are the asserted database options.
+	DatabaseOptions map[string]string `yaml:"databaseOptions,omitempty"`
+	Warnings        []string          `yaml:"warnings,omitempty"`
+}
+
+// EffectiveTable is a table as it will be validated.
+type EffectiveTable struct {
+	// Enabled is the outcome of the table's when condition.
+	Enabled bool             `yaml:"enabled"`
+	When    string           `yaml:"when,omitempty"`
+	Where   string           `yaml:"where,omitempty"`
+	Params  map[string]Param `yaml:"params,omitempty"`
+	Count   *int64           `yaml:"count,omitempty"`
+	// CountTolerance is kept as written, e.g. "1%".
+	CountTolerance string           `yaml:"countTolerance,omitempty"`
+	Distribution   *Distribution    `yaml:"distribution,omitempty"`
+	ColumnBounds   map[string]Bound `yaml:"columnBounds,omitempty"`
+	Monotonic      *Monotonic       `yaml:"monotonic,omitempty"`
+	// GeneratedColumns and CheckConstraints are the schema assertions.
+	GeneratedColumns map[string]string `yaml:"generatedColumns,omitempty"`
+	CheckConstraints map[string]string `yaml:"checkConstraints,omitempty"`
+	Options          ComparisonOptions `yaml:"options"`
+	// Strategy is the row strategy in effect, including the default.
+	Strategy            string         `yaml:"strategy"`
+	AllowMissingColumns map[string]any `yaml:"allowMissingColumns,omitempty"`
+	IgnoreColumns       []string       `yaml:"ignoreColumns,omitempty"`
+	Columns             Rows           `yaml:"columns,omitempty"`
+}
+
+// Effective resolves the configuration for display. Call it after SelectDataset.
+func (c *Config) Effective() (*Effective, error) {
+	e := &Effective{
+		Before:          c.Before,
+		After:           c.After,
+		Seed:            c.Seed,
+		Tables:          make(map[string]EffectiveTable, len(c.Tables)),
+		ChangeStreams:   c.ChangeStreams,
+		DatabaseOptions: c.DatabaseOptions,
+		Warnings:        c.Warnings,
+	}
+	for name, t := range c.Tables {
+		enabled, err := t.Enabled()
+		if err != nil {
+			return nil, err
+		}
+		opts := c.Options.Merge(t.Options)
+		if opts.NumericMode == "" {
+			opts.NumericMode = "exact"
+		}
+		if opts.AllowUnorderedRows == nil {
+			unordered := true
+			opts.AllowUnorderedRows = &unordered
+		}
+		e.Tables[name] = EffectiveTable{Enabled: enabled, When: t.When, Where: t.Where, Params: t.Params,
+			Count: t.Count, CountTolerance: t.CountTolerance, Distribution: t.Distribution,
+			ColumnBounds: t.ColumnBounds, Monotonic: t.Monotonic, GeneratedColumns: t.GeneratedColumns, CheckConstraints: t.CheckConstraints,
+			Options: opts, Strategy: t.RowStrategy(opts),
+			AllowMissingColumns: t.AllowMissingColumns, IgnoreColumns: t.IgnoreColumns, Columns: t.Columns}
+	}
+	return e, nil
+}
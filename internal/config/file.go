@@ -0,0 +1,71 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+// loadFiles resolves the paths of `!file` expected values against baseDir and digests the
+// files, so a missing fixture fails the load and changed contents change Hash.
+func (c *Config) loadFiles(baseDir string) error {
+	for tableName, table := range c.Tables {
+		if err := loadRowFiles(table.Columns, baseDir); err != nil {
+			return fmt.Errorf("table %s: %w", tableName, err)
+		}
+		for name, rows := range table.Datasets {
+			if err := loadRowFiles(rows, baseDir); err != nil {
+				return fmt.Errorf("table %s dataset %s: %w", tableName, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func loadRowFiles(rows Rows, baseDir string) error {
+	for i, row := range rows {
+		for col, v := range row {
+			resolved, err := loadValueFiles(v, baseDir)
+			if err != nil {
+				return fmt.Errorf("row %d column %s: %w", i+1, col, err)
+			}
+			row[col] = resolved
+		}
+	}
+	return nil
+}
+
+// loadValueFiles digests a File value, including the candidates of a OneOf.
+func loadValueFiles(v any, baseDir string) (any, error) {
+	switch m := v.(type) {
+	case File:
+		return digestFile(resolvePath(baseDir, m.Path))
+	case OneOf:
+		values := make([]any, len(m.Values))
+		for i, candidate := range m.Values {
+			resolved, err := loadValueFiles(candidate, baseDir)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = resolved
+		}
+		return OneOf{Values: values}, nil
+	}
+	return v, nil
+}
+
+func digestFile(path string) (File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return File{}, fmt.Errorf("reading !file: %w", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return File{}, fmt.Errorf("reading !file %s: %w", path, err)
+	}
+	return File{Path: path, Size: n, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
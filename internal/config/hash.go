@@ -0,0 +1,21 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Hash returns a hex SHA-256 of the resolved configuration. It is stable across runs:
+// mapping keys are encoded in sorted order, and formatting or comments in the source
+// file do not affect it.
+func (c *Config) Hash() (string, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("encoding config: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
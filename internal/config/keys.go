@@ -0,0 +1,102 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// KeyDoc describes a configuration key.
+type KeyDoc struct {
+	// Key is the dotted path of the key; `<name>` stands for a map key and `[]` for a list item.
+	Key     string
+	Type    string
+	Default string
+}
+
+// Keys lists every configuration key, generated from the yaml and default struct tags
+// of Config.
+func Keys() []KeyDoc {
+	var docs []KeyDoc
+	describeStruct(&docs, "", reflect.TypeOf(Config{}))
+	return docs
+}
+
+var (
+	rowsType        = reflect.TypeOf(Rows{})
+	keyedRowsType   = reflect.TypeOf(KeyedRows{})
+	definitionsType = reflect.TypeOf(Definitions{})
+	paramType       = reflect.TypeOf(Param{})
+	durationType    = reflect.TypeOf(time.Duration(0))
+	bucketsType     = reflect.TypeOf([]Bucket{})
+	boundType       = reflect.TypeOf(Bound{})
+)
+
+func describeStruct(docs *[]KeyDoc, prefix string, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := prefix + name
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		switch {
+		case ft.Kind() == reflect.Struct && ft != paramType:
+			describeStruct(docs, key+".", ft)
+		case ft.Kind() == reflect.Map && ft.Elem().Kind() == reflect.Struct && ft.Elem() != paramType && ft.Elem() != boundType:
+			describeStruct(docs, key+".<name>.", ft.Elem())
+		case ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Struct && ft != bucketsType:
+			describeStruct(docs, key+"[].", ft.Elem())
+		default:
+			*docs = append(*docs, KeyDoc{Key: key, Type: typeName(ft), Default: f.Tag.Get("default")})
+		}
+	}
+}
+
+// typeName names a field type in config terms.
+func typeName(t reflect.Type) string {
+	switch t {
+	case rowsType:
+		return "rows"
+	case keyedRowsType:
+		return "map of key to row"
+	case definitionsType:
+		return "map of rows"
+	case durationType:
+		return "duration"
+	case bucketsType:
+		return "map of value to count"
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeName(t.Elem())
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int64:
+		return "integer"
+	case reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		return "list of " + typeName(t.Elem())
+	case reflect.Map:
+		if t.Elem() == paramType {
+			return "map of params"
+		}
+		if t.Elem() == rowsType {
+			return "map of rows"
+		}
+		if t.Elem() == boundType {
+			return "map of {min, max}"
+		}
+		return "map"
+	case reflect.Interface:
+		return "any"
+	}
+	return t.String()
+}
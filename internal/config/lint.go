@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lint inspects the raw YAML document for expectations whose meaning is ambiguous:
+//
+//   - a column written with an empty value (`Name:`), which YAML reads as NULL but is
+//     usually an unfinished edit; NULL expectations should be spelled `null`
+//   - rows of one table that list different column sets; columns omitted from a row are
+//     not treated as NULL, so the row can never match in strict mode
+func lint(root *yaml.Node) []string {
+	doc := root
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	tables := mappingValue(doc, "tables")
+	if tables == nil || tables.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var warnings []string
+	for i := 0; i+1 < len(tables.Content); i += 2 {
+		tableName := tables.Content[i].Value
+		table := tables.Content[i+1]
+		if rows := mappingValue(table, "columns"); rows != nil {
+			warnings = append(warnings, lintRows(tableName, rows)...)
+		}
+		if datasets := mappingValue(table, "datasets"); datasets != nil && datasets.Kind == yaml.MappingNode {
+			for j := 0; j+1 < len(datasets.Content); j += 2 {
+				name := fmt.Sprintf("%s (dataset %s)", tableName, datasets.Content[j].Value)
+				warnings = append(warnings, lintRows(name, datasets.Content[j+1])...)
+			}
+		}
+	}
+	return warnings
+}
+
+func lintRows(tableName string, rows *yaml.Node) []string {
+	if rows.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var warnings []string
+	all := make(map[string]bool)
+	rowCols := make(map[int]map[string]bool)
+	for ri, row := range rows.Content {
+		if row.Kind != yaml.MappingNode || row.Tag == "!anyRow" || row.Tag == "!ref" {
+			continue
+		}
+		cols := make(map[string]bool)
+		for i := 0; i+1 < len(row.Content); i += 2 {
+			col, val := row.Content[i].Value, row.Content[i+1]
+			if IsMetaColumn(col) {
+				continue
+			}
+			cols[col] = true
+			all[col] = true
+			if val.Kind == yaml.ScalarNode && val.Tag == "!!null" && val.Value == "" {
+				warnings = append(warnings, fmt.Sprintf(
+					"table %s row %d (line %d): column %s has an empty value; write null explicitly if NULL is intended",
+					tableName, ri+1, val.Line, col))
+			}
+		}
+		rowCols[ri] = cols
+	}
+	for ri := range rows.Content {
+		cols, ok := rowCols[ri]
+		if !ok {
+			continue
+		}
+		var missing []string
+		for col := range all {
+			if !cols[col] {
+				missing = append(missing, col)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			warnings = append(warnings, fmt.Sprintf(
+				"table %s row %d (line %d) omits columns present in other rows (%s); omitted columns are not NULL, write null explicitly",
+				tableName, ri+1, rows.Content[ri].Line, strings.Join(missing, ", ")))
+		}
+	}
+	return warnings
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// keyLine returns the line of the key at path in a YAML document, or 0 when it is absent.
+func keyLine(root *yaml.Node, path ...string) int {
+	n := root
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	line := 0
+	for _, key := range path {
+		if n == nil || n.Kind != yaml.MappingNode {
+			return 0
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == key {
+				line, next = n.Content[i].Line, n.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return 0
+		}
+		n = next
+	}
+	return line
+}
+
+// atLine prefixes err with a config line, like the errors of the YAML decoders, when the
+// line is known.
+func atLine(line int, err error) error {
+	if line == 0 {
+		return err
+	}
+	return fmt.Errorf("line %d: %w", line, err)
+}
@@ -0,0 +1,705 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"gopkg.in/yaml.v3"
+)
+
+// Matchers are expected values written with a YAML tag (e.g. `!oneOf [1, 2]`) that describe
+// a condition on the actual value instead of an exact value. They are decoded here and
+// evaluated by the validator.
+
+// MatcherDoc describes a YAML tag usable in expected rows.
+type MatcherDoc struct {
+	Syntax string
+	Doc    string
+}
+
+// Matchers lists the value matchers, row tags and reserved row keys. Keep it in sync with
+// decodeValue and Rows.UnmarshalYAML.
+var Matchers = []MatcherDoc{
+	{"!any", "value is not NULL; its content is not checked"},
+	{"!oneOf [a, b, ...]", "value equals any listed value"},
+	{"!strlen n | {min, max}", "STRING length in characters or BYTES length in bytes"},
+	{"!re pattern", "STRING matches the regular expression (RE2)"},
+	{"!range {gt, gte, lt, lte}", "value within the bounds (numbers, timestamps, dates, strings)"},
+	{"!between [a, b]", "value within the inclusive range"},
+	{"!nan | !inf | !-inf", "FLOAT64 NaN or infinity"},
+	{"!dateBetween {from, to}", "DATE within the inclusive range"},
+	{"!today [time zone]", "DATE equals the current date (default America/Los_Angeles)"},
+	{"!recent duration", "TIMESTAMP within the duration of validation time"},
+	{"!file path", "BYTES or STRING equals the file contents"},
+	{"!sha256 hex", "SHA-256 of the BYTES, STRING or JSON value"},
+	{"!anyRow {count: n}", "row entry: n rows of any content"},
+	{"!ref name | {name, with}", "row entry: a row from definitions"},
+	{"__ignore: true", "row key: the row is not checked"},
+	{"__expectedFailure: marker", "row key: the row is known to fail"},
+	{"__message: text", "row key: text prepended to the row's errors"},
+}
+
+// Any matches any non-NULL value (`!any`), e.g. generated IDs and commit timestamps that
+// differ between runs.
+type Any struct{}
+
+func (Any) String() string {
+	return "any"
+}
+
+func (Any) MarshalYAML() (any, error) {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!any"}, nil
+}
+
+// OneOf matches when the actual value equals any of Values.
+type OneOf struct {
+	Values []any
+}
+
+func (m OneOf) String() string {
+	parts := make([]string, len(m.Values))
+	for i, v := range m.Values {
+		parts[i] = fmt.Sprintf("%v", v)
+	}
+	return "oneOf[" + strings.Join(parts, ", ") + "]"
+}
+
+func (m OneOf) MarshalYAML() (any, error) {
+	return taggedNode("!oneOf", m.Values)
+}
+
+// StrLen matches STRING values by character count and BYTES values by byte count.
+// A nil bound is unchecked.
+type StrLen struct {
+	Min *int `yaml:"min,omitempty"`
+	Max *int `yaml:"max,omitempty"`
+}
+
+func (m StrLen) String() string {
+	var parts []string
+	if m.Min != nil {
+		parts = append(parts, fmt.Sprintf("min=%d", *m.Min))
+	}
+	if m.Max != nil {
+		parts = append(parts, fmt.Sprintf("max=%d", *m.Max))
+	}
+	return "strlen{" + strings.Join(parts, ", ") + "}"
+}
+
+func (m StrLen) MarshalYAML() (any, error) {
+	type plain StrLen
+	return taggedNode("!strlen", plain(m))
+}
+
+// Regex matches STRING values against a regular expression (`!re "^user-\d+$"`), compiled
+// once when the config is loaded. Like regexp.MatchString it matches anywhere in the value
+// unless the pattern is anchored.
+type Regex struct {
+	re *regexp.Regexp
+}
+
+// MatchString reports whether s matches the pattern.
+func (m Regex) MatchString(s string) bool {
+	return m.re.MatchString(s)
+}
+
+func (m Regex) String() string {
+	return "re(" + m.re.String() + ")"
+}
+
+func (m Regex) MarshalYAML() (any, error) {
+	return taggedNode("!re", m.re.String())
+}
+
+// Range matches values within bounds (`!range {gt: 0, lte: 100}`); `!between [a, b]` is
+// the inclusive range. A nil bound is unchecked. Bounds are compared like columnBounds.
+type Range struct {
+	Gt  any `yaml:"gt,omitempty"`
+	Gte any `yaml:"gte,omitempty"`
+	Lt  any `yaml:"lt,omitempty"`
+	Lte any `yaml:"lte,omitempty"`
+}
+
+func (m Range) String() string {
+	var parts []string
+	for _, b := range []struct {
+		name  string
+		value any
+	}{{"gt", m.Gt}, {"gte", m.Gte}, {"lt", m.Lt}, {"lte", m.Lte}} {
+		if b.value != nil {
+			parts = append(parts, fmt.Sprintf("%s=%v", b.name, b.value))
+		}
+	}
+	return "range{" + strings.Join(parts, ", ") + "}"
+}
+
+func (m Range) MarshalYAML() (any, error) {
+	type plain Range
+	return taggedNode("!range", plain(m))
+}
+
+// decodeRange decodes `!range {gt, gte, lt, lte}` and `!between [a, b]`.
+func decodeRange(n *yaml.Node) (Range, error) {
+	var m Range
+	if n.Tag == "!between" {
+		if n.Kind != yaml.SequenceNode || len(n.Content) != 2 {
+			return m, fmt.Errorf("line %d: !between expects [a, b]", n.Line)
+		}
+		if err := n.Content[0].Decode(&m.Gte); err != nil {
+			return m, err
+		}
+		if err := n.Content[1].Decode(&m.Lte); err != nil {
+			return m, err
+		}
+		if m.Gte == nil || m.Lte == nil {
+			return m, fmt.Errorf("line %d: !between bounds cannot be null", n.Line)
+		}
+		return m, nil
+	}
+	type plain Range
+	if n.Kind != yaml.MappingNode {
+		return m, fmt.Errorf("line %d: !range expects {gt, gte, lt, lte}", n.Line)
+	}
+	for i := 0; i < len(n.Content); i += 2 {
+		switch k := n.Content[i]; k.Value {
+		case "gt", "gte", "lt", "lte":
+		default:
+			return m, fmt.Errorf("line %d: unknown !range bound %q (want gt, gte, lt or lte)", k.Line, k.Value)
+		}
+	}
+	if err := decodeUntagged(n, (*plain)(&m)); err != nil {
+		return m, fmt.Errorf("line %d: invalid !range: %w", n.Line, err)
+	}
+	switch {
+	case m.Gt == nil && m.Gte == nil && m.Lt == nil && m.Lte == nil:
+		return m, fmt.Errorf("line %d: !range needs gt, gte, lt or lte", n.Line)
+	case m.Gt != nil && m.Gte != nil:
+		return m, fmt.Errorf("line %d: !range cannot have both gt and gte", n.Line)
+	case m.Lt != nil && m.Lte != nil:
+		return m, fmt.Errorf("line %d: !range cannot have both lt and lte", n.Line)
+	}
+	return m, nil
+}
+
+// FloatSpecial matches the FLOAT64 sentinels NaN (`!nan`), +Inf (`!inf`) and -Inf (`!-inf`).
+// Unlike a plain `.nan`, which never equals anything, `!nan` matches a stored NaN.
+type FloatSpecial string
+
+// Float sentinels matched by FloatSpecial.
+const (
+	NaN    FloatSpecial = "nan"
+	PosInf FloatSpecial = "inf"
+	NegInf FloatSpecial = "-inf"
+)
+
+func (m FloatSpecial) String() string {
+	return string(m)
+}
+
+func (m FloatSpecial) MarshalYAML() (any, error) {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!" + string(m)}, nil
+}
+
+// DateBetween matches DATE values within the inclusive range [From, To]. A nil bound is unchecked.
+type DateBetween struct {
+	From *civil.Date
+	To   *civil.Date
+}
+
+func (m DateBetween) String() string {
+	var parts []string
+	if m.From != nil {
+		parts = append(parts, "from="+m.From.String())
+	}
+	if m.To != nil {
+		parts = append(parts, "to="+m.To.String())
+	}
+	return "dateBetween{" + strings.Join(parts, ", ") + "}"
+}
+
+func (m DateBetween) MarshalYAML() (any, error) {
+	bounds := make(map[string]string)
+	if m.From != nil {
+		bounds["from"] = m.From.String()
+	}
+	if m.To != nil {
+		bounds["to"] = m.To.String()
+	}
+	return taggedNode("!dateBetween", bounds)
+}
+
+// DefaultTodayLocation is the time zone of `!today` without an argument. It is Spanner's
+// default time zone, so `!today` equals CURRENT_DATE() evaluated at validation time.
+const DefaultTodayLocation = "America/Los_Angeles"
+
+// Today matches the current date in Location (`!today` or `!today UTC`).
+type Today struct {
+	Location *time.Location
+}
+
+func (m Today) String() string {
+	return "today(" + m.Location.String() + ")"
+}
+
+func (m Today) MarshalYAML() (any, error) {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!today", Value: m.Location.String()}, nil
+}
+
+// Recent matches TIMESTAMP values within Within of the validation time, either way
+// (`!recent 10m`), e.g. commit timestamps of rows written just before validation.
+type Recent struct {
+	Within time.Duration
+}
+
+func (m Recent) String() string {
+	return "recent(" + m.Within.String() + ")"
+}
+
+func (m Recent) MarshalYAML() (any, error) {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!recent", Value: m.Within.String()}, nil
+}
+
+// decodeDateBetween decodes `!dateBetween {from: 2024-01-01, to: 2024-12-31}`.
+func decodeDateBetween(n *yaml.Node) (DateBetween, error) {
+	var m DateBetween
+	var bounds struct {
+		From string `yaml:"from"`
+		To   string `yaml:"to"`
+	}
+	if n.Kind != yaml.MappingNode {
+		return m, fmt.Errorf("line %d: !dateBetween expects {from, to}", n.Line)
+	}
+	if err := decodeUntagged(n, &bounds); err != nil {
+		return m, fmt.Errorf("line %d: invalid !dateBetween: %w", n.Line, err)
+	}
+	for _, b := range []struct {
+		value string
+		dst   **civil.Date
+	}{{bounds.From, &m.From}, {bounds.To, &m.To}} {
+		if b.value == "" {
+			continue
+		}
+		d, err := civil.ParseDate(b.value)
+		if err != nil {
+			return m, fmt.Errorf("line %d: invalid !dateBetween date %q (want YYYY-MM-DD)", n.Line, b.value)
+		}
+		*b.dst = &d
+	}
+	if m.From == nil && m.To == nil {
+		return m, fmt.Errorf("line %d: !dateBetween needs from or to", n.Line)
+	}
+	if m.From != nil && m.To != nil && m.To.Before(*m.From) {
+		return m, fmt.Errorf("line %d: !dateBetween from %s is after to %s", n.Line, m.From, m.To)
+	}
+	return m, nil
+}
+
+// File matches BYTES or STRING values against the contents of a file
+// (`!file fixtures/logo.png`). The file is digested when the config is loaded and the
+// actual value is compared by SHA-256, so large fixtures are never held in memory.
+type File struct {
+	// Path is resolved against the config file's directory when the config is loaded.
+	Path string `yaml:"path"`
+	// Size and SHA256 describe the file contents.
+	Size   int64  `yaml:"size"`
+	SHA256 string `yaml:"sha256"`
+}
+
+func (m File) String() string {
+	return "file(" + m.Path + ")"
+}
+
+func (m File) MarshalYAML() (any, error) {
+	type plain File
+	return taggedNode("!file", plain(m))
+}
+
+// SHA256 matches BYTES, STRING or JSON values by the hex SHA-256 digest of their content
+// (`!sha256 "9f86d0..."`). JSON values are digested in compact form with sorted keys.
+type SHA256 string
+
+func (m SHA256) String() string {
+	return "sha256(" + string(m) + ")"
+}
+
+func (m SHA256) MarshalYAML() (any, error) {
+	return taggedNode("!sha256", string(m))
+}
+
+// taggedNode encodes v and attaches a matcher tag so configs round-trip through yaml.Marshal.
+func taggedNode(tag string, v any) (*yaml.Node, error) {
+	var n yaml.Node
+	if err := n.Encode(v); err != nil {
+		return nil, err
+	}
+	n.Tag = tag
+	return &n, nil
+}
+
+// decodeUntagged decodes the content of a matcher node, ignoring its tag.
+func decodeUntagged(n *yaml.Node, out any) error {
+	c := *n
+	c.Tag = ""
+	return c.Decode(out)
+}
+
+// decodeValue decodes an expected value node, turning matcher tags into matcher values.
+func decodeValue(n *yaml.Node) (any, error) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	switch n.Tag {
+	case "!any":
+		if n.Kind != yaml.ScalarNode || n.Value != "" {
+			return nil, fmt.Errorf("line %d: !any takes no value", n.Line)
+		}
+		return Any{}, nil
+	case "!oneOf":
+		if n.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("line %d: !oneOf expects a sequence", n.Line)
+		}
+		var m OneOf
+		for _, item := range n.Content {
+			v, err := decodeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			m.Values = append(m.Values, v)
+		}
+		return m, nil
+	case "!strlen":
+		var m StrLen
+		switch n.Kind {
+		case yaml.ScalarNode:
+			var exact int
+			if err := decodeUntagged(n, &exact); err != nil {
+				return nil, fmt.Errorf("line %d: !strlen expects a length or {min, max}: %w", n.Line, err)
+			}
+			m.Min, m.Max = &exact, &exact
+		case yaml.MappingNode:
+			type plain StrLen
+			if err := decodeUntagged(n, (*plain)(&m)); err != nil {
+				return nil, fmt.Errorf("line %d: invalid !strlen: %w", n.Line, err)
+			}
+		default:
+			return nil, fmt.Errorf("line %d: !strlen expects a length or {min, max}", n.Line)
+		}
+		if m.Min != nil && m.Max != nil && *m.Min > *m.Max {
+			return nil, fmt.Errorf("line %d: !strlen min %d exceeds max %d", n.Line, *m.Min, *m.Max)
+		}
+		return m, nil
+	case "!re":
+		if n.Kind != yaml.ScalarNode || n.Value == "" {
+			return nil, fmt.Errorf("line %d: !re expects a pattern", n.Line)
+		}
+		re, err := regexp.Compile(n.Value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid !re pattern: %w", n.Line, err)
+		}
+		return Regex{re: re}, nil
+	case "!range", "!between":
+		return decodeRange(n)
+	case "!nan", "!inf", "!-inf":
+		if n.Kind != yaml.ScalarNode || n.Value != "" {
+			return nil, fmt.Errorf("line %d: %s takes no value", n.Line, n.Tag)
+		}
+		return FloatSpecial(strings.TrimPrefix(n.Tag, "!")), nil
+	case "!dateBetween":
+		return decodeDateBetween(n)
+	case "!sha256":
+		digest := strings.ToLower(n.Value)
+		if _, err := hex.DecodeString(digest); n.Kind != yaml.ScalarNode || err != nil || len(digest) != 64 {
+			return nil, fmt.Errorf("line %d: !sha256 expects 64 hex digits", n.Line)
+		}
+		return SHA256(digest), nil
+	case "!file":
+		var m File
+		switch n.Kind {
+		case yaml.ScalarNode:
+			m.Path = n.Value
+		case yaml.MappingNode:
+			// the form written by MarshalYAML; the digest is recomputed on load
+			type plain File
+			if err := decodeUntagged(n, (*plain)(&m)); err != nil {
+				return nil, fmt.Errorf("line %d: invalid !file: %w", n.Line, err)
+			}
+		}
+		if m.Path == "" {
+			return nil, fmt.Errorf("line %d: !file expects a path", n.Line)
+		}
+		return File{Path: m.Path}, nil
+	case "!today":
+		name := DefaultTodayLocation
+		if n.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("line %d: !today expects an optional time zone", n.Line)
+		}
+		if n.Value != "" {
+			name = n.Value
+		}
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: !today: %w", n.Line, err)
+		}
+		return Today{Location: loc}, nil
+	case "!recent":
+		var m Recent
+		if n.Kind != yaml.ScalarNode || decodeUntagged(n, &m.Within) != nil || m.Within <= 0 {
+			return nil, fmt.Errorf("line %d: !recent expects a positive duration such as 10m", n.Line)
+		}
+		return m, nil
+	}
+	if strings.HasPrefix(n.Tag, "!") && !strings.HasPrefix(n.Tag, "!!") {
+		return nil, fmt.Errorf("line %d: unknown matcher tag %s", n.Line, n.Tag)
+	}
+
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// AnyRow is a whole-row wildcard (`!anyRow {count: 3}`): Count more rows exist whose
+// content is not checked. It keeps strict row counting while only some rows are spelled out.
+type AnyRow struct {
+	Count int `yaml:"count"`
+}
+
+func (m AnyRow) String() string {
+	return fmt.Sprintf("anyRow{count=%d}", m.Count)
+}
+
+func (m AnyRow) MarshalYAML() (any, error) {
+	type plain AnyRow
+	return taggedNode("!anyRow", plain(m))
+}
+
+// anyRowKey holds the AnyRow of a wildcard entry in Rows. It cannot clash with a column name.
+const anyRowKey = "!anyRow"
+
+func decodeAnyRow(n *yaml.Node) (AnyRow, error) {
+	m := AnyRow{Count: 1}
+	switch {
+	case n.Kind == yaml.ScalarNode && n.Value == "":
+	case n.Kind == yaml.MappingNode:
+		type plain AnyRow
+		if err := decodeUntagged(n, (*plain)(&m)); err != nil {
+			return m, fmt.Errorf("line %d: invalid !anyRow: %w", n.Line, err)
+		}
+	default:
+		return m, fmt.Errorf("line %d: !anyRow expects {count: n}", n.Line)
+	}
+	if m.Count < 1 {
+		return m, fmt.Errorf("line %d: !anyRow count must be at least 1", n.Line)
+	}
+	return m, nil
+}
+
+// Rows is a list of expected rows keyed by column name. Entries written as `!anyRow`
+// are wildcards; use Split to separate them from the rows to match.
+type Rows []map[string]any
+
+// UnmarshalYAML decodes expected rows, resolving matcher tags in column values.
+func (r *Rows) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.SequenceNode {
+		return &yaml.TypeError{Errors: []string{fmt.Sprintf("line %d: expected a list of rows", node.Line)}}
+	}
+	rows := make(Rows, 0, len(node.Content))
+	// problems are collected across rows, and returned as a yaml.TypeError so decoding
+	// carries on with the rest of the config
+	var errs []string
+	for _, item := range node.Content {
+		switch item.Tag {
+		case "!anyRow":
+			m, err := decodeAnyRow(item)
+			if err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			rows = append(rows, map[string]any{anyRowKey: m})
+			continue
+		case "!ref":
+			ref, err := decodeRef(item)
+			if err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			rows = append(rows, map[string]any{refKey: ref, lineKey: item.Line})
+			continue
+		}
+		decoded, err := decodeRow(item)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if v, ok := decoded[ignoreKey]; ok {
+			ignore, isBool := v.(bool)
+			if !isBool {
+				errs = append(errs, fmt.Sprintf("line %d: %s must be true or false", item.Line, ignoreKey))
+				continue
+			}
+			if !ignore {
+				delete(decoded, ignoreKey)
+			}
+		}
+		if v, ok := decoded[messageKey]; ok {
+			if _, isString := v.(string); !isString {
+				errs = append(errs, fmt.Sprintf("line %d: %s must be a string", item.Line, messageKey))
+				continue
+			}
+		}
+		if v, ok := decoded[xfailKey]; ok {
+			if marker, isString := v.(string); !isString || marker == "" {
+				errs = append(errs, fmt.Sprintf("line %d: %s must be a non-empty string", item.Line, xfailKey))
+				continue
+			}
+		}
+		decoded[lineKey] = item.Line
+		rows = append(rows, decoded)
+	}
+	if len(errs) > 0 {
+		return &yaml.TypeError{Errors: errs}
+	}
+	*r = rows
+	return nil
+}
+
+// decodeRow decodes a single expected row mapping, resolving matcher tags in column values.
+func decodeRow(n *yaml.Node) (map[string]any, error) {
+	var raw map[string]yaml.Node
+	if err := n.Decode(&raw); err != nil {
+		return nil, err
+	}
+	decoded := make(map[string]any, len(raw))
+	for col, cn := range raw {
+		v, err := decodeValue(&cn)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", col, err)
+		}
+		decoded[col] = v
+	}
+	return decoded, nil
+}
+
+// MarshalYAML encodes wildcard entries back into their `!anyRow` form.
+func (r Rows) MarshalYAML() (any, error) {
+	out := make([]any, len(r))
+	for i, row := range r {
+		if m, ok := row[anyRowKey].(AnyRow); ok {
+			out[i] = m
+			continue
+		}
+		if ref, ok := row[refKey].(Ref); ok {
+			out[i] = ref
+			continue
+		}
+		if _, ok := row[lineKey]; ok {
+			row = maps.Clone(row)
+			delete(row, lineKey)
+		}
+		out[i] = row
+	}
+	return out, nil
+}
+
+// ignoreKey marks an expected row (`__ignore: true`) that stays in the config but is not
+// checked. It stands for one row of any content, like `!anyRow`, so row counts still hold.
+const ignoreKey = "__ignore"
+
+// xfailKey marks an expected row known to fail (`__expectedFailure: "JIRA-123"`). Like an
+// ignored row it stands for one row of any content; the validator checks it separately.
+const xfailKey = "__expectedFailure"
+
+// messageKey holds a message (`__message: "seeded by migration 042"`) prepended to the
+// errors about an expected row.
+const messageKey = "__message"
+
+// lineKey holds the config line of an expected row, recorded while decoding so that errors
+// about the row can point at it. It is not written back by MarshalYAML.
+const lineKey = "__line"
+
+// IsMetaColumn reports whether a key of an expected row is an annotation such as
+// `__message` rather than a column.
+func IsMetaColumn(key string) bool {
+	return key == ignoreKey || key == xfailKey || key == messageKey || key == lineKey || key == keyKey
+}
+
+// LineOf returns the config line of an expected row, or 0 for rows not read from YAML,
+// such as rows of external sources.
+func LineOf(row map[string]any) int {
+	line, _ := row[lineKey].(int)
+	return line
+}
+
+// MessageOf returns the `__message` of an expected row, or "".
+func MessageOf(row map[string]any) string {
+	m, _ := row[messageKey].(string)
+	return m
+}
+
+// AsAnyRow returns the wildcard of a Rows entry written as `!anyRow`. Ignored rows and rows
+// marked as expected failures are wildcards for a single row.
+func AsAnyRow(row map[string]any) (AnyRow, bool) {
+	if _, ok := row[xfailKey]; ok || row[ignoreKey] == true {
+		return AnyRow{Count: 1}, true
+	}
+	m, ok := row[anyRowKey].(AnyRow)
+	return m, ok
+}
+
+// ExpectedFailureRow is an expected row marked with `__expectedFailure`.
+type ExpectedFailureRow struct {
+	// Pos is the 1-based position of the row.
+	Pos int
+	// Marker is the value of `__expectedFailure`, typically an issue key.
+	Marker string
+	// Row is the expected row without the marker.
+	Row map[string]any
+}
+
+// ExpectedFailures returns the rows marked as expected failures.
+func (r Rows) ExpectedFailures() []ExpectedFailureRow {
+	var rows []ExpectedFailureRow
+	for i, row := range r {
+		marker, ok := row[xfailKey].(string)
+		if !ok {
+			continue
+		}
+		plain := make(map[string]any, len(row)-1)
+		for k, v := range row {
+			if k != xfailKey {
+				plain[k] = v
+			}
+		}
+		rows = append(rows, ExpectedFailureRow{Pos: i + 1, Marker: marker, Row: plain})
+	}
+	return rows
+}
+
+// Ignored returns the 1-based positions of the ignored rows.
+func (r Rows) Ignored() []int {
+	var rows []int
+	for i, row := range r {
+		if row[ignoreKey] == true {
+			rows = append(rows, i+1)
+		}
+	}
+	return rows
+}
+
+// Split returns the rows to match and the number of additional rows allowed by `!anyRow` wildcards.
+func (r Rows) Split() (rows []map[string]any, anyRows int) {
+	for _, row := range r {
+		if m, ok := AsAnyRow(row); ok {
+			anyRows += m.Count
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, anyRows
+}
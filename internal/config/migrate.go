@@ -0,0 +1,144 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config format versions. Version 1 is assumed when `version` is absent. Version 2 drops
+// the allowUnorderedRows option in favour of `strategy: ordered` on each table.
+const (
+	VersionLegacy  = 1
+	CurrentVersion = 2
+)
+
+// checkVersion rejects unknown versions and the option forms a version no longer accepts.
+func (c *Config) checkVersion(root *yaml.Node) error {
+	switch c.Version {
+	case 0, VersionLegacy, CurrentVersion:
+	default:
+		return atLine(keyLine(root, "version"),
+			fmt.Errorf("unsupported config version %d (this spalidate reads versions %d to %d)", c.Version, VersionLegacy, CurrentVersion))
+	}
+	if c.Version < 2 {
+		return nil
+	}
+	removed := fmt.Errorf("allowUnorderedRows was removed in version 2; use `strategy: ordered` on tables (spalidate migrate-config rewrites it)")
+	if c.Options.AllowUnorderedRows != nil {
+		return atLine(keyLine(root, "options", "allowUnorderedRows"), removed)
+	}
+	for name, t := range c.Tables {
+		if t.Options != nil && t.Options.AllowUnorderedRows != nil {
+			return atLine(keyLine(root, "tables", name, "options", "allowUnorderedRows"), fmt.Errorf("table %s: %w", name, removed))
+		}
+	}
+	return nil
+}
+
+// Migrate rewrites a configuration into the current format, keeping its comments and key
+// order, and describes each change. A configuration that is already current is returned
+// unchanged with no changes.
+func Migrate(data []byte) ([]byte, []string, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("expected a YAML mapping")
+	}
+	doc := root.Content[0]
+
+	version := VersionLegacy
+	if v := mappingValue(doc, "version"); v != nil {
+		n, err := strconv.Atoi(v.Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: invalid version %q", v.Line, v.Value)
+		}
+		version = n
+	}
+	switch {
+	case version == CurrentVersion:
+		return data, nil, nil
+	case version > CurrentVersion || version < VersionLegacy:
+		return nil, nil, fmt.Errorf("unsupported config version %d", version)
+	}
+
+	// the comment at the top of the file stays there, above the added version
+	var lead string
+	if len(doc.Content) > 0 && mappingValue(doc, "version") == nil {
+		lead, doc.Content[0].HeadComment = doc.Content[0].HeadComment, ""
+	}
+
+	var changes []string
+	ordered := false
+	if opts := mappingValue(doc, "options"); opts != nil {
+		if v := removeKey(opts, "allowUnorderedRows"); v != nil {
+			ordered = v.Value == "false"
+			changes = append(changes, "removed options.allowUnorderedRows")
+		}
+		if len(opts.Content) == 0 {
+			removeKey(doc, "options")
+		}
+	}
+	if tables := mappingValue(doc, "tables"); tables != nil && tables.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(tables.Content); i += 2 {
+			name, table := tables.Content[i].Value, tables.Content[i+1]
+			if table.Kind != yaml.MappingNode {
+				continue
+			}
+			tableOrdered := ordered
+			if opts := mappingValue(table, "options"); opts != nil {
+				if v := removeKey(opts, "allowUnorderedRows"); v != nil {
+					tableOrdered = v.Value == "false"
+					changes = append(changes, fmt.Sprintf("removed tables.%s.options.allowUnorderedRows", name))
+				}
+				if len(opts.Content) == 0 {
+					removeKey(table, "options")
+				}
+			}
+			if tableOrdered && mappingValue(table, "strategy") == nil {
+				table.Content = append(table.Content, scalarNode("strategy"), scalarNode(StrategyOrdered))
+				changes = append(changes, fmt.Sprintf("set tables.%s.strategy to ordered", name))
+			}
+		}
+	}
+
+	if v := mappingValue(doc, "version"); v != nil {
+		v.Value, v.Tag, v.Style = strconv.Itoa(CurrentVersion), "!!int", 0
+	} else {
+		key := scalarNode("version")
+		key.HeadComment = lead
+		doc.Content = append([]*yaml.Node{key, scalarNode(strconv.Itoa(CurrentVersion))}, doc.Content...)
+	}
+	changes = append(changes, fmt.Sprintf("set version to %d", CurrentVersion))
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return nil, nil, fmt.Errorf("encoding config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, fmt.Errorf("encoding config: %w", err)
+	}
+	return b.Bytes(), changes, nil
+}
+
+// removeKey deletes key from a mapping node and returns its value node, or nil.
+func removeKey(n *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			v := n.Content[i+1]
+			n.Content = append(n.Content[:i], n.Content[i+2:]...)
+			return v
+		}
+	}
+	return nil
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+}
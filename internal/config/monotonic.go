@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Monotonic directions.
+const (
+	Ascending  = "asc"
+	Descending = "desc"
+)
+
+// Monotonic asserts that a column never decreases (or, descending, never increases) over
+// the rows of a table in order (`monotonic: {column: CreatedAt}`), e.g. for event or
+// ledger tables, without listing the rows.
+type Monotonic struct {
+	Column string `yaml:"column"`
+	// Direction is asc or desc.
+	Direction string `yaml:"direction,omitempty" default:"asc"`
+	// OrderBy lists the columns ordering the rows; empty means the primary key.
+	OrderBy []string `yaml:"orderBy,omitempty" default:"primary key"`
+}
+
+// validate rejects incomplete monotonic assertions and normalizes the direction.
+func (m *Monotonic) validate() error {
+	if m.Column == "" {
+		return fmt.Errorf("monotonic needs a column")
+	}
+	m.Direction = strings.ToLower(m.Direction)
+	switch m.Direction {
+	case "":
+		m.Direction = Ascending
+	case Ascending, Descending:
+	default:
+		return fmt.Errorf("unknown monotonic direction %q (want asc or desc)", m.Direction)
+	}
+	return nil
+}
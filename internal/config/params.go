@@ -0,0 +1,232 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"gopkg.in/yaml.v3"
+)
+
+// Built-in parameters of `where` filters: RunStartParam is the anchor given with --anchor,
+// the start of the run under test, and RunEndParam the time validation started. They are
+// named, so only GoogleSQL filters can use them.
+const (
+	RunStartParam = "runStart"
+	RunEndParam   = "runEnd"
+)
+
+// TimeRelative reports whether the outcome of the config depends on when it runs: a where
+// filter uses @runStart or @runEnd, or an expected value is `!today` or `!recent`.
+func (c *Config) TimeRelative() bool {
+	for _, t := range c.Tables {
+		if strings.Contains(t.Where, "@"+RunStartParam) || strings.Contains(t.Where, "@"+RunEndParam) {
+			return true
+		}
+		rowSets := append([]Rows{t.Columns}, slices.Collect(maps.Values(t.Datasets))...)
+		for _, rows := range rowSets {
+			for _, row := range rows {
+				if slices.ContainsFunc(slices.Collect(maps.Values(row)), isTimeRelative) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func isTimeRelative(v any) bool {
+	switch m := v.(type) {
+	case Today, Recent:
+		return true
+	case OneOf:
+		return slices.ContainsFunc(m.Values, isTimeRelative)
+	}
+	return false
+}
+
+// Param types accepted in the `type:` of a query parameter.
+const (
+	ParamInt64     = "INT64"
+	ParamFloat64   = "FLOAT64"
+	ParamString    = "STRING"
+	ParamBool      = "BOOL"
+	ParamTimestamp = "TIMESTAMP"
+	ParamDate      = "DATE"
+	ParamNumeric   = "NUMERIC"
+	ParamBytes     = "BYTES"
+)
+
+// Param is a query parameter of a table's `where` filter, bound as a Spanner statement
+// parameter. It is written as a plain value (`42`, `"a"`, `[1, 2]`,
+// `2024-01-01T00:00:00Z`) whose type is inferred, or as `{type: DATE, value: 2024-01-01}`.
+// A list value is bound as an ARRAY of the type.
+type Param struct {
+	Type  string
+	Value any
+	// bound is Value converted to the Go type the Spanner client binds as Type.
+	bound any
+}
+
+// Bound returns the value to bind as a statement parameter.
+func (p Param) Bound() any {
+	return p.bound
+}
+
+func (p Param) MarshalYAML() (any, error) {
+	return map[string]any{"type": p.Type, "value": p.Value}, nil
+}
+
+// UnmarshalYAML decodes a plain or typed parameter value and converts it for binding.
+func (p *Param) UnmarshalYAML(n *yaml.Node) error {
+	valueNode := n
+	if n.Kind == yaml.MappingNode {
+		var typed struct {
+			Type  string    `yaml:"type"`
+			Value yaml.Node `yaml:"value"`
+		}
+		if err := n.Decode(&typed); err != nil {
+			return fmt.Errorf("line %d: invalid param: %w", n.Line, err)
+		}
+		p.Type = strings.ToUpper(typed.Type)
+		valueNode = &typed.Value
+	}
+	if err := valueNode.Decode(&p.Value); err != nil {
+		return fmt.Errorf("line %d: invalid param: %w", n.Line, err)
+	}
+	if p.Type == "" {
+		t, err := inferParamType(p.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n.Line, err)
+		}
+		p.Type = t
+	}
+
+	var err error
+	if valueNode.Kind == yaml.SequenceNode {
+		p.bound, err = bindParamList(p.Type, valueNode.Content)
+	} else {
+		p.bound, err = bindParam(p.Type, valueNode)
+	}
+	if err != nil {
+		return fmt.Errorf("line %d: param of type %s: %w", n.Line, p.Type, err)
+	}
+	return nil
+}
+
+// inferParamType returns the type of a plain value. Lists mixing integers and floats are FLOAT64.
+func inferParamType(v any) (string, error) {
+	switch x := v.(type) {
+	case int, int64, uint64:
+		return ParamInt64, nil
+	case float64:
+		return ParamFloat64, nil
+	case string:
+		return ParamString, nil
+	case bool:
+		return ParamBool, nil
+	case time.Time:
+		return ParamTimestamp, nil
+	case nil:
+		return "", fmt.Errorf("NULL params are not supported; use IS NULL in the where filter")
+	case []any:
+		if len(x) == 0 {
+			return "", fmt.Errorf("cannot infer the type of an empty list; use {type: ..., value: []}")
+		}
+		t, err := inferParamType(x[0])
+		if err != nil {
+			return "", err
+		}
+		for _, item := range x[1:] {
+			it, err := inferParamType(item)
+			if err != nil {
+				return "", err
+			}
+			switch {
+			case it == t:
+			case it == ParamFloat64 && t == ParamInt64, it == ParamInt64 && t == ParamFloat64:
+				t = ParamFloat64
+			default:
+				return "", fmt.Errorf("list mixes %s and %s values", t, it)
+			}
+		}
+		return t, nil
+	}
+	return "", fmt.Errorf("unsupported param value %v", v)
+}
+
+// bindParam converts a scalar node to the Go type bound as typ.
+func bindParam(typ string, n *yaml.Node) (any, error) {
+	if n.Kind != yaml.ScalarNode || n.Tag == "!!null" {
+		return nil, fmt.Errorf("expected a non-null scalar")
+	}
+	switch typ {
+	case ParamInt64:
+		var v int64
+		err := n.Decode(&v)
+		return v, err
+	case ParamFloat64:
+		var v float64
+		err := n.Decode(&v)
+		return v, err
+	case ParamString:
+		return n.Value, nil
+	case ParamBool:
+		var v bool
+		err := n.Decode(&v)
+		return v, err
+	case ParamTimestamp:
+		return time.Parse(time.RFC3339Nano, n.Value)
+	case ParamDate:
+		return civil.ParseDate(n.Value)
+	case ParamNumeric:
+		v, ok := new(big.Rat).SetString(n.Value)
+		if !ok {
+			return nil, fmt.Errorf("invalid NUMERIC %q", n.Value)
+		}
+		return *v, nil
+	case ParamBytes:
+		return base64.StdEncoding.DecodeString(n.Value)
+	}
+	return nil, fmt.Errorf("unknown type")
+}
+
+// bindParamList converts list items to a typed slice, bound as ARRAY<typ>.
+func bindParamList(typ string, items []*yaml.Node) (any, error) {
+	switch typ {
+	case ParamInt64:
+		return bindList[int64](typ, items)
+	case ParamFloat64:
+		return bindList[float64](typ, items)
+	case ParamString:
+		return bindList[string](typ, items)
+	case ParamBool:
+		return bindList[bool](typ, items)
+	case ParamTimestamp:
+		return bindList[time.Time](typ, items)
+	case ParamDate:
+		return bindList[civil.Date](typ, items)
+	case ParamNumeric:
+		return bindList[big.Rat](typ, items)
+	case ParamBytes:
+		return bindList[[]byte](typ, items)
+	}
+	return nil, fmt.Errorf("unknown type")
+}
+
+func bindList[T any](typ string, items []*yaml.Node) ([]T, error) {
+	out := make([]T, len(items))
+	for i, item := range items {
+		v, err := bindParam(typ, item)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i+1, err)
+		}
+		out[i] = v.(T)
+	}
+	return out, nil
+}
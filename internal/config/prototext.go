@@ -0,0 +1,157 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// readPrototextRows parses a text-format message of type src.Message, described by the
+// FileDescriptorSet at descriptorPath, and maps its fields to columns by field name.
+// When src.Field names a repeated message field, each element becomes one row;
+// otherwise the whole message is a single row.
+func readPrototextRows(path, descriptorPath string, src *SourceConfig) ([]map[string]any, error) {
+	if src.Message == "" {
+		return nil, fmt.Errorf("prototext source requires a message type")
+	}
+	raw, err := os.ReadFile(descriptorPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build descriptors: %w", err)
+	}
+	d, err := files.FindDescriptorByName(protoreflect.FullName(src.Message))
+	if err != nil {
+		return nil, fmt.Errorf("message %s not found in descriptor set: %w", src.Message, err)
+	}
+	md, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a message type", src.Message)
+	}
+
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	msg := dynamicpb.NewMessage(md)
+	if err := prototext.Unmarshal(text, msg); err != nil {
+		return nil, fmt.Errorf("failed to parse prototext: %w", err)
+	}
+
+	if src.Field == "" {
+		row, err := protoRow(msg)
+		if err != nil {
+			return nil, err
+		}
+		return []map[string]any{row}, nil
+	}
+
+	fd := md.Fields().ByName(protoreflect.Name(src.Field))
+	if fd == nil || !fd.IsList() || fd.Message() == nil {
+		return nil, fmt.Errorf("%s has no repeated message field %s", src.Message, src.Field)
+	}
+	list := msg.Get(fd).List()
+	rows := make([]map[string]any, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		row, err := protoRow(list.Get(i).Message())
+		if err != nil {
+			return nil, fmt.Errorf("element %d of %s: %w", i, src.Field, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func protoRow(m protoreflect.Message) (map[string]any, error) {
+	fields := m.Descriptor().Fields()
+	row := make(map[string]any, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.HasPresence() && !m.Has(fd) {
+			row[string(fd.Name())] = nil
+			continue
+		}
+		v, err := protoValue(fd, m.Get(fd))
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", fd.Name(), err)
+		}
+		row[string(fd.Name())] = v
+	}
+	return row, nil
+}
+
+func protoValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) (any, error) {
+	if fd.IsList() {
+		list := v.List()
+		out := make([]any, 0, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			e, err := protoScalar(fd, list.Get(i))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, e)
+		}
+		return out, nil
+	}
+	if fd.IsMap() {
+		return nil, fmt.Errorf("map fields are not supported")
+	}
+	return protoScalar(fd, v)
+}
+
+func protoScalar(fd protoreflect.FieldDescriptor, v protoreflect.Value) (any, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return v.Bool(), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return v.Int(), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return int64(v.Uint()), nil
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return v.Float(), nil
+	case protoreflect.StringKind:
+		return v.String(), nil
+	case protoreflect.BytesKind:
+		return v.Bytes(), nil
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name()), nil
+		}
+		return int64(v.Enum()), nil
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		m := v.Message()
+		if m.Descriptor().FullName() == "google.protobuf.Timestamp" {
+			f := m.Descriptor().Fields()
+			secs := m.Get(f.ByName("seconds")).Int()
+			nanos := m.Get(f.ByName("nanos")).Int()
+			return time.Unix(secs, nanos).UTC(), nil
+		}
+		// Nested messages are compared as JSON documents.
+		b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m.Interface())
+		if err != nil {
+			return nil, err
+		}
+		var doc any
+		if err := json.Unmarshal(b, &doc); err != nil {
+			return nil, err
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unsupported field kind %s", fd.Kind())
+}
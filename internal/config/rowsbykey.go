@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// keyKey holds the primary key value of a row written under rowsByKey. The key column is
+// only known from the schema, so the validator moves the value there; see WithKeys.
+const keyKey = "__key"
+
+// KeyedRows are expected rows written as a mapping from primary key value to the other
+// columns (`rowsByKey: {user-001: {Name: Alice}}`), for tables with a single-column
+// primary key. Each key can be written only once.
+type KeyedRows Rows
+
+func (k *KeyedRows) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping of primary key to row", node.Line)
+	}
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Line: node.Line, Column: node.Column}
+	keys := make([]any, 0, len(node.Content)/2)
+	seen := map[string]int{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		kn, vn := node.Content[i], node.Content[i+1]
+		var key any
+		if err := kn.Decode(&key); err != nil {
+			return fmt.Errorf("line %d: invalid key: %w", kn.Line, err)
+		}
+		// 42 and "42" name the same row once compared under the key column's type
+		if first, ok := seen[fmt.Sprint(key)]; ok {
+			return fmt.Errorf("line %d: duplicate key %v (first at line %d)", kn.Line, key, first)
+		}
+		seen[fmt.Sprint(key)] = kn.Line
+		keys = append(keys, key)
+		switch {
+		case vn.Tag == "!anyRow":
+			return fmt.Errorf("line %d: rowsByKey rows cannot be !anyRow", vn.Line)
+		case vn.Kind == yaml.ScalarNode && vn.Tag == "!!null":
+			// a key alone only asserts that the row exists
+			vn = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: kn.Line, Column: kn.Column}
+		}
+		seq.Content = append(seq.Content, vn)
+	}
+	var rows Rows
+	if err := rows.UnmarshalYAML(seq); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		row[keyKey] = keys[i]
+	}
+	*k = KeyedRows(rows)
+	return nil
+}
+
+// expandRowsByKey appends the rowsByKey rows of a table to its columns. They are paired
+// with the actual rows by primary key, so the table must not select another strategy.
+func (t *TableConfig) expandRowsByKey() error {
+	if t.RowsByKey == nil {
+		return nil
+	}
+	switch {
+	case len(t.Columns) > 0 || len(t.Datasets) > 0 || t.Source != nil:
+		return fmt.Errorf("rowsByKey cannot be combined with columns, datasets or source")
+	case t.Strategy != "" && t.Strategy != StrategyPrimaryKey:
+		return fmt.Errorf("rowsByKey requires strategy %s, got %s", StrategyPrimaryKey, t.Strategy)
+	}
+	t.Columns = Rows(t.RowsByKey)
+	t.RowsByKey = nil
+	t.Strategy = StrategyPrimaryKey
+	return nil
+}
+
+// WithKeys returns the rows with the key of each rowsByKey row put under the primary key
+// column. Rows without a key are returned as they are.
+func (r Rows) WithKeys(pk []string) (Rows, error) {
+	var out Rows
+	for i, row := range r {
+		key, ok := row[keyKey]
+		if !ok {
+			continue
+		}
+		if len(pk) != 1 {
+			return nil, fmt.Errorf("rowsByKey requires a single-column primary key, got (%s)", strings.Join(pk, ", "))
+		}
+		if _, ok := row[pk[0]]; ok {
+			return nil, atLine(LineOf(row), fmt.Errorf("rowsByKey row %v also sets the primary key column %s", key, pk[0]))
+		}
+		if out == nil {
+			out = make(Rows, len(r))
+			copy(out, r)
+		}
+		keyed := maps.Clone(row)
+		delete(keyed, keyKey)
+		keyed[pk[0]] = key
+		out[i] = keyed
+	}
+	if out == nil {
+		return r, nil
+	}
+	return out, nil
+}
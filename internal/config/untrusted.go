@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"text/template"
+
+	"github.com/nu0ma/spalidate/internal/errkind"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseUntrusted parses a config submitted over the network, e.g. to `spalidate api`. It
+// rejects everything that reaches beyond the database: external files (`source`, seed
+// fixtures, `!file`), the env function in when expressions, SQL hooks and `where`
+// filters, and table, column or change stream names that are not plain identifiers. Its
+// errors are errkind.ErrConfig.
+func ParseUntrusted(data []byte) (*Config, error) {
+	config, err := parse(data, "", true)
+	if err != nil {
+		return nil, errkind.Mark(err, errkind.ErrConfig)
+	}
+	return config, nil
+}
+
+// identifierPattern matches a table or column name, optionally schema-qualified.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// checkUntrusted lists the parts of a decoded config that ParseUntrusted rejects.
+func (c *Config) checkUntrusted(root *yaml.Node) Errors {
+	var errs Errors
+	reject := func(line int, format string, args ...any) {
+		errs = append(errs, atLine(line, fmt.Errorf(format+" is not allowed in submitted configs", args...)))
+	}
+	if len(c.Before) > 0 {
+		reject(keyLine(root, "before"), "before")
+	}
+	if len(c.After) > 0 {
+		reject(keyLine(root, "after"), "after")
+	}
+	if c.Seed != nil {
+		reject(keyLine(root, "seed"), "seed")
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Definitions)) {
+		if hasFile(c.Definitions[name]) {
+			reject(keyLine(root, "definitions", name), "definition %s: !file", name)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.ChangeStreams)) {
+		if !identifierPattern.MatchString(name) {
+			errs = append(errs, atLine(keyLine(root, "changeStreams", name), fmt.Errorf("change stream %q is not a plain change stream name", name)))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Tables)) {
+		t := c.Tables[name]
+		line := func(key string) int { return keyLine(root, "tables", name, key) }
+		ident := func(key, col string) {
+			if !identifierPattern.MatchString(col) {
+				errs = append(errs, atLine(line(key), fmt.Errorf("table %s: %s: %q is not a plain column name", name, key, col)))
+			}
+		}
+		if !identifierPattern.MatchString(name) {
+			errs = append(errs, atLine(keyLine(root, "tables", name), fmt.Errorf("table %q is not a plain table name", name)))
+		}
+		if t.Source != nil {
+			reject(line("source"), "table %s: source", name)
+		}
+		if t.Where != "" {
+			reject(line("where"), "table %s: where", name)
+		}
+		if _, err := parseWhen(t.When); err == nil && t.When != "" {
+			// env is the only function of when expressions, so it is the one left undefined here
+			if _, err := template.New("when").Parse(t.When); err != nil {
+				reject(line("when"), "table %s: env in when", name)
+			}
+		}
+		rowSets := append([]Rows{t.Columns}, slices.Collect(maps.Values(t.Datasets))...)
+		if slices.ContainsFunc(rowSets, func(rows Rows) bool { return slices.ContainsFunc(rows, hasFile) }) {
+			reject(line("columns"), "table %s: !file", name)
+		}
+		for _, col := range t.IgnoreColumns {
+			ident("ignoreColumns", col)
+		}
+		for _, col := range slices.Sorted(maps.Keys(t.ColumnBounds)) {
+			ident("columnBounds", col)
+		}
+		if t.Distribution != nil {
+			ident("distribution", t.Distribution.Column)
+		}
+		if t.Monotonic != nil {
+			for _, col := range append([]string{t.Monotonic.Column}, t.Monotonic.OrderBy...) {
+				ident("monotonic", col)
+			}
+		}
+	}
+	return errs
+}
+
+// hasFile reports whether a row expects a `!file` value, directly or as a `!oneOf` candidate.
+func hasFile(row map[string]any) bool {
+	for _, v := range row {
+		if isFile(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func isFile(v any) bool {
+	switch m := v.(type) {
+	case File:
+		return true
+	case OneOf:
+		return slices.ContainsFunc(m.Values, isFile)
+	}
+	return false
+}
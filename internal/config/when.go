@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+var whenFuncs = template.FuncMap{
+	"env": os.Getenv,
+}
+
+// EvalWhen renders a when expression as a text/template and evaluates the result.
+// Supported forms after rendering are `a == b`, `a != b` and a bare boolean-like value
+// (true/false, 1/0, yes/no, on/off). Operands may be single- or double-quoted.
+func EvalWhen(expr string) (bool, error) {
+	tmpl, err := parseWhen(expr)
+	if err != nil {
+		return false, err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, nil); err != nil {
+		return false, fmt.Errorf("failed to render when expression %q: %w", expr, err)
+	}
+	rendered := strings.TrimSpace(b.String())
+
+	if lhs, rhs, ok := strings.Cut(rendered, "!="); ok {
+		return unquote(lhs) != unquote(rhs), nil
+	}
+	if lhs, rhs, ok := strings.Cut(rendered, "=="); ok {
+		return unquote(lhs) == unquote(rhs), nil
+	}
+
+	switch strings.ToLower(unquote(rendered)) {
+	case "true", "1", "yes", "on":
+		return true, nil
+	case "", "false", "0", "no", "off":
+		return false, nil
+	}
+	// the rendered text is not echoed, as it may hold environment values
+	return false, fmt.Errorf("when expression %q does not render to a boolean", expr)
+}
+
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 {
+		if (s[0] == '"' && s[len(s)-1] == '"') || (s[0] == '\'' && s[len(s)-1] == '\'') {
+			return s[1 : len(s)-1]
+		}
+	}
+	return s
+}
+
+// parseWhen compiles the template of a when expression. Parse calls it so syntax errors
+// surface at load time rather than when the table is reached.
+func parseWhen(expr string) (*template.Template, error) {
+	tmpl, err := template.New("when").Funcs(whenFuncs).Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid when expression %q: %w", expr, err)
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,33 @@
+// Package errkind holds the sentinel errors that classify spalidate failures, so callers
+// can branch with errors.Is instead of matching messages.
+package errkind
+
+import "errors"
+
+var (
+	// ErrConfig marks configuration files that cannot be read, parsed or applied.
+	ErrConfig = errors.New("invalid configuration")
+	// ErrConnection marks failures to reach or authenticate with Spanner.
+	ErrConnection = errors.New("cannot connect to Spanner")
+	// ErrTableNotFound marks reads of tables that do not exist.
+	ErrTableNotFound = errors.New("table not found")
+	// ErrRowMismatch marks data that does not match its expectations.
+	ErrRowMismatch = errors.New("rows do not match")
+)
+
+// Mark classifies err as kind for errors.Is while keeping its message. Nil stays nil, and
+// errors already of that kind are returned unchanged.
+func Mark(err, kind error) error {
+	if err == nil || errors.Is(err, kind) {
+		return err
+	}
+	return &marked{err: err, kind: kind}
+}
+
+type marked struct {
+	err, kind error
+}
+
+func (e *marked) Error() string { return e.err.Error() }
+
+func (e *marked) Unwrap() []error { return []error{e.err, e.kind} }
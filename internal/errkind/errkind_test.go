@@ -0,0 +1,32 @@
+package errkind
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type detail struct{ msg string }
+
+func (d *detail) Error() string { return d.msg }
+
+func TestMark(t *testing.T) {
+	base := &detail{msg: "table Users: 1 row missing"}
+	err := fmt.Errorf("validation failed: %w", Mark(base, ErrRowMismatch))
+	if err.Error() != "validation failed: table Users: 1 row missing" {
+		t.Errorf("Expected the message to be unchanged, got %q", err)
+	}
+	if !errors.Is(err, ErrRowMismatch) || errors.Is(err, ErrConfig) {
+		t.Errorf("Expected only ErrRowMismatch, got %v", err)
+	}
+	var d *detail
+	if !errors.As(err, &d) || d != base {
+		t.Error("Expected the wrapped error to stay reachable with errors.As")
+	}
+	if Mark(nil, ErrConfig) != nil {
+		t.Error("Expected nil to stay nil")
+	}
+	if m := Mark(err, ErrRowMismatch); m != err {
+		t.Error("Expected an error of the same kind to be returned unchanged")
+	}
+}
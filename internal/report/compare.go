@@ -0,0 +1,107 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Kinds of change of a table between two reports.
+const (
+	ChangeNewlyFailing = "newly failing"
+	ChangeNewlyPassing = "newly passing"
+	ChangeStillFailing = "still failing"
+	ChangeAdded        = "added"
+	ChangeRemoved      = "removed"
+)
+
+// TableChange describes how a table's outcome differs between an old and a new report.
+type TableChange struct {
+	Table string
+	Kind  string
+	// Before and After are the table's statuses; empty when it is missing from that report.
+	Before, After string
+	// OldError and NewError are the failure messages of each side, if any.
+	OldError, NewError string
+}
+
+// Compare returns the tables whose outcome changed between two reports, plus the tables
+// that fail in both, sorted by table name. Tables that pass (or are skipped) in both are omitted.
+func Compare(oldReport, newReport *Report) []TableChange {
+	before := make(map[string]TableReport, len(oldReport.Tables))
+	for _, t := range oldReport.Tables {
+		before[t.Table] = t
+	}
+	after := make(map[string]TableReport, len(newReport.Tables))
+	for _, t := range newReport.Tables {
+		after[t.Table] = t
+	}
+
+	var changes []TableChange
+	for name, a := range after {
+		b, ok := before[name]
+		c := TableChange{Table: name, Before: b.Status, After: a.Status, OldError: b.Error, NewError: a.Error}
+		switch {
+		case !ok:
+			c.Kind = ChangeAdded
+		case a.Status == StatusFailed && b.Status == StatusFailed:
+			c.Kind = ChangeStillFailing
+		case a.Status == StatusFailed:
+			c.Kind = ChangeNewlyFailing
+		case b.Status == StatusFailed:
+			c.Kind = ChangeNewlyPassing
+		default:
+			continue
+		}
+		changes = append(changes, c)
+	}
+	for name, b := range before {
+		if _, ok := after[name]; !ok {
+			changes = append(changes, TableChange{Table: name, Kind: ChangeRemoved, Before: b.Status, OldError: b.Error})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Table < changes[j].Table })
+	return changes
+}
+
+// NewlyFailing returns the number of tables that fail in the new report but did not in the
+// old one, including added tables that fail.
+func NewlyFailing(changes []TableChange) int {
+	n := 0
+	for _, c := range changes {
+		if c.Kind == ChangeNewlyFailing || c.Kind == ChangeAdded && c.After == StatusFailed {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteChanges writes one line per changed table followed by a summary line.
+func WriteChanges(w io.Writer, changes []TableChange) error {
+	var b strings.Builder
+	counts := make(map[string]int)
+	for _, c := range changes {
+		counts[c.Kind]++
+		fmt.Fprintf(&b, "%-14s %s", strings.ToUpper(c.Kind), c.Table)
+		switch c.Kind {
+		case ChangeNewlyFailing:
+			fmt.Fprintf(&b, ": %s", c.NewError)
+		case ChangeStillFailing:
+			if c.NewError != c.OldError {
+				fmt.Fprintf(&b, ": %s (was: %s)", c.NewError, c.OldError)
+			} else {
+				fmt.Fprintf(&b, ": %s", c.NewError)
+			}
+		case ChangeAdded:
+			fmt.Fprintf(&b, " (%s)", c.After)
+		case ChangeRemoved:
+			fmt.Fprintf(&b, " (was %s)", c.Before)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%d newly failing, %d newly passing, %d still failing\n",
+		NewlyFailing(changes), counts[ChangeNewlyPassing], counts[ChangeStillFailing])
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,171 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/nu0ma/spalidate/internal/validator"
+)
+
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+	// StatusExpectedFailure is a failing table marked with expectedFailure.
+	StatusExpectedFailure = "expectedFailure"
+)
+
+// Report is the JSON representation of a validation run.
+type Report struct {
+	Passed     bool          `json:"passed"`
+	StartedAt  time.Time     `json:"startedAt"`
+	DurationMs int64         `json:"durationMs"`
+	Tables     []TableReport `json:"tables"`
+	// Interrupted is set when the run was stopped before every table was validated.
+	Interrupted bool `json:"interrupted,omitempty"`
+	// Metadata identifies the run, so reports can be correlated with deployments.
+	Metadata *Metadata `json:"metadata,omitempty"`
+}
+
+// Metadata describes where and how a validation run happened.
+type Metadata struct {
+	// Labels are the --label key=value pairs of the run.
+	Labels   map[string]string `json:"labels,omitempty"`
+	Hostname string            `json:"hostname,omitempty"`
+	// ConfigHash is the hash of the resolved configuration.
+	ConfigHash string `json:"configHash,omitempty"`
+	// Database is the full database path.
+	Database string `json:"database,omitempty"`
+	// ReadTimestamp is the --as-of time of a stale read; empty for strong reads.
+	ReadTimestamp string `json:"readTimestamp,omitempty"`
+	// Restore is set when the run verified a database restored from a backup.
+	Restore *Restore `json:"restore,omitempty"`
+}
+
+// Restore identifies the backup behind a restore-verification run.
+type Restore struct {
+	Backup         string `json:"backup"`
+	SourceDatabase string `json:"sourceDatabase,omitempty"`
+	// VersionTime is the RFC 3339 time at which the backup's data is consistent.
+	VersionTime string `json:"versionTime,omitempty"`
+}
+
+// TableReport is the JSON representation of one table's outcome.
+type TableReport struct {
+	Table  string `json:"table"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Report string `json:"report,omitempty"`
+	// ExpectedFailure is the marker of a table whose failure was expected.
+	ExpectedFailure string `json:"expectedFailure,omitempty"`
+	// XFail and XPass list marked rows that failed as expected and markers that passed.
+	XFail []string `json:"xfail,omitempty"`
+	XPass []string `json:"xpass,omitempty"`
+	// Memory is set when the run recorded memory use; see validator.WithMemoryStats.
+	Memory *Memory `json:"memory,omitempty"`
+}
+
+// Memory is the memory used to validate a table.
+type Memory struct {
+	Rows int `json:"rows"`
+	// RowBytes estimates the size of the rows held in memory.
+	RowBytes int64 `json:"rowBytes"`
+	// PeakHeapBytes is the largest process heap size sampled during the table.
+	PeakHeapBytes uint64 `json:"peakHeapBytes"`
+}
+
+// FromResult builds a report from a validation result.
+func FromResult(res *validator.Result, startedAt time.Time, duration time.Duration) *Report {
+	r := &Report{
+		Passed:      res.Err() == nil,
+		StartedAt:   startedAt.UTC(),
+		DurationMs:  duration.Milliseconds(),
+		Tables:      make([]TableReport, 0, len(res.Tables)),
+		Interrupted: res.Interrupted,
+	}
+	for _, t := range res.Tables {
+		tr := TableReport{Table: t.Table, Status: StatusPassed, XFail: t.XFail, XPass: t.XPass}
+		if m := t.Memory; m != nil {
+			tr.Memory = &Memory{Rows: m.Rows, RowBytes: m.RowBytes, PeakHeapBytes: m.PeakHeapBytes}
+		}
+		switch {
+		case t.Skipped:
+			tr.Status = StatusSkipped
+		case t.ExpectedFailure != "":
+			tr.Status = StatusExpectedFailure
+			tr.ExpectedFailure = t.ExpectedFailure
+			tr.Error = t.Err.Error()
+			tr.Report = t.Report
+		case t.Err != nil:
+			tr.Status = StatusFailed
+			tr.Error = t.Err.Error()
+			tr.Report = t.Report
+		}
+		r.Tables = append(r.Tables, tr)
+	}
+	return r
+}
+
+// Load reads a JSON report from a file.
+func Load(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// WriteFile writes the report as indented JSON.
+func (r *Report) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// IndexFileName is the name of the index written next to per-target reports.
+const IndexFileName = "index.json"
+
+// TargetFileName returns the report file name used for a database in a multi-target run.
+func TargetFileName(database string) string {
+	return "report-" + database + ".json"
+}
+
+// Index summarizes the per-target reports of a run against several databases.
+type Index struct {
+	Passed  bool         `json:"passed"`
+	Targets []IndexEntry `json:"targets"`
+}
+
+// IndexEntry points at one target's report. File is empty when the target failed
+// before validation started; Error then explains why.
+type IndexEntry struct {
+	Database string `json:"database"`
+	Passed   bool   `json:"passed"`
+	File     string `json:"file,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Add records a target and updates the overall status.
+func (i *Index) Add(e IndexEntry) {
+	if len(i.Targets) == 0 {
+		i.Passed = true
+	}
+	i.Targets = append(i.Targets, e)
+	i.Passed = i.Passed && e.Passed
+}
+
+// WriteFile writes the index as indented JSON.
+func (i *Index) WriteFile(path string) error {
+	data, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
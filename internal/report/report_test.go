@@ -0,0 +1,102 @@
+package report
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nu0ma/spalidate/internal/validator"
+)
+
+func TestFromResult(t *testing.T) {
+	res := &validator.Result{Tables: []validator.TableResult{
+		{Table: "Books"},
+		{Table: "Users", Err: errors.New("row count mismatch")},
+		{Table: "Flags", Skipped: true},
+	}}
+	r := FromResult(res, time.Now(), time.Second)
+	if r.Passed {
+		t.Error("Expected report to fail")
+	}
+	want := []string{StatusPassed, StatusFailed, StatusSkipped}
+	for i, tr := range r.Tables {
+		if tr.Status != want[i] {
+			t.Errorf("table %s: expected status %s, got %s", tr.Table, want[i], tr.Status)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), TargetFileName("db1"))
+	if err := r.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Passed || len(loaded.Tables) != 3 {
+		t.Errorf("Unexpected loaded report: %+v", loaded)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	var idx Index
+	idx.Add(IndexEntry{Database: "db1", Passed: true, File: TargetFileName("db1")})
+	if !idx.Passed {
+		t.Error("Expected index with one passing target to pass")
+	}
+	idx.Add(IndexEntry{Database: "db2", Error: "creating spanner client: boom"})
+	if idx.Passed {
+		t.Error("Expected index with a failing target to fail")
+	}
+	if TargetFileName("db1") != "report-db1.json" {
+		t.Errorf("Unexpected target file name %s", TargetFileName("db1"))
+	}
+}
+
+func TestCompare(t *testing.T) {
+	old := &Report{Tables: []TableReport{
+		{Table: "Books", Status: StatusFailed, Error: "expected row 1 not found"},
+		{Table: "Orders", Status: StatusPassed},
+		{Table: "Legacy", Status: StatusPassed},
+		{Table: "Users", Status: StatusFailed, Error: "expected row 2 not found"},
+		{Table: "Flags", Status: StatusPassed},
+	}}
+	cur := &Report{Tables: []TableReport{
+		{Table: "Books", Status: StatusPassed},
+		{Table: "Orders", Status: StatusFailed, Error: "unexpected row count"},
+		{Table: "Users", Status: StatusFailed, Error: "expected row 3 not found"},
+		{Table: "Flags", Status: StatusPassed},
+		{Table: "Tags", Status: StatusFailed, Error: "unexpected rows present"},
+	}}
+
+	changes := Compare(old, cur)
+	want := map[string]string{
+		"Books":  ChangeNewlyPassing,
+		"Legacy": ChangeRemoved,
+		"Orders": ChangeNewlyFailing,
+		"Tags":   ChangeAdded,
+		"Users":  ChangeStillFailing,
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, got %+v", len(want), changes)
+	}
+	for _, c := range changes {
+		if want[c.Table] != c.Kind {
+			t.Errorf("table %s: expected %q, got %q", c.Table, want[c.Table], c.Kind)
+		}
+	}
+	if n := NewlyFailing(changes); n != 2 {
+		t.Errorf("Expected 2 newly failing tables, got %d", n)
+	}
+
+	var b strings.Builder
+	if err := WriteChanges(&b, changes); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "expected row 3 not found (was: expected row 2 not found)") ||
+		!strings.HasSuffix(b.String(), "2 newly failing, 1 newly passing, 1 still failing\n") {
+		t.Errorf("Unexpected output:\n%s", b.String())
+	}
+}
@@ -0,0 +1,61 @@
+package repro
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/validator"
+	"gopkg.in/yaml.v3"
+)
+
+// Write stores a bundle describing a failed validation run in dir, so the failure can be
+// inspected without database access. The bundle contains:
+//
+//	config.yaml       the effective configuration
+//	report.txt        the errors and mismatch reports of failing tables
+//	schema.sql        the database DDL (omitted when ddl is nil)
+//	rows/<table>.json the actual rows of each failing table
+func Write(dir string, cfg *config.Config, res *validator.Result, ddl []string) error {
+	if err := os.MkdirAll(filepath.Join(dir, "rows"), 0o755); err != nil {
+		return fmt.Errorf("failed to create repro bundle directory: %w", err)
+	}
+
+	cfgData, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), cfgData, 0o644); err != nil {
+		return err
+	}
+
+	var report strings.Builder
+	for _, t := range res.Failed() {
+		fmt.Fprintf(&report, "table %s: %v\n", t.Table, t.Err)
+		if t.Report != "" {
+			fmt.Fprintf(&report, "%s\n", t.Report)
+		}
+
+		rows, err := json.MarshalIndent(t.Rows, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal rows of table %s: %w", t.Table, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "rows", t.Table+".json"), rows, 0o644); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "report.txt"), []byte(report.String()), 0o644); err != nil {
+		return err
+	}
+
+	if ddl != nil {
+		schema := strings.Join(ddl, ";\n\n") + ";\n"
+		if err := os.WriteFile(filepath.Join(dir, "schema.sql"), []byte(schema), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
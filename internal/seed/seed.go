@@ -0,0 +1,91 @@
+// Package seed turns the seed section of a config into Spanner mutations.
+package seed
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/nu0ma/spalidate/internal/config"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Mutations builds the mutations for every seeded row, in config order.
+func Mutations(s *config.Seed) ([]*spanner.Mutation, error) {
+	var ms []*spanner.Mutation
+	for i, m := range s.Mutations {
+		for ri, row := range m.Rows {
+			cols := make([]string, 0, len(row))
+			for col := range row {
+				cols = append(cols, col)
+			}
+			sort.Strings(cols)
+			vals := make([]any, len(cols))
+			for ci, col := range cols {
+				pv, err := protoValue(row[col])
+				if err != nil {
+					return nil, fmt.Errorf("seed mutation %d (%s) row %d column %s: %w", i+1, m.Table, ri+1, col, err)
+				}
+				vals[ci] = spanner.GenericColumnValue{Type: &sppb.Type{}, Value: pv}
+			}
+			switch m.Op {
+			case "", config.SeedInsert:
+				ms = append(ms, spanner.Insert(m.Table, cols, vals))
+			case config.SeedInsertOrUpdate:
+				ms = append(ms, spanner.InsertOrUpdate(m.Table, cols, vals))
+			case config.SeedReplace:
+				ms = append(ms, spanner.Replace(m.Table, cols, vals))
+			case config.SeedUpdate:
+				ms = append(ms, spanner.Update(m.Table, cols, vals))
+			default:
+				return nil, fmt.Errorf("seed mutation %d (%s): unknown op %q", i+1, m.Table, m.Op)
+			}
+		}
+	}
+	return ms, nil
+}
+
+// protoValue encodes a YAML value in the wire format Spanner expects for mutations:
+// integers as decimal strings, arrays as lists and mappings as JSON strings.
+func protoValue(v any) (*structpb.Value, error) {
+	switch t := v.(type) {
+	case nil:
+		return structpb.NewNullValue(), nil
+	case string:
+		return structpb.NewStringValue(t), nil
+	case bool:
+		return structpb.NewBoolValue(t), nil
+	case int:
+		return structpb.NewStringValue(strconv.Itoa(t)), nil
+	case int64:
+		return structpb.NewStringValue(strconv.FormatInt(t, 10)), nil
+	case uint64:
+		return structpb.NewStringValue(strconv.FormatUint(t, 10)), nil
+	case float64:
+		return structpb.NewNumberValue(t), nil
+	case time.Time:
+		return structpb.NewStringValue(t.UTC().Format(time.RFC3339Nano)), nil
+	case []any:
+		list := &structpb.ListValue{Values: make([]*structpb.Value, len(t))}
+		for i, e := range t {
+			pv, err := protoValue(e)
+			if err != nil {
+				return nil, err
+			}
+			list.Values[i] = pv
+		}
+		return structpb.NewListValue(list), nil
+	case map[string]any:
+		b, err := json.Marshal(t)
+		if err != nil {
+			return nil, err
+		}
+		return structpb.NewStringValue(string(b)), nil
+	default:
+		return nil, fmt.Errorf("unsupported seed value %v (%T)", v, v)
+	}
+}
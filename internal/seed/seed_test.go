@@ -0,0 +1,60 @@
+package seed
+
+import (
+	"testing"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestProtoValue(t *testing.T) {
+	tests := []struct {
+		in   any
+		want *structpb.Value
+	}{
+		{nil, structpb.NewNullValue()},
+		{"alice", structpb.NewStringValue("alice")},
+		{42, structpb.NewStringValue("42")},
+		{int64(-7), structpb.NewStringValue("-7")},
+		{1.5, structpb.NewNumberValue(1.5)},
+		{true, structpb.NewBoolValue(true)},
+		{map[string]any{"a": 1}, structpb.NewStringValue(`{"a":1}`)},
+	}
+	for _, tt := range tests {
+		got, err := protoValue(tt.in)
+		if err != nil {
+			t.Errorf("protoValue(%v) failed: %v", tt.in, err)
+			continue
+		}
+		if got.String() != tt.want.String() {
+			t.Errorf("protoValue(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	list, err := protoValue([]any{"a", 1})
+	if err != nil {
+		t.Fatalf("protoValue(list) failed: %v", err)
+	}
+	if vals := list.GetListValue().GetValues(); len(vals) != 2 || vals[1].GetStringValue() != "1" {
+		t.Errorf("Unexpected list encoding: %v", list)
+	}
+}
+
+func TestMutations(t *testing.T) {
+	s := &config.Seed{Mutations: []config.SeedMutation{
+		{Table: "Users", Rows: []map[string]any{{"UserID": "u1"}, {"UserID": "u2"}}},
+		{Table: "Users", Op: config.SeedUpdate, Rows: []map[string]any{{"UserID": "u1", "Name": "Alice"}}},
+	}}
+	ms, err := Mutations(s)
+	if err != nil {
+		t.Fatalf("Mutations failed: %v", err)
+	}
+	if len(ms) != 3 {
+		t.Errorf("Expected 3 mutations, got %d", len(ms))
+	}
+
+	s.Mutations[0].Rows[0]["Bad"] = struct{}{}
+	if _, err := Mutations(s); err == nil {
+		t.Error("Expected unsupported value to fail")
+	}
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/report"
+)
+
+// Server exposes validation results over HTTP:
+//
+//	GET  /healthz      200 when the latest run passed, 503 when it failed or none has finished yet
+//	GET  /livez        200 while the process is running
+//	GET  /readyz       200 until shutdown starts, then 503
+//	GET  /report.json  the latest report
+//	POST /validate     validates the YAML/JSON config in the request body (only when Validate is set);
+//	                   see config.ParseUntrusted for what such configs may not use
+type Server struct {
+	// Validate runs a validation for a config submitted to POST /validate.
+	Validate func(ctx context.Context, cfg *config.Config) *report.Report
+
+	mu       sync.RWMutex
+	latest   *report.Report
+	draining bool
+}
+
+func New() *Server {
+	return &Server{}
+}
+
+// Update replaces the latest report.
+func (s *Server) Update(r *report.Report) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latest = r
+}
+
+// SetDraining marks the server as shutting down, so /readyz reports it as not ready.
+func (s *Server) SetDraining() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.draining = true
+}
+
+// Latest returns the most recent report, or nil if no run has finished.
+func (s *Server) Latest() *report.Report {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.latest
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.HandleFunc("GET /report.json", s.handleReport)
+	mux.HandleFunc("GET /livez", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /readyz", s.handleReadyz)
+	if s.Validate != nil {
+		mux.HandleFunc("POST /validate", s.handleValidate)
+	}
+	return mux
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	latest := s.Latest()
+	switch {
+	case latest == nil:
+		http.Error(w, "no validation run finished yet", http.StatusServiceUnavailable)
+	case !latest.Passed:
+		http.Error(w, "validation failed", http.StatusServiceUnavailable)
+	default:
+		_, _ = w.Write([]byte("ok\n"))
+	}
+}
+
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	draining := s.draining
+	s.mu.RUnlock()
+	if draining {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	latest := s.Latest()
+	if latest == nil {
+		http.Error(w, "no validation run finished yet", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, latest)
+}
+
+// maxConfigSize bounds the config payload accepted by POST /validate.
+const maxConfigSize = 10 << 20
+
+func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxConfigSize))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorBody{Error: fmt.Sprintf("failed to read request body: %v", err)})
+		return
+	}
+	cfg, err := config.ParseUntrusted(data)
+	if err == nil {
+		err = cfg.SelectDataset(r.URL.Query().Get("dataset"))
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorBody{Error: err.Error()})
+		return
+	}
+
+	rep := s.Validate(r.Context(), cfg)
+	s.Update(rep)
+	status := http.StatusOK
+	if !rep.Passed {
+		status = http.StatusUnprocessableEntity
+	}
+	writeJSON(w, status, rep)
+}
+
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
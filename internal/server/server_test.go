@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/report"
+)
+
+func TestValidateEndpoint(t *testing.T) {
+	srv := New()
+	srv.Validate = func(ctx context.Context, cfg *config.Config) *report.Report {
+		_, ok := cfg.Tables["Users"]
+		return &report.Report{Passed: ok}
+	}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before any run, got %d", resp.StatusCode)
+	}
+
+	tests := []struct {
+		body string
+		want int
+	}{
+		{"tables:\n  Users:\n    columns:\n      - UserID: \"user-001\"\n", http.StatusOK},
+		{"tables:\n  Products: {}\n", http.StatusUnprocessableEntity},
+		{"tables: [", http.StatusBadRequest},
+		{"tables:\n  Users:\n    source: {path: /etc/passwd, format: csv}\n", http.StatusBadRequest},
+		{"tables: {}\nchangeStreams:\n  'S(start_timestamp => NULL) --':\n    window: 1m\n    records: [{table: Users}]\n", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		resp, err := http.Post(ts.URL+"/validate", "application/yaml", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("POST /validate %q: expected %d, got %d", tt.body, tt.want, resp.StatusCode)
+		}
+	}
+
+	resp, err = http.Get(ts.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 after a failing run, got %d", resp.StatusCode)
+	}
+}
+
+func TestProbes(t *testing.T) {
+	srv := New()
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	get := func(path string) int {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if got := get("/livez"); got != http.StatusOK {
+		t.Errorf("Expected /livez 200, got %d", got)
+	}
+	if got := get("/readyz"); got != http.StatusOK {
+		t.Errorf("Expected /readyz 200, got %d", got)
+	}
+	srv.SetDraining()
+	if got := get("/readyz"); got != http.StatusServiceUnavailable {
+		t.Errorf("Expected /readyz 503 while draining, got %d", got)
+	}
+	if got := get("/livez"); got != http.StatusOK {
+		t.Errorf("Expected /livez 200 while draining, got %d", got)
+	}
+}
@@ -4,37 +4,622 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
+	"sync"
+	"time"
 
 	"cloud.google.com/go/spanner"
+	database "cloud.google.com/go/spanner/admin/database/apiv1"
+	"cloud.google.com/go/spanner/admin/database/apiv1/databasepb"
+	"github.com/nu0ma/spalidate/internal/errkind"
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 type Client struct {
 	spannerClient *spanner.Client
+	database      string
+	clientOpts    []option.ClientOption
+	readTimestamp time.Time
+	queryTimeout  time.Duration
+	emulator      bool
+	// emulatorHost is Options.EmulatorHost; empty when SPANNER_EMULATOR_HOST selects it.
+	emulatorHost string
+	// dialect is set from Options or detected on first use; see Dialect.
+	dialectMu sync.Mutex
+	dialect   Dialect
 }
 
 type Options struct {
+	// EmulatorHost connects to an emulator at host:port without touching SPANNER_EMULATOR_HOST,
+	// so several clients in one process can target different backends.
 	EmulatorHost string
+	// ReadTimestamp performs data queries as stale reads at this time; zero means strong reads.
+	ReadTimestamp time.Time
+	// QueryTimeout bounds each individual query; zero means no limit.
+	QueryTimeout time.Duration
+	// Endpoint overrides the Cloud Spanner API endpoint (host:port), e.g. a regional or
+	// private endpoint. Connections use TLS and are authenticated; ignored with EmulatorHost.
+	Endpoint string
+	// CredentialsFile authenticates with this service account or external account key file
+	// instead of Application Default Credentials; ignored with EmulatorHost.
+	CredentialsFile string
+	// ImpersonateServiceAccount acts as this service account (by email), using the other
+	// credentials only to mint its tokens; ignored with EmulatorHost.
+	ImpersonateServiceAccount string
+	// Dialect is the database's SQL dialect; empty detects it on first use.
+	Dialect Dialect
 }
 
+// cloudPlatformScope is requested for impersonated tokens; it covers data and admin APIs.
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
 func NewClient(ctx context.Context, projectID, instanceID, databaseID string, opts ...Options) (*Client, error) {
-	if len(opts) > 0 && opts[0].EmulatorHost != "" {
-		if os.Getenv("SPANNER_EMULATOR_HOST") == "" {
-			if err := os.Setenv("SPANNER_EMULATOR_HOST", opts[0].EmulatorHost); err != nil {
-				return nil, fmt.Errorf("failed to set SPANNER_EMULATOR_HOST: %w", err)
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	var clientOpts []option.ClientOption
+	cfg := spanner.ClientConfig{}
+	emulator := o.EmulatorHost != "" || os.Getenv("SPANNER_EMULATOR_HOST") != ""
+	if o.EmulatorHost != "" {
+		clientOpts = emulatorClientOptions(o.EmulatorHost)
+		cfg.DisableNativeMetrics = true
+	} else if !emulator {
+		var err error
+		if clientOpts, err = cloudClientOptions(ctx, o); err != nil {
+			return nil, errkind.Mark(err, errkind.ErrConnection)
+		}
+	}
+
+	db := fmt.Sprintf("projects/%s/instances/%s/databases/%s", projectID, instanceID, databaseID)
+	spannerClient, err := spanner.NewClientWithConfig(ctx, db, cfg, clientOpts...)
+	if err != nil {
+		return nil, errkind.Mark(err, errkind.ErrConnection)
+	}
+	c := &Client{
+		spannerClient: spannerClient,
+		database:      db,
+		clientOpts:    clientOpts,
+		readTimestamp: o.ReadTimestamp,
+		queryTimeout:  o.QueryTimeout,
+		emulator:      emulator,
+		emulatorHost:  o.EmulatorHost,
+		dialect:       o.Dialect,
+	}
+	return c, err
+}
+
+// Wrap returns a Client reading through an existing client, e.g. one owned by a test. Only
+// the query options of o apply; the connection is the caller's, so admin operations use
+// Application Default Credentials, or the emulator when SPANNER_EMULATOR_HOST is set.
+func Wrap(client *spanner.Client, o Options) *Client {
+	return &Client{
+		spannerClient: client,
+		database:      client.DatabaseName(),
+		readTimestamp: o.ReadTimestamp,
+		queryTimeout:  o.QueryTimeout,
+		emulator:      os.Getenv("SPANNER_EMULATOR_HOST") != "",
+		dialect:       o.Dialect,
+	}
+}
+
+// cloudClientOptions selects the endpoint and credentials of a Cloud Spanner connection;
+// the client library defaults to the global endpoint and Application Default Credentials.
+func cloudClientOptions(ctx context.Context, o Options) ([]option.ClientOption, error) {
+	var clientOpts, credOpts []option.ClientOption
+	if o.Endpoint != "" {
+		clientOpts = append(clientOpts, option.WithEndpoint(o.Endpoint))
+	}
+	if o.CredentialsFile != "" {
+		credOpts = append(credOpts, option.WithCredentialsFile(o.CredentialsFile))
+	}
+	if o.ImpersonateServiceAccount == "" {
+		return append(clientOpts, credOpts...), nil
+	}
+	ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+		TargetPrincipal: o.ImpersonateServiceAccount,
+		Scopes:          []string{cloudPlatformScope},
+	}, credOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("impersonating %s: %w", o.ImpersonateServiceAccount, err)
+	}
+	return append(clientOpts, option.WithTokenSource(ts)), nil
+}
+
+// emulatorClientOptions connects API clients to an emulator without credentials.
+func emulatorClientOptions(host string) []option.ClientOption {
+	return []option.ClientOption{
+		option.WithEndpoint(host),
+		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		option.WithoutAuthentication(),
+	}
+}
+
+func (c *Client) Query(ctx context.Context, sql string) *spanner.RowIterator {
+	return c.QueryWithParams(ctx, sql, nil)
+}
+
+// QueryWithParams runs a query binding params as statement parameters: `@name`, or `$1`,
+// `$2`, ... bound from p1, p2, ... on PostgreSQL-dialect databases.
+func (c *Client) QueryWithParams(ctx context.Context, sql string, params map[string]any) *spanner.RowIterator {
+	stmt := spanner.Statement{SQL: sql, Params: params}
+	return c.single().Query(ctx, stmt)
+}
+
+// QueryTimeout returns the per-query limit, or zero when queries are unbounded.
+func (c *Client) QueryTimeout() time.Duration {
+	return c.queryTimeout
+}
+
+// WithQueryTimeout derives a context bounded by the per-query limit. The iterator of a
+// query started with it must be fully consumed before cancel is called.
+func (c *Client) WithQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.queryTimeout)
+}
+
+// single returns a single-use read-only transaction honoring the configured read timestamp.
+func (c *Client) single() *spanner.ReadOnlyTransaction {
+	tx := c.spannerClient.Single()
+	if !c.readTimestamp.IsZero() {
+		tx = tx.WithTimestampBound(spanner.ReadTimestamp(c.readTimestamp))
+	}
+	return tx
+}
+
+// PrimaryKeyColumns returns the primary key columns of a table in key order.
+func (c *Client) PrimaryKeyColumns(ctx context.Context, table string) ([]string, error) {
+	dialect, err := c.Dialect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stmt := dialect.schemaStatement(`SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.INDEX_COLUMNS
+WHERE TABLE_SCHEMA = {schema} AND TABLE_NAME = {table} AND INDEX_TYPE = 'PRIMARY_KEY'
+ORDER BY ORDINAL_POSITION`, table)
+	ctx, cancel := c.WithQueryTimeout(ctx)
+	defer cancel()
+	iter := c.spannerClient.Single().Query(ctx, stmt)
+	defer iter.Stop()
+
+	var cols []string
+	err = iter.Do(func(row *spanner.Row) error {
+		var name string
+		if err := row.Column(0, &name); err != nil {
+			return err
+		}
+		cols = append(cols, name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read primary key of %s: %w", table, Classify(err))
+	}
+	if len(cols) == 0 {
+		return nil, errkind.Mark(fmt.Errorf("table %s not found or has no primary key", table), errkind.ErrTableNotFound)
+	}
+	return cols, nil
+}
+
+// DefaultVersionRetention is the version retention period of databases that do not set one.
+const DefaultVersionRetention = time.Hour
+
+// VersionRetention returns the database's version_retention_period, the oldest age at
+// which stale reads are possible.
+func (c *Client) VersionRetention(ctx context.Context) (time.Duration, error) {
+	dialect, err := c.Dialect(ctx)
+	if err != nil {
+		return 0, err
+	}
+	stmt := dialect.schemaStatement(`SELECT OPTION_VALUE FROM INFORMATION_SCHEMA.DATABASE_OPTIONS
+WHERE SCHEMA_NAME = {schema} AND OPTION_NAME = 'version_retention_period'`, "")
+	ctx, cancel := c.WithQueryTimeout(ctx)
+	defer cancel()
+	iter := c.spannerClient.Single().Query(ctx, stmt)
+	defer iter.Stop()
+
+	retention := DefaultVersionRetention
+	err = iter.Do(func(row *spanner.Row) error {
+		var value string
+		if err := row.Column(0, &value); err != nil {
+			return err
+		}
+		d, err := parseRetention(value)
+		retention = d
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read version retention period: %w", Classify(err))
+	}
+	return retention, nil
+}
+
+// DatabaseOptions returns the options set on the database, such as default_leader, by name.
+func (c *Client) DatabaseOptions(ctx context.Context) (map[string]string, error) {
+	dialect, err := c.Dialect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stmt := dialect.schemaStatement(`SELECT OPTION_NAME, OPTION_VALUE FROM INFORMATION_SCHEMA.DATABASE_OPTIONS
+WHERE SCHEMA_NAME = {schema}`, "")
+	ctx, cancel := c.WithQueryTimeout(ctx)
+	defer cancel()
+	iter := c.single().Query(ctx, stmt)
+	defer iter.Stop()
+
+	opts := map[string]string{}
+	err = iter.Do(func(row *spanner.Row) error {
+		var name, value string
+		if err := row.Columns(&name, &value); err != nil {
+			return err
+		}
+		opts[name] = value
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database options: %w", Classify(err))
+	}
+	return opts, nil
+}
+
+// parseRetention parses a retention period such as "7d", "1h", "90m" or "3600s".
+func parseRetention(s string) (time.Duration, error) {
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'h': time.Hour, 'm': time.Minute, 's': time.Second}
+	if len(s) < 2 || units[s[len(s)-1]] == 0 {
+		return 0, fmt.Errorf("invalid retention period %q", s)
+	}
+	n, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid retention period %q", s)
+	}
+	return time.Duration(n) * units[s[len(s)-1]], nil
+}
+
+// Index describes a secondary index.
+type Index struct {
+	Name string
+	// Columns are the index key columns in key order.
+	Columns []string
+	// NullFiltered indexes omit rows where any key column is NULL.
+	NullFiltered bool
+}
+
+// SecondaryIndexes returns the secondary indexes of a table, sorted by name.
+func (c *Client) SecondaryIndexes(ctx context.Context, table string) ([]Index, error) {
+	dialect, err := c.Dialect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stmt := dialect.schemaStatement(`SELECT i.INDEX_NAME, i.IS_NULL_FILTERED, c.COLUMN_NAME
+FROM INFORMATION_SCHEMA.INDEXES AS i
+JOIN INFORMATION_SCHEMA.INDEX_COLUMNS AS c
+  ON c.TABLE_SCHEMA = i.TABLE_SCHEMA AND c.TABLE_NAME = i.TABLE_NAME AND c.INDEX_NAME = i.INDEX_NAME
+WHERE i.TABLE_SCHEMA = {schema} AND i.TABLE_NAME = {table} AND i.INDEX_TYPE = 'INDEX'
+  AND c.ORDINAL_POSITION IS NOT NULL
+ORDER BY i.INDEX_NAME, c.ORDINAL_POSITION`, table)
+	ctx, cancel := c.WithQueryTimeout(ctx)
+	defer cancel()
+	iter := c.single().Query(ctx, stmt)
+	defer iter.Stop()
+
+	var indexes []Index
+	err = iter.Do(func(row *spanner.Row) error {
+		var (
+			name, column string
+			nullFiltered bool
+		)
+		if dialect == DialectPostgreSQL {
+			// PostgreSQL reports IS_NULL_FILTERED as 'YES' or 'NO'
+			var filtered string
+			if err := row.Columns(&name, &filtered, &column); err != nil {
+				return err
+			}
+			nullFiltered = filtered == "YES"
+		} else if err := row.Columns(&name, &nullFiltered, &column); err != nil {
+			return err
+		}
+		if n := len(indexes); n == 0 || indexes[n-1].Name != name {
+			indexes = append(indexes, Index{Name: name, NullFiltered: nullFiltered})
+		}
+		last := &indexes[len(indexes)-1]
+		last.Columns = append(last.Columns, column)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes of %s: %w", table, Classify(err))
+	}
+	return indexes, nil
+}
+
+// GeneratedColumn describes a generated column.
+type GeneratedColumn struct {
+	// Expression is the column's expression as stored in the schema.
+	Expression string
+	// Stored is set for STORED columns, whose values are written on commit.
+	Stored bool
+}
+
+// GeneratedColumns returns the generated columns of a table by name.
+func (c *Client) GeneratedColumns(ctx context.Context, table string) (map[string]GeneratedColumn, error) {
+	dialect, err := c.Dialect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stmt := dialect.schemaStatement(`SELECT COLUMN_NAME, GENERATION_EXPRESSION, IS_STORED FROM INFORMATION_SCHEMA.COLUMNS
+WHERE TABLE_SCHEMA = {schema} AND TABLE_NAME = {table} AND GENERATION_EXPRESSION IS NOT NULL`, table)
+	ctx, cancel := c.WithQueryTimeout(ctx)
+	defer cancel()
+	iter := c.single().Query(ctx, stmt)
+	defer iter.Stop()
+
+	cols := map[string]GeneratedColumn{}
+	err = iter.Do(func(row *spanner.Row) error {
+		var (
+			name, expr string
+			stored     spanner.NullString
+		)
+		if err := row.Columns(&name, &expr, &stored); err != nil {
+			return err
+		}
+		cols[name] = GeneratedColumn{Expression: expr, Stored: stored.StringVal == "YES"}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read generated columns of %s: %w", table, Classify(err))
+	}
+	return cols, nil
+}
+
+// CheckConstraints returns the CHECK constraints of a table, mapping names to expressions.
+func (c *Client) CheckConstraints(ctx context.Context, table string) (map[string]string, error) {
+	dialect, err := c.Dialect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stmt := dialect.schemaStatement(`SELECT cc.CONSTRAINT_NAME, cc.CHECK_CLAUSE
+FROM INFORMATION_SCHEMA.CHECK_CONSTRAINTS AS cc
+JOIN INFORMATION_SCHEMA.TABLE_CONSTRAINTS AS tc
+  ON tc.CONSTRAINT_SCHEMA = cc.CONSTRAINT_SCHEMA AND tc.CONSTRAINT_NAME = cc.CONSTRAINT_NAME
+WHERE tc.TABLE_SCHEMA = {schema} AND tc.TABLE_NAME = {table} AND tc.CONSTRAINT_TYPE = 'CHECK'`, table)
+	ctx, cancel := c.WithQueryTimeout(ctx)
+	defer cancel()
+	iter := c.single().Query(ctx, stmt)
+	defer iter.Stop()
+
+	checks := map[string]string{}
+	err = iter.Do(func(row *spanner.Row) error {
+		var name, clause string
+		if err := row.Columns(&name, &clause); err != nil {
+			return err
+		}
+		checks[name] = clause
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read check constraints of %s: %w", table, Classify(err))
+	}
+	return checks, nil
+}
+
+// DataChange is a row change read from a change stream.
+type DataChange struct {
+	CommitTimestamp time.Time
+	Table           string
+	ModType         string
+	// Keys holds the primary key of the changed row, as encoded by the change stream
+	// (INT64 values are strings).
+	Keys map[string]any
+}
+
+type changeRecord struct {
+	DataChangeRecord      []*dataChangeRecord      `spanner:"data_change_record"`
+	ChildPartitionsRecord []*childPartitionsRecord `spanner:"child_partitions_record"`
+}
+
+type dataChangeRecord struct {
+	CommitTimestamp time.Time `spanner:"commit_timestamp"`
+	TableName       string    `spanner:"table_name"`
+	ModType         string    `spanner:"mod_type"`
+	Mods            []*struct {
+		Keys spanner.NullJSON `spanner:"keys"`
+	} `spanner:"mods"`
+}
+
+type childPartitionsRecord struct {
+	StartTimestamp  time.Time `spanner:"start_timestamp"`
+	ChildPartitions []*struct {
+		Token string `spanner:"token"`
+	} `spanner:"child_partitions"`
+}
+
+// ReadChangeStream returns the data changes committed to a change stream between start
+// and end, reading every partition of the window. Both times must be within the stream's
+// retention period, and end must not be in the future.
+func (c *Client) ReadChangeStream(ctx context.Context, stream string, start, end time.Time) ([]DataChange, error) {
+	if dialect, err := c.Dialect(ctx); err != nil {
+		return nil, err
+	} else if dialect == DialectPostgreSQL {
+		return nil, errPostgreSQLChangeStreams
+	}
+	type partition struct {
+		token string
+		start time.Time
+	}
+	var (
+		changes []DataChange
+		seen    = map[string]bool{}
+		// the first query, without a token, returns the initial partitions
+		queue = []partition{{start: start}}
+	)
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		params := map[string]any{"start": p.start, "end": end, "token": spanner.NullString{StringVal: p.token, Valid: p.token != ""}}
+		// the stream name is a plain identifier (the config rejects any other), quoted all the same
+		sql := fmt.Sprintf("SELECT ChangeRecord FROM `READ_%s`"+`(start_timestamp => @start, end_timestamp => @end,
+partition_token => @token, heartbeat_milliseconds => 10000)`, stream)
+		qctx, cancel := c.WithQueryTimeout(ctx)
+		iter := c.spannerClient.Single().Query(qctx, spanner.Statement{SQL: sql, Params: params})
+		err := iter.Do(func(row *spanner.Row) error {
+			var rec struct {
+				ChangeRecord []*changeRecord `spanner:"ChangeRecord"`
+			}
+			if err := row.ToStructLenient(&rec); err != nil {
+				return err
 			}
+			for _, r := range rec.ChangeRecord {
+				for _, d := range r.DataChangeRecord {
+					for _, m := range d.Mods {
+						keys, _ := m.Keys.Value.(map[string]any)
+						changes = append(changes, DataChange{CommitTimestamp: d.CommitTimestamp, Table: d.TableName, ModType: d.ModType, Keys: keys})
+					}
+				}
+				for _, cp := range r.ChildPartitionsRecord {
+					for _, child := range cp.ChildPartitions {
+						if !seen[child.Token] {
+							seen[child.Token] = true
+							queue = append(queue, partition{token: child.Token, start: cp.StartTimestamp})
+						}
+					}
+				}
+			}
+			return nil
+		})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read change stream %s: %w", stream, Classify(err))
 		}
 	}
+	return changes, nil
+}
 
-	spannerClient, err := spanner.NewClient(ctx, fmt.Sprintf("projects/%s/instances/%s/databases/%s", projectID, instanceID, databaseID))
+// TableNames returns the names of the user tables in the database, sorted.
+func (c *Client) TableNames(ctx context.Context) ([]string, error) {
+	dialect, err := c.Dialect(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{spannerClient: spannerClient}, err
+	stmt := dialect.schemaStatement(`SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES
+WHERE TABLE_SCHEMA = {schema} ORDER BY TABLE_NAME`, "")
+	ctx, cancel := c.WithQueryTimeout(ctx)
+	defer cancel()
+	iter := c.single().Query(ctx, stmt)
+	defer iter.Stop()
+
+	var names []string
+	err = iter.Do(func(row *spanner.Row) error {
+		var name string
+		if err := row.Column(0, &name); err != nil {
+			return err
+		}
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", Classify(err))
+	}
+	return names, nil
 }
 
-func (c *Client) Query(ctx context.Context, sql string) *spanner.RowIterator {
-	stmt := spanner.Statement{SQL: sql}
-	return c.spannerClient.Single().Query(ctx, stmt)
+// DatabaseDDL returns the DDL statements that define the database schema.
+func (c *Client) DatabaseDDL(ctx context.Context) ([]string, error) {
+	admin, err := database.NewDatabaseAdminClient(ctx, c.clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database admin client: %w", err)
+	}
+	defer func() { _ = admin.Close() }()
+
+	resp, err := admin.GetDatabaseDdl(ctx, &databasepb.GetDatabaseDdlRequest{Database: c.database})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database DDL: %w", err)
+	}
+	return resp.GetStatements(), nil
+}
+
+// RestoreInfo describes the backup a database was restored from.
+type RestoreInfo struct {
+	// Backup is the full backup path.
+	Backup         string
+	SourceDatabase string
+	// VersionTime is the time at which the backup's data is consistent.
+	VersionTime time.Time
+}
+
+// RestoreInfo returns the backup the database was restored from, or nil when it was not
+// restored from a backup.
+func (c *Client) RestoreInfo(ctx context.Context) (*RestoreInfo, error) {
+	admin, err := database.NewDatabaseAdminClient(ctx, c.clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database admin client: %w", err)
+	}
+	defer func() { _ = admin.Close() }()
+
+	db, err := admin.GetDatabase(ctx, &databasepb.GetDatabaseRequest{Name: c.database})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database: %w", err)
+	}
+	b := db.GetRestoreInfo().GetBackupInfo()
+	if b == nil {
+		return nil, nil
+	}
+	return &RestoreInfo{
+		Backup:         b.GetBackup(),
+		SourceDatabase: b.GetSourceDatabase(),
+		VersionTime:    b.GetVersionTime().AsTime(),
+	}, nil
+}
+
+// ExecuteDML runs a DML statement in its own read-write transaction and returns the row count.
+func (c *Client) ExecuteDML(ctx context.Context, sql string) (int64, error) {
+	ctx, cancel := c.WithQueryTimeout(ctx)
+	defer cancel()
+	var count int64
+	_, err := c.spannerClient.ReadWriteTransaction(ctx, func(ctx context.Context, tx *spanner.ReadWriteTransaction) error {
+		n, err := tx.Update(ctx, spanner.Statement{SQL: sql})
+		count = n
+		return err
+	})
+	return count, Classify(err)
+}
+
+// ExecutePartitionedDML runs a statement as partitioned DML, for large UPDATE or DELETE
+// statements, and returns the lower bound of the affected row count.
+func (c *Client) ExecutePartitionedDML(ctx context.Context, sql string) (int64, error) {
+	ctx, cancel := c.WithQueryTimeout(ctx)
+	defer cancel()
+	return c.spannerClient.PartitionedUpdate(ctx, spanner.Statement{SQL: sql})
+}
+
+// UpdateDDL applies schema statements and waits for them to complete.
+func (c *Client) UpdateDDL(ctx context.Context, statements []string) error {
+	admin, err := database.NewDatabaseAdminClient(ctx, c.clientOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create database admin client: %w", err)
+	}
+	defer func() { _ = admin.Close() }()
+
+	op, err := admin.UpdateDatabaseDdl(ctx, &databasepb.UpdateDatabaseDdlRequest{
+		Database:   c.database,
+		Statements: statements,
+	})
+	if err != nil {
+		return err
+	}
+	return op.Wait(ctx)
+}
+
+// IsEmulator reports whether the client talks to the Spanner emulator.
+func (c *Client) IsEmulator() bool {
+	return c.emulator
+}
+
+// Apply writes the mutations in a single read-write transaction.
+func (c *Client) Apply(ctx context.Context, ms []*spanner.Mutation) error {
+	_, err := c.spannerClient.Apply(ctx, ms)
+	return Classify(err)
 }
 
 func (c *Client) Close() {
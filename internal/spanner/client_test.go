@@ -0,0 +1,71 @@
+package spanner
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithQueryTimeout(t *testing.T) {
+	c := &Client{}
+	ctx, cancel := c.WithQueryTimeout(context.Background())
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("Expected no deadline without a query timeout")
+	}
+	cancel()
+
+	c.queryTimeout = time.Minute
+	ctx, cancel = c.WithQueryTimeout(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > time.Minute {
+		t.Errorf("Expected a deadline within a minute, got %v (ok=%v)", deadline, ok)
+	}
+}
+
+func TestParseRetention(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"1h", time.Hour},
+		{"7d", 7 * 24 * time.Hour},
+		{"90m", 90 * time.Minute},
+		{"3600s", time.Hour},
+		{"", 0},
+		{"h", 0},
+		{"1w", 0},
+		{"-1h", 0},
+		{"1.5h", 0},
+	}
+	for _, tt := range tests {
+		got, err := parseRetention(tt.in)
+		if tt.want == 0 {
+			if err == nil {
+				t.Errorf("parseRetention(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseRetention(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestCloudClientOptions(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		opts Options
+		want int
+	}{
+		{Options{}, 0},
+		{Options{Endpoint: "spanner.example.com:443"}, 1},
+		{Options{Endpoint: "spanner.example.com:443", CredentialsFile: "sa.json"}, 2},
+	}
+	for _, tt := range tests {
+		got, err := cloudClientOptions(ctx, tt.opts)
+		if err != nil || len(got) != tt.want {
+			t.Errorf("%+v: expected %d options, got %d (%v)", tt.opts, tt.want, len(got), err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package spanner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+	"github.com/nu0ma/spalidate/internal/errkind"
+)
+
+// Dialect is the SQL dialect of a database, chosen when it is created.
+type Dialect string
+
+const (
+	DialectGoogleSQL  Dialect = "googlesql"
+	DialectPostgreSQL Dialect = "postgresql"
+)
+
+// ParseDialect parses a --dialect value; empty means detect it from the database.
+func ParseDialect(s string) (Dialect, error) {
+	switch strings.ToLower(s) {
+	case "":
+		return "", nil
+	case "googlesql", "google_standard_sql":
+		return DialectGoogleSQL, nil
+	case "postgresql", "postgres", "pg":
+		return DialectPostgreSQL, nil
+	}
+	return "", fmt.Errorf("unknown dialect %q (want googlesql or postgresql)", s)
+}
+
+// Ident renders a table or column name. PostgreSQL folds unquoted names to lower case, so
+// they are double-quoted, part by part for schema-qualified names; GoogleSQL names are
+// case-insensitive and are left as written.
+func (d Dialect) Ident(name string) string {
+	if d != DialectPostgreSQL {
+		return name
+	}
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = `"` + strings.ReplaceAll(p, `"`, `""`) + `"`
+	}
+	return strings.Join(parts, ".")
+}
+
+// ForceIndex renders a table read through one of its secondary indexes.
+func (d Dialect) ForceIndex(table, index string) string {
+	if d == DialectPostgreSQL {
+		return fmt.Sprintf("%s /*@ FORCE_INDEX=%s */", d.Ident(table), index)
+	}
+	return fmt.Sprintf("%s@{FORCE_INDEX=%s}", table, index)
+}
+
+// schemaStatement adapts an INFORMATION_SCHEMA query to the dialect: {schema} becomes the
+// default schema (empty in GoogleSQL, public in PostgreSQL) and {table} the table
+// parameter (@table, or $1 as PostgreSQL parameters are positional).
+func (d Dialect) schemaStatement(sql, table string) spanner.Statement {
+	schema, param, name := "''", "@table", "table"
+	if d == DialectPostgreSQL {
+		schema, param, name = "'public'", "$1", "p1"
+	}
+	stmt := spanner.Statement{SQL: strings.NewReplacer("{schema}", schema, "{table}", param).Replace(sql)}
+	if strings.Contains(sql, "{table}") {
+		stmt.Params = map[string]any{name: table}
+	}
+	return stmt
+}
+
+// Dialect returns the database's dialect: the one given in Options, else the one the
+// database reports, read once. The query is valid in both dialects.
+func (c *Client) Dialect(ctx context.Context) (Dialect, error) {
+	c.dialectMu.Lock()
+	defer c.dialectMu.Unlock()
+	if c.dialect != "" {
+		return c.dialect, nil
+	}
+	ctx, cancel := c.WithQueryTimeout(ctx)
+	defer cancel()
+	iter := c.spannerClient.Single().Query(ctx, spanner.Statement{
+		SQL: `SELECT OPTION_VALUE FROM INFORMATION_SCHEMA.DATABASE_OPTIONS WHERE OPTION_NAME = 'database_dialect'`,
+	})
+	defer iter.Stop()
+
+	// databases that do not report a dialect predate PostgreSQL support
+	dialect := DialectGoogleSQL
+	err := iter.Do(func(row *spanner.Row) error {
+		var value string
+		if err := row.Column(0, &value); err != nil {
+			return err
+		}
+		if value == "POSTGRESQL" {
+			dialect = DialectPostgreSQL
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to detect database dialect: %w", Classify(err))
+	}
+	c.dialect = dialect
+	return dialect, nil
+}
+
+// errPostgreSQLChangeStreams is returned for change stream reads on PostgreSQL databases,
+// whose change stream functions return JSON records.
+var errPostgreSQLChangeStreams = errkind.Mark(fmt.Errorf("change streams are not supported on PostgreSQL-dialect databases"), errkind.ErrConfig)
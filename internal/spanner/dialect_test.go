@@ -0,0 +1,65 @@
+package spanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDialect(t *testing.T) {
+	tests := map[string]Dialect{
+		"":                    "",
+		"googlesql":           DialectGoogleSQL,
+		"GOOGLE_STANDARD_SQL": DialectGoogleSQL,
+		"postgresql":          DialectPostgreSQL,
+		"pg":                  DialectPostgreSQL,
+	}
+	for in, want := range tests {
+		got, err := ParseDialect(in)
+		if err != nil || got != want {
+			t.Errorf("ParseDialect(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseDialect("mysql"); err == nil {
+		t.Error("Expected an unknown dialect to be rejected")
+	}
+}
+
+func TestDialectSQL(t *testing.T) {
+	tests := []struct {
+		dialect    Dialect
+		ident      string
+		forceIndex string
+		stmt       string
+		params     map[string]any
+	}{
+		{
+			dialect:    DialectGoogleSQL,
+			ident:      "sales.Orders",
+			forceIndex: "sales.Orders@{FORCE_INDEX=OrdersByUser}",
+			stmt:       "SELECT 1 FROM T WHERE TABLE_SCHEMA = '' AND TABLE_NAME = @table",
+			params:     map[string]any{"table": "sales.Orders"},
+		},
+		{
+			dialect:    DialectPostgreSQL,
+			ident:      `"sales"."Orders"`,
+			forceIndex: `"sales"."Orders" /*@ FORCE_INDEX=OrdersByUser */`,
+			stmt:       "SELECT 1 FROM T WHERE TABLE_SCHEMA = 'public' AND TABLE_NAME = $1",
+			params:     map[string]any{"p1": "sales.Orders"},
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.dialect.Ident("sales.Orders"); got != tt.ident {
+			t.Errorf("%s: Ident = %s, want %s", tt.dialect, got, tt.ident)
+		}
+		if got := tt.dialect.ForceIndex("sales.Orders", "OrdersByUser"); got != tt.forceIndex {
+			t.Errorf("%s: ForceIndex = %s, want %s", tt.dialect, got, tt.forceIndex)
+		}
+		stmt := tt.dialect.schemaStatement("SELECT 1 FROM T WHERE TABLE_SCHEMA = {schema} AND TABLE_NAME = {table}", "sales.Orders")
+		if stmt.SQL != tt.stmt || !reflect.DeepEqual(stmt.Params, tt.params) {
+			t.Errorf("%s: schemaStatement = %q %v, want %q %v", tt.dialect, stmt.SQL, stmt.Params, tt.stmt, tt.params)
+		}
+	}
+	if got := DialectPostgreSQL.Ident(`a"b`); got != `"a""b"` {
+		t.Errorf("Expected embedded quotes to be doubled, got %s", got)
+	}
+}
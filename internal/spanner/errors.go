@@ -0,0 +1,98 @@
+package spanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+	"github.com/nu0ma/spalidate/internal/errkind"
+	"google.golang.org/grpc/codes"
+)
+
+// Classify marks a Spanner error with its errkind: unreachable, unauthenticated or
+// unauthorized backends are ErrConnection, and queries of missing tables ErrTableNotFound.
+// Other errors are returned unchanged.
+func Classify(err error) error {
+	switch spanner.ErrCode(err) {
+	case codes.Unavailable, codes.Unauthenticated, codes.PermissionDenied:
+		return errkind.Mark(err, errkind.ErrConnection)
+	case codes.NotFound, codes.InvalidArgument:
+		if strings.Contains(spanner.ErrDesc(err), "Table not found") {
+			return errkind.Mark(err, errkind.ErrTableNotFound)
+		}
+	}
+	return err
+}
+
+// hintError is an error with a likely cause and remedy, shown on its own line.
+type hintError struct {
+	err  error
+	hint string
+}
+
+func (e *hintError) Error() string {
+	return e.err.Error() + "\nhint: " + e.hint
+}
+
+func (e *hintError) Unwrap() error { return e.err }
+
+// Diagnose classifies err like Classify and attaches a hint when it has a frequent root
+// cause: an emulator that is not running, SPANNER_EMULATOR_HOST pointing elsewhere, a
+// wrong instance or database ID, or a table named with the wrong case. table is the table
+// the failing operation read, or "".
+func (c *Client) Diagnose(ctx context.Context, err error, table string) error {
+	if err == nil {
+		return nil
+	}
+	err = Classify(err)
+	var he *hintError
+	if errors.As(err, &he) {
+		return err
+	}
+	if hint := c.hint(ctx, err, table); hint != "" {
+		return &hintError{err: err, hint: hint}
+	}
+	return err
+}
+
+func (c *Client) hint(ctx context.Context, err error, table string) string {
+	code := spanner.ErrCode(err)
+	switch {
+	case code == codes.Unavailable && c.emulator:
+		if c.emulatorHost != "" {
+			return fmt.Sprintf("no emulator answers at %s; start it, e.g. with `gcloud emulators spanner start`", c.emulatorHost)
+		}
+		return fmt.Sprintf("no emulator answers at %s, taken from SPANNER_EMULATOR_HOST; start the emulator there or unset the variable",
+			os.Getenv("SPANNER_EMULATOR_HOST"))
+	case code == codes.NotFound && strings.Contains(err.Error(), "Instance not found"):
+		if c.emulator {
+			return "the instance does not exist; the emulator starts empty, so create the instance and database first"
+		}
+		return "the instance does not exist; check the project and instance IDs"
+	case code == codes.NotFound && strings.Contains(err.Error(), "Database not found"):
+		if c.emulator {
+			return fmt.Sprintf("database %s does not exist; check the database ID, and note that the emulator forgets databases when it restarts", c.database)
+		}
+		return fmt.Sprintf("database %s does not exist; check the project, instance and database IDs", c.database)
+	case table != "" && errors.Is(err, errkind.ErrTableNotFound):
+		names, lerr := c.TableNames(ctx)
+		if lerr != nil {
+			return ""
+		}
+		return tableCaseHint(table, names)
+	}
+	return ""
+}
+
+// tableCaseHint suggests the existing table whose name differs from table only in case.
+func tableCaseHint(table string, names []string) string {
+	for _, name := range names {
+		if name != table && strings.EqualFold(name, table) {
+			return fmt.Sprintf("the table is named %s; names are matched case-sensitively", name)
+		}
+	}
+	return ""
+}
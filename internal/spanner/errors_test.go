@@ -0,0 +1,72 @@
+package spanner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDiagnose(t *testing.T) {
+	db := "projects/p/instances/i/databases/d"
+	tests := []struct {
+		name   string
+		client *Client
+		env    string
+		err    error
+		want   string
+	}{
+		{"emulator down", &Client{emulator: true, emulatorHost: "localhost:9010"},
+			"", status.Error(codes.Unavailable, "connection refused"), "no emulator answers at localhost:9010"},
+		{"emulator from env", &Client{emulator: true},
+			"localhost:9999", status.Error(codes.Unavailable, "connection refused"), "taken from SPANNER_EMULATOR_HOST"},
+		{"unavailable on Cloud Spanner", &Client{},
+			"", status.Error(codes.Unavailable, "connection refused"), ""},
+		{"wrong database", &Client{database: db},
+			"", status.Error(codes.NotFound, "Database not found: "+db), "database " + db + " does not exist"},
+		{"wrong instance on emulator", &Client{emulator: true},
+			"", status.Error(codes.NotFound, "Instance not found: projects/p/instances/i"), "the emulator starts empty"},
+		{"other error", &Client{emulator: true},
+			"", status.Error(codes.Internal, "boom"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SPANNER_EMULATOR_HOST", tt.env)
+			err := tt.client.Diagnose(context.Background(), tt.err, "")
+			_, hint, found := strings.Cut(err.Error(), "\nhint: ")
+			if tt.want == "" {
+				if found {
+					t.Errorf("Expected no hint, got %q", hint)
+				}
+				return
+			}
+			if !strings.Contains(hint, tt.want) {
+				t.Errorf("Expected hint containing %q, got %q", tt.want, err)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("Expected the hint to wrap the original error")
+			}
+			// a wrapped error keeps its single hint
+			again := tt.client.Diagnose(context.Background(), err, "")
+			if strings.Count(again.Error(), "hint:") != 1 {
+				t.Errorf("Expected one hint, got %q", again)
+			}
+		})
+	}
+	if (&Client{}).Diagnose(context.Background(), nil, "t") != nil {
+		t.Error("Expected nil for a nil error")
+	}
+}
+
+func TestTableCaseHint(t *testing.T) {
+	names := []string{"Orders", "Users"}
+	if got := tableCaseHint("users", names); !strings.Contains(got, "named Users") {
+		t.Errorf("Expected a hint naming Users, got %q", got)
+	}
+	if got := tableCaseHint("Accounts", names); got != "" {
+		t.Errorf("Expected no hint, got %q", got)
+	}
+}
@@ -0,0 +1,168 @@
+package spanner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	database "cloud.google.com/go/spanner/admin/database/apiv1"
+	"cloud.google.com/go/spanner/admin/database/apiv1/databasepb"
+	instance "cloud.google.com/go/spanner/admin/instance/apiv1"
+	"cloud.google.com/go/spanner/admin/instance/apiv1/instancepb"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ParseDDL splits a schema file into statements; see SplitStatements.
+func ParseDDL(schema string) []string {
+	return SplitStatements(schema)
+}
+
+// SplitStatements splits a SQL script, such as a DML file, into statements at semicolons
+// outside string literals, quoted identifiers and comments. Comments (`--`, `#` and
+// `/* */`) are dropped, and so are empty statements.
+func SplitStatements(script string) []string {
+	var statements []string
+	var b strings.Builder
+	flush := func() {
+		if stmt := strings.TrimSpace(b.String()); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		b.Reset()
+	}
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == ';':
+			flush()
+		case c == '#' || strings.HasPrefix(script[i:], "--"):
+			// line comment; the newline is kept
+			for i+1 < len(script) && script[i+1] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += 2 + end + 1
+			}
+			b.WriteByte(' ')
+		case c == '\'' || c == '"' || c == '`':
+			// a literal or quoted identifier, copied through its closing quote; triple quotes
+			// delimit GoogleSQL multi-line strings
+			quote := script[i : i+1]
+			if strings.HasPrefix(script[i:], strings.Repeat(quote, 3)) {
+				quote = strings.Repeat(quote, 3)
+			}
+			j := i + len(quote)
+			for j < len(script) && !strings.HasPrefix(script[j:], quote) {
+				if script[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+len(quote), len(script))
+			b.WriteString(script[i:j])
+			i = j - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+	flush()
+	return statements
+}
+
+// EnsureDatabase creates the emulator instance and database when they do not exist yet,
+// applying ddl to a newly created database. The database is created with the given
+// dialect, GoogleSQL when it is empty. An existing database is left untouched.
+// It reports whether the database was created.
+func EnsureDatabase(ctx context.Context, projectID, instanceID, databaseID, emulatorHost string, dialect Dialect, ddl []string) (bool, error) {
+	var clientOpts []option.ClientOption
+	if emulatorHost != "" {
+		clientOpts = emulatorClientOptions(emulatorHost)
+	}
+	if err := ensureInstance(ctx, projectID, instanceID, clientOpts); err != nil {
+		return false, err
+	}
+
+	admin, err := database.NewDatabaseAdminClient(ctx, clientOpts...)
+	if err != nil {
+		return false, fmt.Errorf("failed to create database admin client: %w", err)
+	}
+	defer func() { _ = admin.Close() }()
+
+	instanceName := fmt.Sprintf("projects/%s/instances/%s", projectID, instanceID)
+	_, err = admin.GetDatabase(ctx, &databasepb.GetDatabaseRequest{Name: instanceName + "/databases/" + databaseID})
+	if err == nil {
+		return false, nil
+	}
+	if status.Code(err) != codes.NotFound {
+		return false, fmt.Errorf("failed to get database: %w", err)
+	}
+
+	req := &databasepb.CreateDatabaseRequest{
+		Parent:          instanceName,
+		CreateStatement: fmt.Sprintf("CREATE DATABASE `%s`", databaseID),
+		ExtraStatements: ddl,
+	}
+	if dialect == DialectPostgreSQL {
+		// PostgreSQL databases take no extra statements; the schema is applied once created
+		req.CreateStatement = fmt.Sprintf("CREATE DATABASE %s", dialect.Ident(databaseID))
+		req.DatabaseDialect = databasepb.DatabaseDialect_POSTGRESQL
+		req.ExtraStatements = nil
+	}
+	op, err := admin.CreateDatabase(ctx, req)
+	if err != nil {
+		return false, fmt.Errorf("failed to create database: %w", err)
+	}
+	db, err := op.Wait(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to create database: %w", err)
+	}
+	if dialect == DialectPostgreSQL && len(ddl) > 0 {
+		ddlOp, err := admin.UpdateDatabaseDdl(ctx, &databasepb.UpdateDatabaseDdlRequest{Database: db.GetName(), Statements: ddl})
+		if err != nil {
+			return false, fmt.Errorf("failed to apply schema: %w", err)
+		}
+		if err := ddlOp.Wait(ctx); err != nil {
+			return false, fmt.Errorf("failed to apply schema: %w", err)
+		}
+	}
+	return true, nil
+}
+
+func ensureInstance(ctx context.Context, projectID, instanceID string, clientOpts []option.ClientOption) error {
+	admin, err := instance.NewInstanceAdminClient(ctx, clientOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create instance admin client: %w", err)
+	}
+	defer func() { _ = admin.Close() }()
+
+	name := fmt.Sprintf("projects/%s/instances/%s", projectID, instanceID)
+	_, err = admin.GetInstance(ctx, &instancepb.GetInstanceRequest{Name: name})
+	if err == nil {
+		return nil
+	}
+	if status.Code(err) != codes.NotFound {
+		return fmt.Errorf("failed to get instance: %w", err)
+	}
+
+	op, err := admin.CreateInstance(ctx, &instancepb.CreateInstanceRequest{
+		Parent:     "projects/" + projectID,
+		InstanceId: instanceID,
+		Instance: &instancepb.Instance{
+			Config:      fmt.Sprintf("projects/%s/instanceConfigs/emulator-config", projectID),
+			DisplayName: instanceID,
+			NodeCount:   1,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create instance: %w", err)
+	}
+	if _, err := op.Wait(ctx); err != nil {
+		return fmt.Errorf("failed to create instance: %w", err)
+	}
+	return nil
+}
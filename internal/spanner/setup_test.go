@@ -0,0 +1,53 @@
+package spanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDDL(t *testing.T) {
+	schema := `
+-- users
+CREATE TABLE Users (
+	UserID STRING(36) NOT NULL,
+) PRIMARY KEY (UserID);
+
+CREATE INDEX UsersByName ON Users(Name);
+CREATE TABLE Notes (ID INT64, Sep STRING(1) DEFAULT (';')) PRIMARY KEY (ID); -- trailing
+`
+	want := []string{
+		"CREATE TABLE Users (\n\tUserID STRING(36) NOT NULL,\n) PRIMARY KEY (UserID)",
+		"CREATE INDEX UsersByName ON Users(Name)",
+		"CREATE TABLE Notes (ID INT64, Sep STRING(1) DEFAULT (';')) PRIMARY KEY (ID)",
+	}
+	if got := ParseDDL(schema); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDDL() = %q, want %q", got, want)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{"plain", "UPDATE A SET x = 1;\nDELETE FROM B WHERE true;\n", []string{"UPDATE A SET x = 1", "DELETE FROM B WHERE true"}},
+		{"semicolon in string", `UPDATE A SET s = 'a;b' WHERE id = 1; UPDATE A SET s = "c;d" WHERE true`,
+			[]string{`UPDATE A SET s = 'a;b' WHERE id = 1`, `UPDATE A SET s = "c;d" WHERE true`}},
+		{"escaped quote", `UPDATE A SET s = 'it\'s; fine' WHERE true`, []string{`UPDATE A SET s = 'it\'s; fine' WHERE true`}},
+		{"doubled quote", `UPDATE A SET s = 'it''s; fine' WHERE true`, []string{`UPDATE A SET s = 'it''s; fine' WHERE true`}},
+		{"triple-quoted string", "UPDATE A SET s = '''x;\n'y''' WHERE true", []string{"UPDATE A SET s = '''x;\n'y''' WHERE true"}},
+		{"quoted identifier", "UPDATE `A;B` SET x = 1 WHERE true", []string{"UPDATE `A;B` SET x = 1 WHERE true"}},
+		{"comments", "-- step 1; not a statement\nUPDATE A SET x = 1 /* ; */ WHERE true; # done;\n",
+			[]string{"UPDATE A SET x = 1   WHERE true"}},
+		{"comment marker in string", `UPDATE A SET s = '--x' WHERE true`, []string{`UPDATE A SET s = '--x' WHERE true`}},
+		{"empty", "\n;;\n-- nothing\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
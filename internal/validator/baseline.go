@@ -0,0 +1,199 @@
+package validator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/logging"
+	spannerClient "github.com/nu0ma/spalidate/internal/spanner"
+)
+
+// Baseline records the content of tables at a point in time, so later runs can detect drift.
+type Baseline struct {
+	RecordedAt time.Time                `json:"recordedAt"`
+	Tables     map[string]TableSnapshot `json:"tables"`
+}
+
+// TableSnapshot holds the row count and the sorted per-row hashes of a table.
+type TableSnapshot struct {
+	Count     int      `json:"count"`
+	Digest    string   `json:"digest"`
+	RowHashes []string `json:"rowHashes"`
+}
+
+// RecordBaseline snapshots the given tables, or every table of the database when tables is empty.
+func RecordBaseline(ctx context.Context, client *spannerClient.Client, tables []string) (*Baseline, error) {
+	if len(tables) == 0 {
+		var err error
+		if tables, err = client.TableNames(ctx); err != nil {
+			return nil, err
+		}
+	}
+	v := NewValidator(&config.Config{}, client)
+	b := &Baseline{RecordedAt: time.Now().UTC(), Tables: make(map[string]TableSnapshot, len(tables))}
+	for _, table := range tables {
+		rows, err := v.fetchRows(ctx, table)
+		if err != nil {
+			return nil, fmt.Errorf("reading table %s: %w", table, err)
+		}
+		b.Tables[table] = snapshot(rows)
+	}
+	return b, nil
+}
+
+func snapshot(rows []map[string]any) TableSnapshot {
+	hashes := make([]string, len(rows))
+	for i, row := range rows {
+		sum := sha256.Sum256([]byte(canonicalRow(row)))
+		hashes[i] = hex.EncodeToString(sum[:16])
+	}
+	sort.Strings(hashes)
+	digest := sha256.Sum256([]byte(strings.Join(hashes, "\n")))
+	return TableSnapshot{Count: len(rows), Digest: hex.EncodeToString(digest[:]), RowHashes: hashes}
+}
+
+// canonicalRow encodes a row losslessly, as a JSON list of [column, value] pairs in column
+// order, with canonicalValue values and null for NULL.
+func canonicalRow(row map[string]any) string {
+	pairs := make([][2]any, 0, len(row))
+	for _, k := range sortedKeys(row) {
+		var value any
+		if !isNull(row[k]) {
+			value = canonicalValue(row[k])
+		}
+		pairs = append(pairs, [2]any{k, value})
+	}
+	b, _ := json.Marshal(pairs)
+	return string(b)
+}
+
+// LoadBaseline reads a baseline written by WriteFile.
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline: %w", err)
+	}
+	return &b, nil
+}
+
+// WriteFile writes the baseline as indented JSON.
+func (b *Baseline) WriteFile(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// TableDrift describes how a table changed since the baseline was recorded.
+type TableDrift struct {
+	Table string
+	// Missing is set when the table no longer exists.
+	Missing       bool
+	BaselineCount int
+	Count         int
+	// AddedRows and RemovedRows count rows whose hash appears on one side only;
+	// a modified row counts as one removed and one added row.
+	AddedRows   int
+	RemovedRows int
+}
+
+// Drifted reports whether the table differs from the baseline.
+func (d TableDrift) Drifted() bool {
+	return d.Missing || d.AddedRows > 0 || d.RemovedRows > 0
+}
+
+// CheckBaseline compares the current content of the baseline's tables with the baseline.
+func CheckBaseline(ctx context.Context, client *spannerClient.Client, base *Baseline) ([]TableDrift, error) {
+	existing, err := client.TableNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool, len(existing))
+	for _, t := range existing {
+		exists[t] = true
+	}
+
+	v := NewValidator(&config.Config{}, client)
+	tables := make([]string, 0, len(base.Tables))
+	for t := range base.Tables {
+		tables = append(tables, t)
+	}
+	sort.Strings(tables)
+
+	var drifts []TableDrift
+	for _, table := range tables {
+		was := base.Tables[table]
+		d := TableDrift{Table: table, BaselineCount: was.Count}
+		if !exists[table] {
+			d.Missing = true
+			drifts = append(drifts, d)
+			continue
+		}
+		rows, err := v.fetchRows(ctx, table)
+		if err != nil {
+			return nil, fmt.Errorf("reading table %s: %w", table, err)
+		}
+		now := snapshot(rows)
+		d.Count = now.Count
+		if now.Digest != was.Digest {
+			d.AddedRows, d.RemovedRows = diffHashes(now.RowHashes, was.RowHashes)
+		}
+		drifts = append(drifts, d)
+	}
+	return drifts, nil
+}
+
+// diffHashes counts the entries of a not in b and of b not in a, as multisets.
+func diffHashes(a, b []string) (onlyA, onlyB int) {
+	counts := make(map[string]int, len(b))
+	for _, h := range b {
+		counts[h]++
+	}
+	for _, h := range a {
+		if counts[h] > 0 {
+			counts[h]--
+			continue
+		}
+		onlyA++
+	}
+	for _, n := range counts {
+		onlyB += n
+	}
+	return onlyA, onlyB
+}
+
+// ReportDrift writes a report line for every drifted table to w and returns an error if any drifted.
+func ReportDrift(w io.Writer, drifts []TableDrift) error {
+	var drifted []string
+	for _, d := range drifts {
+		if !d.Drifted() {
+			logging.L().Debug("Table unchanged", "table", d.Table, "rows", d.Count)
+			continue
+		}
+		if d.Missing {
+			fmt.Fprintf(w, "%s table %s: no longer exists (baseline had %d rows)\n", glyphs.fail, d.Table, d.BaselineCount)
+		} else {
+			fmt.Fprintf(w, "%s table %s: drifted since baseline\n    rows: %d %s %d (+%d added, -%d removed)\n",
+				glyphs.fail, d.Table, d.BaselineCount, glyphs.arrow, d.Count, d.AddedRows, d.RemovedRows)
+		}
+		drifted = append(drifted, d.Table)
+	}
+	if len(drifted) > 0 {
+		return fmt.Errorf("data drifted for tables: %s", strings.Join(drifted, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package validator
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/spanner"
+)
+
+func TestBaselineSnapshotDiff(t *testing.T) {
+	row := func(id string, status int64) map[string]any {
+		return map[string]any{
+			"UserID": spanner.NullString{StringVal: id, Valid: true},
+			"Status": spanner.NullInt64{Int64: status, Valid: true},
+		}
+	}
+	base := snapshot([]map[string]any{row("u1", 1), row("u2", 1)})
+	if same := snapshot([]map[string]any{row("u2", 1), row("u1", 1)}); same.Digest != base.Digest {
+		t.Error("Expected row order not to change the digest")
+	}
+
+	at := func(ns int) []map[string]any {
+		return []map[string]any{{"At": spanner.NullTime{Time: time.Date(2024, 1, 1, 0, 0, 0, ns, time.UTC), Valid: true}}}
+	}
+	if snapshot(at(1)).Digest == snapshot(at(2)).Digest {
+		t.Error("Expected sub-second timestamp changes to change the digest")
+	}
+	if snapshot([]map[string]any{{"S": spanner.NullString{StringVal: "NULL(string)", Valid: true}}}).Digest ==
+		snapshot([]map[string]any{{"S": spanner.NullString{}}}).Digest {
+		t.Error("Expected NULL and its rendering to hash differently")
+	}
+
+	now := snapshot([]map[string]any{row("u1", 1), row("u2", 2), row("u3", 1)})
+	added, removed := diffHashes(now.RowHashes, base.RowHashes)
+	if added != 2 || removed != 1 {
+		t.Errorf("Expected 2 added and 1 removed rows, got %d and %d", added, removed)
+	}
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	b := &Baseline{Tables: map[string]TableSnapshot{"Users": base}}
+	if err := b.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	loaded, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatalf("LoadBaseline failed: %v", err)
+	}
+	if loaded.Tables["Users"].Digest != base.Digest || loaded.Tables["Users"].Count != 2 {
+		t.Errorf("Unexpected loaded baseline: %+v", loaded.Tables["Users"])
+	}
+}
@@ -0,0 +1,183 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/nu0ma/spalidate/internal/config"
+)
+
+// SyntheticTable describes a generated table used to measure decoding and comparison
+// without a database. Its first column is the INT64 primary key ID; the others cycle
+// through STRING, INT64, FLOAT64, BOOL, NUMERIC, DATE and TIMESTAMP.
+type SyntheticTable struct {
+	Rows    int
+	Columns int
+	// JSON adds a JSON column holding a small nested document.
+	JSON bool
+}
+
+// BenchResult holds the time spent on each phase of validating a synthetic table.
+type BenchResult struct {
+	Rows, Columns int
+	// Decode is the time to turn the wire values into Go values, as queries do.
+	Decode time.Duration
+	// Compare is the time to validate the decoded rows against matching expectations.
+	Compare time.Duration
+}
+
+var syntheticTypes = []sppb.TypeCode{
+	sppb.TypeCode_STRING,
+	sppb.TypeCode_INT64,
+	sppb.TypeCode_FLOAT64,
+	sppb.TypeCode_BOOL,
+	sppb.TypeCode_NUMERIC,
+	sppb.TypeCode_DATE,
+	sppb.TypeCode_TIMESTAMP,
+}
+
+// syntheticColumn is a generated column: its type and how a row's wire and expected
+// values are derived from the row number.
+type syntheticColumn struct {
+	name     string
+	typ      *sppb.Type
+	wire     func(i int) *structpb.Value
+	expected func(i int) any
+}
+
+func (t SyntheticTable) columns() []syntheticColumn {
+	cols := []syntheticColumn{{
+		name:     "ID",
+		typ:      &sppb.Type{Code: sppb.TypeCode_INT64},
+		wire:     func(i int) *structpb.Value { return structpb.NewStringValue(strconv.Itoa(i)) },
+		expected: func(i int) any { return i },
+	}}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for c := 1; c < t.Columns; c++ {
+		code := syntheticTypes[(c-1)%len(syntheticTypes)]
+		col := syntheticColumn{name: fmt.Sprintf("C%d_%s", c, code), typ: &sppb.Type{Code: code}}
+		switch code {
+		case sppb.TypeCode_STRING:
+			col.wire = func(i int) *structpb.Value { return structpb.NewStringValue(fmt.Sprintf("value-%d-%d", c, i)) }
+			col.expected = func(i int) any { return fmt.Sprintf("value-%d-%d", c, i) }
+		case sppb.TypeCode_INT64:
+			col.wire = func(i int) *structpb.Value { return structpb.NewStringValue(strconv.Itoa(i * c)) }
+			col.expected = func(i int) any { return i * c }
+		case sppb.TypeCode_FLOAT64:
+			col.wire = func(i int) *structpb.Value { return structpb.NewNumberValue(float64(i) + 0.5) }
+			col.expected = func(i int) any { return float64(i) + 0.5 }
+		case sppb.TypeCode_BOOL:
+			col.wire = func(i int) *structpb.Value { return structpb.NewBoolValue(i%2 == 0) }
+			col.expected = func(i int) any { return i%2 == 0 }
+		case sppb.TypeCode_NUMERIC:
+			col.wire = func(i int) *structpb.Value { return structpb.NewStringValue(fmt.Sprintf("%d.250000000", i)) }
+			col.expected = func(i int) any { return fmt.Sprintf("%d.25", i) }
+		case sppb.TypeCode_DATE:
+			col.wire = func(i int) *structpb.Value {
+				return structpb.NewStringValue(base.AddDate(0, 0, i%3650).Format(time.DateOnly))
+			}
+			col.expected = func(i int) any { return base.AddDate(0, 0, i%3650).Format(time.DateOnly) }
+		case sppb.TypeCode_TIMESTAMP:
+			col.wire = func(i int) *structpb.Value {
+				return structpb.NewStringValue(base.Add(time.Duration(i) * time.Second).Format(time.RFC3339Nano))
+			}
+			col.expected = func(i int) any { return base.Add(time.Duration(i) * time.Second).Format(time.RFC3339Nano) }
+		}
+		cols = append(cols, col)
+	}
+	if t.JSON {
+		cols = append(cols, syntheticColumn{
+			name: "Payload",
+			typ:  &sppb.Type{Code: sppb.TypeCode_JSON},
+			wire: func(i int) *structpb.Value {
+				return structpb.NewStringValue(fmt.Sprintf(`{"id":%d,"tags":["a","b"],"meta":{"ok":true}}`, i))
+			},
+			expected: func(i int) any {
+				return map[string]any{"id": i, "tags": []any{"a", "b"}, "meta": map[string]any{"ok": true}}
+			},
+		})
+	}
+	return cols
+}
+
+// Bench generates t, decodes its rows and validates them against matching expectations,
+// timing each phase. Validation must succeed; a failure means the generator and the
+// comparison disagree.
+func Bench(ctx context.Context, t SyntheticTable) (BenchResult, error) {
+	cols, wire, expected := t.generate()
+	res := BenchResult{Rows: t.Rows, Columns: len(cols)}
+
+	start := time.Now()
+	rows, err := decodeSynthetic(cols, wire)
+	if err != nil {
+		return res, err
+	}
+	res.Decode = time.Since(start)
+
+	start = time.Now()
+	err = compareSynthetic(ctx, rows, expected)
+	res.Compare = time.Since(start)
+	return res, err
+}
+
+// generate returns the columns of t, the wire values of its rows and the expectations
+// they meet.
+func (t SyntheticTable) generate() ([]syntheticColumn, [][]*structpb.Value, config.Rows) {
+	cols := t.columns()
+	wire := make([][]*structpb.Value, t.Rows)
+	expected := make(config.Rows, t.Rows)
+	for i := range t.Rows {
+		wire[i] = make([]*structpb.Value, len(cols))
+		expected[i] = make(map[string]any, len(cols))
+		for j, col := range cols {
+			wire[i][j] = col.wire(i)
+			expected[i][col.name] = col.expected(i)
+		}
+	}
+	return cols, wire, expected
+}
+
+// compareSynthetic validates decoded rows against their expectations through a
+// FakeDatabase.
+func compareSynthetic(ctx context.Context, rows []map[string]any, expected config.Rows) error {
+	cfg := &config.Config{Tables: map[string]config.TableConfig{"Synthetic": {Columns: expected}}}
+	v := NewValidator(cfg, nil, WithDatabase(&FakeDatabase{
+		Tables:      map[string][]map[string]any{"Synthetic": rows},
+		PrimaryKeys: map[string][]string{"Synthetic": {"ID"}},
+	}))
+	if err := v.Run(ctx).Err(); err != nil {
+		return fmt.Errorf("synthetic table did not validate: %w", err)
+	}
+	return nil
+}
+
+// decodeSynthetic decodes wire rows the way spannerDatabase.Query does, resolving the
+// decoders once.
+func decodeSynthetic(cols []syntheticColumn, wire [][]*structpb.Value) ([]map[string]any, error) {
+	decoders := make([]columnDecoder, len(cols))
+	for i, col := range cols {
+		dec, err := decoderFor(col.typ)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode column %s: %w", col.name, err)
+		}
+		decoders[i] = dec
+	}
+	rows := make([]map[string]any, 0, len(wire))
+	for _, values := range wire {
+		row := make(map[string]any, len(cols))
+		for i, col := range cols {
+			val, err := decoders[i](values[i])
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode column %s: %w", col.name, err)
+			}
+			row[col.name] = val
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,75 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// syntheticShapes are the tables measured by the benchmarks; the million-row table is
+// skipped with -short.
+var syntheticShapes = []SyntheticTable{
+	{Rows: 10_000, Columns: 8},
+	{Rows: 10_000, Columns: 8, JSON: true},
+	{Rows: 1_000, Columns: 120},
+	{Rows: 1_000_000, Columns: 8},
+}
+
+func shapeName(t SyntheticTable) string {
+	name := fmt.Sprintf("rows=%d/cols=%d", t.Rows, t.Columns)
+	if t.JSON {
+		name += "/json"
+	}
+	return name
+}
+
+func TestBenchSyntheticTable(t *testing.T) {
+	res, err := Bench(context.Background(), SyntheticTable{Rows: 50, Columns: 15, JSON: true})
+	if err != nil {
+		t.Fatalf("Bench: %v", err)
+	}
+	if res.Rows != 50 || res.Columns != 16 {
+		t.Errorf("Expected 50 rows of 16 columns, got %+v", res)
+	}
+}
+
+func BenchmarkDecodeSynthetic(b *testing.B) {
+	for _, shape := range syntheticShapes {
+		b.Run(shapeName(shape), func(b *testing.B) {
+			if shape.Rows > 100_000 && testing.Short() {
+				b.Skip("large table skipped with -short")
+			}
+			cols, wire, _ := shape.generate()
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := decodeSynthetic(cols, wire); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*shape.Rows), "ns/row")
+		})
+	}
+}
+
+func BenchmarkCompareSynthetic(b *testing.B) {
+	ctx := context.Background()
+	for _, shape := range syntheticShapes {
+		b.Run(shapeName(shape), func(b *testing.B) {
+			if shape.Rows > 100_000 && testing.Short() {
+				b.Skip("large table skipped with -short")
+			}
+			cols, wire, expected := shape.generate()
+			rows, err := decodeSynthetic(cols, wire)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			for b.Loop() {
+				if err := compareSynthetic(ctx, rows, expected); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*shape.Rows), "ns/row")
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"cloud.google.com/go/spanner"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/errkind"
+)
+
+// checkBounds reads the MIN and MAX of every bounded column in one query and checks them
+// against the table's columnBounds.
+func (v *Validator) checkBounds(ctx context.Context, tableName string, tableConfig config.TableConfig) error {
+	dialect, err := v.dialect(ctx)
+	if err != nil {
+		return err
+	}
+	cols := slices.Sorted(maps.Keys(tableConfig.ColumnBounds))
+	exprs := make([]string, 0, 2*len(cols))
+	for i, col := range cols {
+		c := dialect.Ident(col)
+		exprs = append(exprs, fmt.Sprintf("MIN(%s) AS min_%d, MAX(%s) AS max_%d", c, i, c, i))
+	}
+	rows, err := v.queryRows(ctx, tableName, aggregateQuery(dialect, tableName, strings.Join(exprs, ", "), tableConfig), v.queryParams(tableConfig))
+	if err != nil {
+		return err
+	}
+	var violations []string
+	for i, col := range cols {
+		b := tableConfig.ColumnBounds[col]
+		if msg, err := boundViolation(rows[0][fmt.Sprintf("min_%d", i)], b.Min, -1); err != nil {
+			return fmt.Errorf("column %s: %w", col, err)
+		} else if msg != "" {
+			violations = append(violations, fmt.Sprintf("%s min %s", col, msg))
+		}
+		if msg, err := boundViolation(rows[0][fmt.Sprintf("max_%d", i)], b.Max, 1); err != nil {
+			return fmt.Errorf("column %s: %w", col, err)
+		} else if msg != "" {
+			violations = append(violations, fmt.Sprintf("%s max %s", col, msg))
+		}
+	}
+	if len(violations) > 0 {
+		return errkind.Mark(fmt.Errorf("column bounds violated in table %s: %s", tableName, strings.Join(violations, "; ")), errkind.ErrRowMismatch)
+	}
+	return nil
+}
+
+// boundViolation describes how an aggregated value crosses a limit: below it when side is
+// -1 (a min bound), above it when side is 1 (a max bound). NULL aggregates, from empty
+// tables or all-NULL columns, and nil limits never violate.
+func boundViolation(actual, limit any, side int) (string, error) {
+	if limit == nil {
+		return "", nil
+	}
+	c, ok, err := compareOrdered(actual, limit)
+	if err != nil || !ok {
+		return "", err
+	}
+	if c == side {
+		word := "below"
+		if side > 0 {
+			word = "above"
+		}
+		return fmt.Sprintf("%s is %s the bound %s", valueToPretty(actual), word, valueToPretty(limit)), nil
+	}
+	return "", nil
+}
+
+// compareOrdered compares a Spanner value with a bound, returning -1, 0 or 1. ok is false
+// for NULL values.
+func compareOrdered(actual, limit any) (c int, ok bool, err error) {
+	if limit == config.Now {
+		limit = now()
+	}
+	switch a := actual.(type) {
+	case spanner.NullInt64:
+		if !a.Valid {
+			return 0, false, nil
+		}
+		return compareRat(new(big.Rat).SetInt64(a.Int64), limit)
+	case spanner.NullFloat64:
+		if !a.Valid || math.IsNaN(a.Float64) {
+			return 0, false, nil
+		}
+		if math.IsInf(a.Float64, 0) {
+			return int(math.Copysign(1, a.Float64)), true, nil
+		}
+		return compareRat(new(big.Rat).SetFloat64(a.Float64), limit)
+	case spanner.NullNumeric:
+		if !a.Valid {
+			return 0, false, nil
+		}
+		return compareRat(&a.Numeric, limit)
+	case spanner.NullTime:
+		if !a.Valid {
+			return 0, false, nil
+		}
+		t, err := boundTime(limit)
+		if err != nil {
+			return 0, false, err
+		}
+		return a.Time.Compare(t), true, nil
+	case spanner.NullDate:
+		if !a.Valid {
+			return 0, false, nil
+		}
+		t, err := boundTime(limit)
+		if err != nil {
+			return 0, false, err
+		}
+		d := civil.DateOf(t)
+		switch {
+		case a.Date.Before(d):
+			return -1, true, nil
+		case a.Date.After(d):
+			return 1, true, nil
+		}
+		return 0, true, nil
+	case spanner.NullString:
+		if !a.Valid {
+			return 0, false, nil
+		}
+		s, isString := limit.(string)
+		if !isString {
+			return 0, false, typeMismatchError("string", limit)
+		}
+		return strings.Compare(a.StringVal, s), true, nil
+	}
+	return 0, false, fmt.Errorf("column bounds are not supported for %T values", actual)
+}
+
+func compareRat(a *big.Rat, limit any) (int, bool, error) {
+	r, err := toRat(limit)
+	if err != nil {
+		return 0, false, err
+	}
+	return a.Cmp(r), true, nil
+}
+
+// boundTime returns the time of a TIMESTAMP or DATE bound.
+func boundTime(limit any) (time.Time, error) {
+	switch l := limit.(type) {
+	case time.Time:
+		return l, nil
+	case string:
+		return parseTimestamp(l)
+	}
+	return time.Time{}, typeMismatchError("timestamp", limit)
+}
@@ -0,0 +1,69 @@
+package validator
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"cloud.google.com/go/spanner"
+	"github.com/nu0ma/spalidate/internal/config"
+)
+
+func TestBoundViolation(t *testing.T) {
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	cfg, err := config.Parse([]byte(`tables:
+  T:
+    columnBounds:
+      Price: {min: 0}
+      CreatedAt:
+        max: !now
+      UpdatedAt: {max: "!now"}
+      Day: {min: 2024-01-01, max: 2024-12-31}
+      Code: {min: "A", max: "M"}
+`), ".")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	bounds := cfg.Tables["T"].ColumnBounds
+
+	tests := []struct {
+		name      string
+		actual    any
+		limit     any
+		side      int
+		violation bool
+	}{
+		{"int above min", spanner.NullInt64{Int64: 0, Valid: true}, bounds["Price"].Min, -1, false},
+		{"int below min", spanner.NullInt64{Int64: -1, Valid: true}, bounds["Price"].Min, -1, true},
+		{"float below min", spanner.NullFloat64{Float64: -0.01, Valid: true}, bounds["Price"].Min, -1, true},
+		{"numeric below min", spanner.NullNumeric{Numeric: *big.NewRat(-1, 100), Valid: true}, bounds["Price"].Min, -1, true},
+		{"NULL aggregate", spanner.NullInt64{}, bounds["Price"].Min, -1, false},
+		{"timestamp in the past", spanner.NullTime{Time: now().Add(-time.Hour), Valid: true}, bounds["CreatedAt"].Max, 1, false},
+		{"timestamp in the future", spanner.NullTime{Time: now().Add(time.Hour), Valid: true}, bounds["UpdatedAt"].Max, 1, true},
+		{"date within", spanner.NullDate{Date: civil.Date{Year: 2024, Month: 12, Day: 31}, Valid: true}, bounds["Day"].Max, 1, false},
+		{"date after", spanner.NullDate{Date: civil.Date{Year: 2025, Month: 1, Day: 1}, Valid: true}, bounds["Day"].Max, 1, true},
+		{"string above max", spanner.NullString{StringVal: "Z", Valid: true}, bounds["Code"].Max, 1, true},
+		{"no limit", spanner.NullInt64{Int64: -5, Valid: true}, bounds["Price"].Max, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := boundViolation(tt.actual, tt.limit, tt.side)
+			if err != nil {
+				t.Fatalf("boundViolation: %v", err)
+			}
+			if (msg != "") != tt.violation {
+				t.Errorf("got violation %q, want violation=%v", msg, tt.violation)
+			}
+		})
+	}
+
+	if _, err := boundViolation(spanner.NullString{StringVal: "a", Valid: true}, 1, 1); err == nil {
+		t.Error("Expected a numeric bound on a STRING column to be rejected")
+	}
+	if _, err := config.Parse([]byte("tables:\n  T:\n    columnBounds:\n      Price: {}\n"), "."); err == nil {
+		t.Error("Expected a bound without min or max to be rejected")
+	}
+}
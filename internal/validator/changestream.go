@@ -0,0 +1,72 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/errkind"
+	"github.com/nu0ma/spalidate/internal/logging"
+	spannerClient "github.com/nu0ma/spalidate/internal/spanner"
+)
+
+// changeStreamPrefix names the results of change stream assertions, next to the tables.
+const changeStreamPrefix = "changeStream:"
+
+// runChangeStream reads a change stream over its window ending at end and checks that
+// every expected record is present.
+func (v *Validator) runChangeStream(ctx context.Context, name string, s config.ChangeStream, end time.Time) TableResult {
+	tr := TableResult{Table: changeStreamPrefix + name}
+	start := end.Add(-s.Window)
+	changes, err := v.db.ReadChangeStream(ctx, name, start, end)
+	if err != nil {
+		tr.Err = err
+		return tr
+	}
+	logging.L().Debug("Read change stream", "stream", name, "changes", len(changes), "from", start, "to", end)
+	tr.Err = errkind.Mark(v.matchChanges(name, changes, s.Records), errkind.ErrRowMismatch)
+	return tr
+}
+
+// matchChanges pairs each expected record with a distinct data change and lists the
+// records left unpaired.
+func (v *Validator) matchChanges(name string, changes []spannerClient.DataChange, expected []config.ChangeRecord) error {
+	used := make([]bool, len(changes))
+	var missing []string
+	for _, exp := range expected {
+		found := false
+		for i, c := range changes {
+			if !used[i] && v.changeMatches(c, exp) {
+				used[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, exp.String())
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("change stream %s: %d of %d expected records missing among %d changes: %s",
+			name, len(missing), len(expected), len(changes), strings.Join(missing, "; "))
+	}
+	return nil
+}
+
+func (v *Validator) changeMatches(c spannerClient.DataChange, exp config.ChangeRecord) bool {
+	if c.Table != exp.Table || exp.ModType != "" && c.ModType != exp.ModType {
+		return false
+	}
+	for k, want := range exp.Keys {
+		got, ok := c.Keys[k]
+		if !ok {
+			return false
+		}
+		// change streams encode INT64 keys as strings
+		if v.validateData(got, want) != nil && fmt.Sprint(got) != fmt.Sprint(want) {
+			return false
+		}
+	}
+	return true
+}
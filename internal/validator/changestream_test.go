@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	spannerClient "github.com/nu0ma/spalidate/internal/spanner"
+)
+
+func TestMatchChanges(t *testing.T) {
+	cfg, err := config.Parse([]byte(`tables: {}
+changeStreams:
+  OrdersStream:
+    window: 5m
+    records:
+      - {table: Orders, modType: insert, keys: {OrderID: 1}}
+      - {table: Orders, modType: UPDATE, keys: {OrderID: 1}}
+      - {table: Payments, keys: {OrderID: 1, PaymentID: "p-1"}}
+`), ".")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	records := cfg.ChangeStreams["OrdersStream"].Records
+	v := NewValidator(cfg, nil)
+
+	changes := []spannerClient.DataChange{
+		{Table: "Orders", ModType: "INSERT", Keys: map[string]any{"OrderID": "1"}},
+		{Table: "Orders", ModType: "UPDATE", Keys: map[string]any{"OrderID": "1"}},
+		{Table: "Payments", ModType: "DELETE", Keys: map[string]any{"OrderID": "1", "PaymentID": "p-1"}},
+		{Table: "Orders", ModType: "INSERT", Keys: map[string]any{"OrderID": "2"}},
+	}
+	if err := v.matchChanges("OrdersStream", changes, records); err != nil {
+		t.Errorf("Expected every record to match, got %v", err)
+	}
+
+	// a change pairs with one expected record only
+	twice := []config.ChangeRecord{records[0], records[0]}
+	err = v.matchChanges("OrdersStream", changes, twice)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 expected records missing among 4 changes: INSERT Orders {OrderID: 1}") {
+		t.Errorf("Expected a duplicate record to be missing, got %v", err)
+	}
+
+	err = v.matchChanges("OrdersStream", changes[3:], records)
+	if err == nil || !strings.Contains(err.Error(), "3 of 3 expected records missing") {
+		t.Errorf("Expected all records to be missing, got %v", err)
+	}
+
+	for _, src := range []string{
+		"changeStreams: {S: {records: [{table: T}]}}",
+		"changeStreams: {S: {window: 1m}}",
+		"changeStreams: {S: {window: 1m, records: [{modType: INSERT}]}}",
+		"changeStreams: {S: {window: 1m, records: [{table: T, modType: UPSERT}]}}",
+		"changeStreams: {'S(start_timestamp => NULL) --': {window: 1m, records: [{table: T}]}}",
+	} {
+		if _, err := config.Parse([]byte(src), "."); err == nil {
+			t.Errorf("Expected %q to be rejected", src)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/logging"
+	spannerClient "github.com/nu0ma/spalidate/internal/spanner"
+)
+
+// TableDivergence describes how one table differs between two connections.
+type TableDivergence struct {
+	Table        string
+	PrimaryErr   error
+	SecondaryErr error
+	// OnlyInPrimary and OnlyInSecondary hold formatted rows present on one side only.
+	OnlyInPrimary   []string
+	OnlyInSecondary []string
+}
+
+// Diverged reports whether the two sides disagree on validation outcome or content.
+func (d TableDivergence) Diverged() bool {
+	if (d.PrimaryErr == nil) != (d.SecondaryErr == nil) {
+		return true
+	}
+	return len(d.OnlyInPrimary) > 0 || len(d.OnlyInSecondary) > 0
+}
+
+// CheckConsistency runs the same configuration against two connections and reports,
+// per table, the validation outcome on each side and the rows that differ between them.
+func CheckConsistency(ctx context.Context, cfg *config.Config, primary, secondary *spannerClient.Client) ([]TableDivergence, error) {
+	pv := NewValidator(cfg, primary)
+	sv := NewValidator(cfg, secondary)
+
+	var results []TableDivergence
+	for _, tableName := range sortedTableNames(cfg.Tables) {
+		tableConfig := cfg.Tables[tableName]
+		enabled, err := tableConfig.Enabled()
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", tableName, err)
+		}
+		if !enabled {
+			logging.L().Info("Skipping table", "table", tableName, "when", tableConfig.When)
+			continue
+		}
+
+		ptv, stv := pv.forTable(tableConfig), sv.forTable(tableConfig)
+		primaryRows, primaryPK, err := ptv.readTable(ctx, tableName, tableConfig)
+		if err != nil {
+			return nil, fmt.Errorf("reading table %s from primary: %w", tableName, err)
+		}
+		secondaryRows, secondaryPK, err := stv.readTable(ctx, tableName, tableConfig)
+		if err != nil {
+			return nil, fmt.Errorf("reading table %s from secondary: %w", tableName, err)
+		}
+
+		d := TableDivergence{
+			Table:        tableName,
+			PrimaryErr:   ptv.validateRows(tableName, primaryRows, primaryPK, tableConfig),
+			SecondaryErr: stv.validateRows(tableName, secondaryRows, secondaryPK, tableConfig),
+		}
+		d.OnlyInPrimary, d.OnlyInSecondary = diffRowsets(primaryRows, secondaryRows)
+		results = append(results, d)
+	}
+	return results, nil
+}
+
+// diffRowsets returns the rows of a not present in b and vice versa, as multisets. Rows
+// are compared by canonicalRow and returned formatted.
+func diffRowsets(a, b []map[string]any) (onlyA, onlyB []string) {
+	counts := make(map[string]int)
+	for _, r := range b {
+		counts[canonicalRow(r)]++
+	}
+	for _, r := range a {
+		key := canonicalRow(r)
+		if counts[key] > 0 {
+			counts[key]--
+			continue
+		}
+		onlyA = append(onlyA, formatRow(r))
+	}
+	for _, r := range b {
+		key := canonicalRow(r)
+		if counts[key] > 0 {
+			counts[key]--
+			onlyB = append(onlyB, formatRow(r))
+		}
+	}
+	return onlyA, onlyB
+}
+
+// formatRow renders a row as `{col: value, ...}` with columns in sorted order, and
+// timestamps with their sub-second digits.
+func formatRow(row map[string]any) string {
+	parts := make([]string, 0, len(row))
+	for _, k := range sortedKeys(row) {
+		parts = append(parts, fmt.Sprintf("%s: %s", k, canonicalValue(row[k])))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func buildDivergenceReport(d TableDivergence) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s table %s: connections diverge\n", glyphs.fail, d.Table)
+	fmt.Fprintf(&b, "    primary:   %s\n", outcome(d.PrimaryErr))
+	fmt.Fprintf(&b, "    secondary: %s\n", outcome(d.SecondaryErr))
+	for _, r := range d.OnlyInPrimary {
+		fmt.Fprintf(&b, "     %s only in primary:   %s\n", glyphs.bullet, r)
+	}
+	for _, r := range d.OnlyInSecondary {
+		fmt.Fprintf(&b, "     %s only in secondary: %s\n", glyphs.bullet, r)
+	}
+	return b.String()
+}
+
+func outcome(err error) string {
+	if err == nil {
+		return "passed"
+	}
+	return "failed (" + err.Error() + ")"
+}
+
+// ReportDivergences writes a report for every diverging table to w and returns an error if any diverged.
+func ReportDivergences(w io.Writer, results []TableDivergence) error {
+	var diverged []string
+	for _, d := range results {
+		if !d.Diverged() {
+			logging.L().Debug("Table consistent", "table", d.Table)
+			continue
+		}
+		fmt.Fprint(w, buildDivergenceReport(d))
+		diverged = append(diverged, d.Table)
+	}
+	if len(diverged) > 0 {
+		return fmt.Errorf("connections diverge for tables: %s", strings.Join(diverged, ", "))
+	}
+	return nil
+}
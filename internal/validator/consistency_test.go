@@ -0,0 +1,21 @@
+package validator
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/spanner"
+)
+
+func TestDiffRowsets(t *testing.T) {
+	at := func(ns int) map[string]any {
+		return map[string]any{"At": spanner.NullTime{Time: time.Date(2024, 1, 1, 0, 0, 0, ns, time.UTC), Valid: true}}
+	}
+	onlyA, onlyB := diffRowsets([]map[string]any{at(0), at(1)}, []map[string]any{at(0), at(2)})
+	if len(onlyA) != 1 || onlyA[0] != "{At: 2024-01-01T00:00:00.000000001Z}" {
+		t.Errorf("Expected the row differing in nanoseconds only in a, got %v", onlyA)
+	}
+	if len(onlyB) != 1 || onlyB[0] != "{At: 2024-01-01T00:00:00.000000002Z}" {
+		t.Errorf("Expected the row differing in nanoseconds only in b, got %v", onlyB)
+	}
+}
@@ -0,0 +1,193 @@
+package validator
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"cloud.google.com/go/spanner"
+)
+
+// CompareCSV validates that the content of a table matches a CSV export.
+// The first CSV record is the header; rows are paired by primary key and cells are
+// coerced to the type of the corresponding Spanner column before comparison.
+// Only columns present in the CSV header are compared.
+func (v *Validator) CompareCSV(ctx context.Context, tableName string, r io.Reader) error {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return errors.New("CSV has no header row")
+	}
+
+	schema, err := v.db.TableSchema(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	pk := schema.PrimaryKey
+	rows, err := v.fetchRows(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	return v.compareCSVRows(tableName, pk, records[0], records[1:], rows)
+}
+
+func (v *Validator) compareCSVRows(tableName string, pk, header []string, records [][]string, rows []map[string]any) error {
+	colIndex := make(map[string]int, len(header))
+	for i, h := range header {
+		colIndex[strings.TrimSpace(h)] = i
+	}
+	for _, k := range pk {
+		if _, ok := colIndex[k]; !ok {
+			return fmt.Errorf("CSV header is missing primary key column %s", k)
+		}
+	}
+	if len(rows) > 0 {
+		for col := range colIndex {
+			if _, ok := rows[0][col]; !ok {
+				return fmt.Errorf("CSV column %s does not exist in table %s", col, tableName)
+			}
+		}
+	}
+
+	byKey := make(map[string]map[string]any, len(rows))
+	for _, row := range rows {
+		parts := make([]string, len(pk))
+		for i, k := range pk {
+			parts[i] = canonicalValue(row[k])
+		}
+		byKey[strings.Join(parts, "/")] = row
+	}
+
+	var errs, reports []string
+	seen := make(map[string]bool, len(records))
+	for ri, rec := range records {
+		if len(rec) != len(header) {
+			return fmt.Errorf("CSV line %d: expected %d fields, got %d", ri+2, len(header), len(rec))
+		}
+		parts := make([]string, len(pk))
+		for i, k := range pk {
+			parts[i] = rec[colIndex[k]]
+			if len(rows) > 0 && isTimestamp(rows[0][k]) {
+				// any RFC 3339 spelling of the key pairs with the row
+				if t, err := time.Parse(time.RFC3339Nano, parts[i]); err == nil {
+					parts[i] = canonicalValue(t)
+				}
+			}
+		}
+		key := strings.Join(parts, "/")
+		if seen[key] {
+			errs = append(errs, fmt.Sprintf("duplicate key %s in CSV", key))
+			continue
+		}
+		seen[key] = true
+
+		row, ok := byKey[key]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("row with key %s missing from table", key))
+			continue
+		}
+
+		var diffs []colDiff
+		for _, col := range header {
+			col = strings.TrimSpace(col)
+			actual := row[col]
+			expected, err := coerceCSVValue(actual, rec[colIndex[col]])
+			if err != nil {
+				diffs = append(diffs, colDiff{column: col, expected: rec[colIndex[col]], actual: actual})
+				continue
+			}
+			if err := v.validateData(actual, expected); err != nil {
+				diffs = append(diffs, colDiff{column: col, expected: expected, actual: actual})
+			}
+		}
+		if len(diffs) > 0 {
+			reports = append(reports, buildMismatchReport(tableName, diffs))
+			errs = append(errs, fmt.Sprintf("row with key %s does not match", key))
+		}
+	}
+
+	var extra []string
+	for key := range byKey {
+		if !seen[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	for _, key := range extra {
+		errs = append(errs, fmt.Sprintf("row with key %s not present in CSV", key))
+	}
+
+	if len(errs) > 0 {
+		err := fmt.Errorf("table %s differs from CSV: %s", tableName, strings.Join(errs, "; "))
+		if len(reports) > 0 {
+			return &reportError{err: err, report: strings.Join(reports, "\n")}
+		}
+		return err
+	}
+	return nil
+}
+
+// coerceCSVValue converts a CSV cell into an expected value of the actual column's type.
+// An empty cell stands for NULL when the actual value is NULL.
+func coerceCSVValue(actual any, s string) (any, error) {
+	if s == "" && isNull(actual) {
+		return nil, nil
+	}
+	switch actual.(type) {
+	case spanner.NullInt64, int64:
+		return strconv.ParseInt(s, 10, 64)
+	case spanner.NullFloat64, float64:
+		return strconv.ParseFloat(s, 64)
+	case spanner.NullBool, bool:
+		return strconv.ParseBool(s)
+	case spanner.NullDate, civil.Date, spanner.NullTime, time.Time, spanner.NullString, string:
+		return s, nil
+	case spanner.NullJSON:
+		if !looksLikeJSON(s) {
+			return nil, fmt.Errorf("not a JSON document: %q", s)
+		}
+		return s, nil
+	}
+	return s, nil
+}
+
+func isTimestamp(v any) bool {
+	switch v.(type) {
+	case spanner.NullTime, time.Time:
+		return true
+	}
+	return false
+}
+
+func isNull(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case spanner.NullString:
+		return !x.Valid
+	case spanner.NullInt64:
+		return !x.Valid
+	case spanner.NullFloat64:
+		return !x.Valid
+	case spanner.NullBool:
+		return !x.Valid
+	case spanner.NullTime:
+		return !x.Valid
+	case spanner.NullDate:
+		return !x.Valid
+	case spanner.NullJSON:
+		return !x.Valid
+	case spanner.NullNumeric:
+		return !x.Valid
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/nu0ma/spalidate/internal/config"
+)
+
+func TestCompareCSVRows(t *testing.T) {
+	v := NewValidator(&config.Config{}, nil)
+	rows := []map[string]any{
+		{"UserID": "user-001", "Status": spanner.NullInt64{Int64: 1, Valid: true}, "Score": spanner.NullFloat64{}},
+		{"UserID": "user-002", "Status": spanner.NullInt64{Int64: 2, Valid: true}, "Score": spanner.NullFloat64{Float64: 0.5, Valid: true}},
+	}
+	header := []string{"UserID", "Status", "Score"}
+
+	records := [][]string{
+		{"user-002", "2", "0.5"},
+		{"user-001", "1", ""},
+	}
+	if err := v.compareCSVRows("Users", []string{"UserID"}, header, records, rows); err != nil {
+		t.Fatalf("Expected CSV to match, got: %v", err)
+	}
+
+	records = [][]string{
+		{"user-001", "3", ""},
+		{"user-003", "1", ""},
+	}
+	err := v.compareCSVRows("Users", []string{"UserID"}, header, records, rows)
+	if err == nil {
+		t.Fatal("Expected CSV mismatch")
+	}
+	for _, want := range []string{
+		"row with key user-001 does not match",
+		"row with key user-003 missing from table",
+		"row with key user-002 not present in CSV",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got: %v", want, err)
+		}
+	}
+}
+
+func TestCompareCSVTimestampKeys(t *testing.T) {
+	v := NewValidator(&config.Config{}, nil)
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []map[string]any{
+		{"At": spanner.NullTime{Time: at.Add(100 * time.Millisecond), Valid: true}, "N": spanner.NullInt64{Int64: 1, Valid: true}},
+		{"At": spanner.NullTime{Time: at.Add(200 * time.Millisecond), Valid: true}, "N": spanner.NullInt64{Int64: 2, Valid: true}},
+	}
+	records := [][]string{
+		{"2024-01-01T00:00:00.200Z", "2"},
+		{"2024-01-01T00:00:00.1Z", "1"},
+	}
+	if err := v.compareCSVRows("Events", []string{"At"}, []string{"At", "N"}, records, rows); err != nil {
+		t.Errorf("Expected rows keyed by sub-second timestamps to pair, got: %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	spannerClient "github.com/nu0ma/spalidate/internal/spanner"
+)
+
+// Database is the narrow view of a database the validator reads through. NewValidator
+// wraps its Spanner client in one; WithDatabase substitutes another, such as FakeDatabase.
+// Rows are column maps of decoded values (spanner.NullInt64, spanner.NullString, ...).
+type Database interface {
+	// Read returns the rows of a table selected by opts.
+	Read(ctx context.Context, table string, opts ReadOptions) ([]map[string]any, error)
+	// Query runs a SQL query, such as an aggregate over a table, binding params as `@name`.
+	Query(ctx context.Context, sql string, params map[string]any) ([]map[string]any, error)
+	// TableSchema describes a table.
+	TableSchema(ctx context.Context, table string) (TableSchema, error)
+	// GeneratedColumns returns the generated columns of a table by name.
+	GeneratedColumns(ctx context.Context, table string) (map[string]spannerClient.GeneratedColumn, error)
+	// CheckConstraints returns the CHECK constraints of a table, mapping names to expressions.
+	CheckConstraints(ctx context.Context, table string) (map[string]string, error)
+	// DatabaseOptions returns the options set on the database by name.
+	DatabaseOptions(ctx context.Context) (map[string]string, error)
+	// ReadChangeStream returns the data changes of a change stream committed between start and end.
+	ReadChangeStream(ctx context.Context, stream string, start, end time.Time) ([]spannerClient.DataChange, error)
+}
+
+// dialectDatabase is implemented by databases that may not speak GoogleSQL. The SQL the
+// validator builds is GoogleSQL for databases that do not implement it, such as
+// FakeDatabase.
+type dialectDatabase interface {
+	Dialect(ctx context.Context) (spannerClient.Dialect, error)
+}
+
+// dialect returns the SQL dialect of the validator's database.
+func (v *Validator) dialect(ctx context.Context) (spannerClient.Dialect, error) {
+	if d, ok := v.db.(dialectDatabase); ok {
+		return d.Dialect(ctx)
+	}
+	return spannerClient.DialectGoogleSQL, nil
+}
+
+// ReadOptions select the rows returned by Database.Read.
+type ReadOptions struct {
+	// Where is a SQL condition restricting the rows, with Params bound as `@name`; empty
+	// reads every row.
+	Where  string
+	Params map[string]any
+	// OrderBy sorts the rows by these columns, ascending.
+	OrderBy []string
+	// Exclude lists columns left out of the rows.
+	Exclude []string
+}
+
+// TableSchema is what the validator needs to know of a table's schema.
+type TableSchema struct {
+	// PrimaryKey lists the primary key columns in key order.
+	PrimaryKey []string
+}
+
+// spannerDatabase reads through a Spanner client.
+type spannerDatabase struct {
+	client *spannerClient.Client
+	// timeout overrides the client's per-query limit when positive; see WithQueryTimeout.
+	timeout time.Duration
+}
+
+func (d *spannerDatabase) Read(ctx context.Context, table string, opts ReadOptions) ([]map[string]any, error) {
+	dialect, err := d.client.Dialect(ctx)
+	if err != nil {
+		return nil, d.client.Diagnose(ctx, err, table)
+	}
+	selected := "*"
+	if len(opts.Exclude) > 0 && dialect == spannerClient.DialectGoogleSQL {
+		selected = fmt.Sprintf("* EXCEPT (%s)", strings.Join(opts.Exclude, ", "))
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", selected, dialect.Ident(table))
+	if opts.Where != "" {
+		query += " WHERE " + opts.Where
+	}
+	if len(opts.OrderBy) > 0 {
+		cols := make([]string, len(opts.OrderBy))
+		for i, c := range opts.OrderBy {
+			cols[i] = dialect.Ident(c)
+		}
+		query += " ORDER BY " + strings.Join(cols, ", ")
+	}
+	rows, err := d.Query(ctx, query, opts.Params)
+	if err != nil {
+		return nil, d.client.Diagnose(ctx, err, table)
+	}
+	if dialect == spannerClient.DialectGoogleSQL {
+		return rows, nil
+	}
+	// PostgreSQL has no SELECT * EXCEPT
+	return excludeColumns(rows, opts.Exclude), nil
+}
+
+// excludeColumns drops columns from rows in place.
+func excludeColumns(rows []map[string]any, cols []string) []map[string]any {
+	if len(cols) == 0 {
+		return rows
+	}
+	for _, row := range rows {
+		for _, c := range cols {
+			delete(row, c)
+		}
+	}
+	return rows
+}
+
+func (d *spannerDatabase) Dialect(ctx context.Context) (spannerClient.Dialect, error) {
+	dialect, err := d.client.Dialect(ctx)
+	return dialect, d.client.Diagnose(ctx, err, "")
+}
+
+// Query runs a query and decodes every row into a column map.
+func (d *spannerDatabase) Query(ctx context.Context, sql string, params map[string]any) ([]map[string]any, error) {
+	var (
+		qctx   context.Context
+		cancel context.CancelFunc
+	)
+	if d.timeout > 0 {
+		qctx, cancel = context.WithTimeout(ctx, d.timeout)
+	} else {
+		qctx, cancel = d.client.WithQueryTimeout(ctx)
+	}
+	defer cancel()
+
+	var (
+		rows []map[string]any
+		// every row of a result has the same columns, so they are resolved once
+		names    []string
+		decoders []columnDecoder
+	)
+	err := d.client.QueryWithParams(qctx, sql, params).Do(func(row *spanner.Row) error {
+		if decoders == nil {
+			names = row.ColumnNames()
+			decoders = make([]columnDecoder, len(names))
+			for i, name := range names {
+				dec, err := decoderFor(row.ColumnType(i))
+				if err != nil {
+					return fmt.Errorf("failed to decode column %s: %w", name, err)
+				}
+				decoders[i] = dec
+			}
+		}
+		rowData := make(map[string]any, len(names))
+		for i, name := range names {
+			val, err := decoders[i](row.ColumnValue(i))
+			if err != nil {
+				return fmt.Errorf("failed to decode column %s: %w", name, err)
+			}
+			rowData[name] = val
+		}
+		rows = append(rows, rowData)
+		return nil
+	})
+	if err != nil {
+		if errors.Is(qctx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, fmt.Errorf("query exceeded the per-query timeout of %s: %w", d.queryTimeout(), err)
+		}
+		return nil, fmt.Errorf("query execution failed: %w", d.client.Diagnose(ctx, err, ""))
+	}
+	return rows, nil
+}
+
+// queryTimeout returns the per-query limit: the validator's own, else the client's.
+func (d *spannerDatabase) queryTimeout() time.Duration {
+	if d.timeout > 0 {
+		return d.timeout
+	}
+	return d.client.QueryTimeout()
+}
+
+func (d *spannerDatabase) TableSchema(ctx context.Context, table string) (TableSchema, error) {
+	pk, err := d.client.PrimaryKeyColumns(ctx, table)
+	return TableSchema{PrimaryKey: pk}, d.client.Diagnose(ctx, err, table)
+}
+
+func (d *spannerDatabase) GeneratedColumns(ctx context.Context, table string) (map[string]spannerClient.GeneratedColumn, error) {
+	return d.client.GeneratedColumns(ctx, table)
+}
+
+func (d *spannerDatabase) CheckConstraints(ctx context.Context, table string) (map[string]string, error) {
+	return d.client.CheckConstraints(ctx, table)
+}
+
+func (d *spannerDatabase) DatabaseOptions(ctx context.Context) (map[string]string, error) {
+	return d.client.DatabaseOptions(ctx)
+}
+
+func (d *spannerDatabase) ReadChangeStream(ctx context.Context, stream string, start, end time.Time) ([]spannerClient.DataChange, error) {
+	return d.client.ReadChangeStream(ctx, stream, start, end)
+}
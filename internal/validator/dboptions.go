@@ -0,0 +1,43 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nu0ma/spalidate/internal/errkind"
+)
+
+// databaseOptionsResult names the result of the databaseOptions assertions, next to the
+// tables.
+const databaseOptionsResult = "databaseOptions"
+
+// runDatabaseOptions compares the database's options with the databaseOptions assertions.
+func (v *Validator) runDatabaseOptions(ctx context.Context) TableResult {
+	tr := TableResult{Table: databaseOptionsResult}
+	actual, err := v.db.DatabaseOptions(ctx)
+	if err != nil {
+		tr.Err = err
+		return tr
+	}
+	var diffs []string
+	for _, name := range slices.Sorted(maps.Keys(v.config.DatabaseOptions)) {
+		want := v.config.DatabaseOptions[name]
+		if got := actual[name]; got != want {
+			diffs = append(diffs, fmt.Sprintf("%s: expected %s, got %s", name, optionValue(want), optionValue(got)))
+		}
+	}
+	if len(diffs) > 0 {
+		tr.Err = errkind.Mark(fmt.Errorf("database options differ: %s", strings.Join(diffs, "; ")), errkind.ErrRowMismatch)
+	}
+	return tr
+}
+
+func optionValue(s string) string {
+	if s == "" {
+		return "unset"
+	}
+	return fmt.Sprintf("%q", s)
+}
@@ -0,0 +1,49 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/errkind"
+)
+
+func TestDatabaseOptions(t *testing.T) {
+	cfg, err := config.Parse([]byte(`databaseOptions:
+  default_leader: us-east1
+  version_retention_period: 7d
+  default_time_zone: ""
+`), ".")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	db := &FakeDatabase{Options: map[string]string{
+		"database_dialect":         "GOOGLE_STANDARD_SQL",
+		"default_leader":           "us-east1",
+		"version_retention_period": "7d",
+	}}
+	result := NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background())
+	if err := result.Err(); err != nil {
+		t.Errorf("Expected the database options to match, got %v", err)
+	}
+	if len(result.Tables) != 1 || result.Tables[0].Table != databaseOptionsResult {
+		t.Errorf("Expected a %s result, got %+v", databaseOptionsResult, result.Tables)
+	}
+
+	db.Options["default_leader"] = "us-central1"
+	db.Options["default_time_zone"] = "Asia/Tokyo"
+	err = NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background()).Err()
+	for _, want := range []string{
+		`default_leader: expected "us-east1", got "us-central1"`,
+		`default_time_zone: expected unset, got "Asia/Tokyo"`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q, got %v", want, err)
+		}
+	}
+	if !errors.Is(err, errkind.ErrRowMismatch) {
+		t.Errorf("Expected a mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package validator
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// columnDecoder decodes the values of one column; see decoderFor.
+type columnDecoder func(*structpb.Value) (any, error)
+
+// decodeGenericValue decodes a Spanner value according to its column type. The Go type
+// of the result selects the comparison in validateData, so expected values are always
+// interpreted under the column's type, e.g. "42" is a number only for INT64 columns.
+func decodeGenericValue(gcv *spanner.GenericColumnValue) (any, error) {
+	dec, err := decoderFor(gcv.Type)
+	if err != nil {
+		return nil, err
+	}
+	return dec(gcv.Value)
+}
+
+// decoderFor returns the decoder of a column type. Queries look it up once per column,
+// and scalar values are parsed straight from their protobuf encoding instead of going
+// through the reflection-based spanner decoding.
+func decoderFor(t *sppb.Type) (columnDecoder, error) {
+	if t == nil {
+		return nil, errors.New("column has no type")
+	}
+	switch t.Code {
+	case sppb.TypeCode_STRING:
+		return func(v *structpb.Value) (any, error) {
+			s, ok, err := stringValue(v)
+			return spanner.NullString{StringVal: s, Valid: ok}, err
+		}, nil
+	case sppb.TypeCode_INT64:
+		return func(v *structpb.Value) (any, error) {
+			s, ok, err := stringValue(v)
+			if !ok || err != nil {
+				return spanner.NullInt64{}, err
+			}
+			n, err := strconv.ParseInt(s, 10, 64)
+			return spanner.NullInt64{Int64: n, Valid: err == nil}, err
+		}, nil
+	case sppb.TypeCode_FLOAT64, sppb.TypeCode_FLOAT32:
+		// FLOAT32 (PostgreSQL real) values are compared as FLOAT64
+		return decodeFloat64, nil
+	case sppb.TypeCode_BOOL:
+		return func(v *structpb.Value) (any, error) {
+			switch k := v.GetKind().(type) {
+			case *structpb.Value_NullValue:
+				return spanner.NullBool{}, nil
+			case *structpb.Value_BoolValue:
+				return spanner.NullBool{Bool: k.BoolValue, Valid: true}, nil
+			}
+			return nil, fmt.Errorf("unexpected encoding of BOOL value %v", v)
+		}, nil
+	case sppb.TypeCode_NUMERIC:
+		return func(v *structpb.Value) (any, error) {
+			s, ok, err := stringValue(v)
+			if !ok || err != nil {
+				return spanner.NullNumeric{}, err
+			}
+			r, valid := new(big.Rat).SetString(s)
+			if !valid && s == "NaN" && t.TypeAnnotation == sppb.TypeAnnotationCode_PG_NUMERIC {
+				return nil, errors.New("PostgreSQL NUMERIC NaN values are not supported")
+			}
+			if !valid {
+				return nil, fmt.Errorf("invalid NUMERIC value %q", s)
+			}
+			return spanner.NullNumeric{Numeric: *r, Valid: true}, nil
+		}, nil
+	case sppb.TypeCode_DATE:
+		return func(v *structpb.Value) (any, error) {
+			s, ok, err := stringValue(v)
+			if !ok || err != nil {
+				return spanner.NullDate{}, err
+			}
+			d, err := civil.ParseDate(s)
+			return spanner.NullDate{Date: d, Valid: err == nil}, err
+		}, nil
+	case sppb.TypeCode_TIMESTAMP:
+		return func(v *structpb.Value) (any, error) {
+			s, ok, err := stringValue(v)
+			if !ok || err != nil {
+				return spanner.NullTime{}, err
+			}
+			ts, err := time.Parse(time.RFC3339Nano, s)
+			return spanner.NullTime{Time: ts, Valid: err == nil}, err
+		}, nil
+	case sppb.TypeCode_BYTES:
+		return func(v *structpb.Value) (any, error) {
+			s, ok, err := stringValue(v)
+			if !ok || err != nil {
+				// NULL decodes to a nil slice
+				return []byte(nil), err
+			}
+			return base64.StdEncoding.DecodeString(s)
+		}, nil
+	case sppb.TypeCode_JSON:
+		// JSON keeps the spanner decoding, which defines how documents map to Go values
+		return func(v *structpb.Value) (any, error) {
+			var x spanner.NullJSON
+			err := spanner.GenericColumnValue{Type: t, Value: v}.Decode(&x)
+			return x, err
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported column type: %v", t.Code)
+}
+
+// stringValue returns the string encoding of a value; ok is false for NULL.
+func stringValue(v *structpb.Value) (s string, ok bool, err error) {
+	switch k := v.GetKind().(type) {
+	case *structpb.Value_NullValue:
+		return "", false, nil
+	case *structpb.Value_StringValue:
+		return k.StringValue, true, nil
+	}
+	return "", false, fmt.Errorf("unexpected encoding of value %v", v)
+}
+
+// decodeFloat64 decodes a FLOAT64, which non-finite values encode as strings.
+func decodeFloat64(v *structpb.Value) (any, error) {
+	switch k := v.GetKind().(type) {
+	case *structpb.Value_NullValue:
+		return spanner.NullFloat64{}, nil
+	case *structpb.Value_NumberValue:
+		return spanner.NullFloat64{Float64: k.NumberValue, Valid: true}, nil
+	case *structpb.Value_StringValue:
+		switch k.StringValue {
+		case "NaN":
+			return spanner.NullFloat64{Float64: math.NaN(), Valid: true}, nil
+		case "Infinity":
+			return spanner.NullFloat64{Float64: math.Inf(1), Valid: true}, nil
+		case "-Infinity":
+			return spanner.NullFloat64{Float64: math.Inf(-1), Valid: true}, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected encoding of FLOAT64 value %v", v)
+}
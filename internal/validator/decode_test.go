@@ -0,0 +1,140 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// TestDecodersMatchSpanner checks the direct decoders against the spanner decoding they
+// replace.
+func TestDecodersMatchSpanner(t *testing.T) {
+	null := structpb.NewNullValue()
+	tests := []struct {
+		code   sppb.TypeCode
+		values []*structpb.Value
+		target func() any
+	}{
+		{sppb.TypeCode_STRING, []*structpb.Value{null, structpb.NewStringValue(""), structpb.NewStringValue("héllo")}, func() any { return &spanner.NullString{} }},
+		{sppb.TypeCode_INT64, []*structpb.Value{null, structpb.NewStringValue("-9223372036854775808"), structpb.NewStringValue("42")}, func() any { return &spanner.NullInt64{} }},
+		{sppb.TypeCode_FLOAT64, []*structpb.Value{null, structpb.NewNumberValue(1.5), structpb.NewStringValue("NaN"), structpb.NewStringValue("-Infinity")}, func() any { return &spanner.NullFloat64{} }},
+		{sppb.TypeCode_BOOL, []*structpb.Value{null, structpb.NewBoolValue(true)}, func() any { return &spanner.NullBool{} }},
+		{sppb.TypeCode_NUMERIC, []*structpb.Value{null, structpb.NewStringValue("123.450000000"), structpb.NewStringValue("-0.5")}, func() any { return &spanner.NullNumeric{} }},
+		{sppb.TypeCode_DATE, []*structpb.Value{null, structpb.NewStringValue("2024-02-29")}, func() any { return &spanner.NullDate{} }},
+		{sppb.TypeCode_TIMESTAMP, []*structpb.Value{null, structpb.NewStringValue("2024-01-02T03:04:05.123456789Z")}, func() any { return &spanner.NullTime{} }},
+		{sppb.TypeCode_BYTES, []*structpb.Value{null, structpb.NewStringValue("aGVsbG8=")}, func() any { return &[]byte{} }},
+		{sppb.TypeCode_JSON, []*structpb.Value{null, structpb.NewStringValue(`{"a":[1,2]}`)}, func() any { return &spanner.NullJSON{} }},
+	}
+	for _, tt := range tests {
+		typ := &sppb.Type{Code: tt.code}
+		dec, err := decoderFor(typ)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.code, err)
+		}
+		for _, v := range tt.values {
+			got, err := dec(v)
+			if err != nil {
+				t.Errorf("%v %v: %v", tt.code, v, err)
+				continue
+			}
+			want := tt.target()
+			if err := (spanner.GenericColumnValue{Type: typ, Value: v}).Decode(want); err != nil {
+				t.Fatalf("%v %v: spanner decode: %v", tt.code, v, err)
+			}
+			// %#v renders NaN and big.Rat values comparably, unlike reflect.DeepEqual
+			w := reflect.ValueOf(want).Elem().Interface()
+			if g, w := fmt.Sprintf("%T %#v", got, got), fmt.Sprintf("%T %#v", w, w); g != w {
+				t.Errorf("%v %v: got %s, want %s", tt.code, v, g, w)
+			}
+		}
+	}
+
+	if _, err := decoderFor(&sppb.Type{Code: sppb.TypeCode_ARRAY}); err == nil {
+		t.Error("Expected ARRAY columns to be unsupported")
+	}
+	if _, err := decodeFloat64(structpb.NewStringValue("1.5")); err == nil {
+		t.Error("Expected finite FLOAT64 strings to be rejected")
+	}
+}
+
+// benchRow is a row with one column of each type the decoders handle.
+var benchRow = []spanner.GenericColumnValue{
+	{Type: &sppb.Type{Code: sppb.TypeCode_STRING}, Value: structpb.NewStringValue("order-0001")},
+	{Type: &sppb.Type{Code: sppb.TypeCode_INT64}, Value: structpb.NewStringValue("42")},
+	{Type: &sppb.Type{Code: sppb.TypeCode_FLOAT64}, Value: structpb.NewNumberValue(1.5)},
+	{Type: &sppb.Type{Code: sppb.TypeCode_BOOL}, Value: structpb.NewBoolValue(true)},
+	{Type: &sppb.Type{Code: sppb.TypeCode_NUMERIC}, Value: structpb.NewStringValue("123.450000000")},
+	{Type: &sppb.Type{Code: sppb.TypeCode_DATE}, Value: structpb.NewStringValue("2024-02-29")},
+	{Type: &sppb.Type{Code: sppb.TypeCode_TIMESTAMP}, Value: structpb.NewStringValue("2024-01-02T03:04:05Z")},
+	{Type: &sppb.Type{Code: sppb.TypeCode_STRING}, Value: structpb.NewNullValue()},
+}
+
+// trialDecode is the decoding the type-driven decoders replaced: each target is tried in
+// turn until spanner accepts one.
+func trialDecode(gcv spanner.GenericColumnValue) (any, error) {
+	targets := []func() any{
+		func() any { return &spanner.NullDate{} },
+		func() any { return &spanner.NullNumeric{} },
+		func() any { return &spanner.NullJSON{} },
+		func() any { return &spanner.NullString{} },
+		func() any { return &spanner.NullInt64{} },
+		func() any { return &spanner.NullFloat64{} },
+		func() any { return &spanner.NullBool{} },
+		func() any { return &spanner.NullTime{} },
+		func() any { return &[]byte{} },
+	}
+	for _, target := range targets {
+		p := target()
+		if err := gcv.Decode(p); err == nil {
+			return reflect.ValueOf(p).Elem().Interface(), nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported type %v", gcv.Type.Code)
+}
+
+// BenchmarkDecodeRow compares decoding a row with decoders resolved once from the column
+// types against trial decoding every value.
+func BenchmarkDecodeRow(b *testing.B) {
+	b.Run("typed", func(b *testing.B) {
+		decoders := make([]columnDecoder, len(benchRow))
+		for i, gcv := range benchRow {
+			dec, err := decoderFor(gcv.Type)
+			if err != nil {
+				b.Fatal(err)
+			}
+			decoders[i] = dec
+		}
+		b.ReportAllocs()
+		for b.Loop() {
+			for i, gcv := range benchRow {
+				if _, err := decoders[i](gcv.Value); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("per-value", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			for _, gcv := range benchRow {
+				if _, err := decodeGenericValue(&gcv); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("trial", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			for _, gcv := range benchRow {
+				if _, err := trialDecode(gcv); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
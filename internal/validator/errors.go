@@ -0,0 +1,11 @@
+package validator
+
+import "github.com/nu0ma/spalidate/internal/errkind"
+
+// Sentinel errors classifying validation failures, for errors.Is; see package errkind.
+var (
+	ErrConfig        = errkind.ErrConfig
+	ErrConnection    = errkind.ErrConnection
+	ErrTableNotFound = errkind.ErrTableNotFound
+	ErrRowMismatch   = errkind.ErrRowMismatch
+)
@@ -0,0 +1,50 @@
+package validator
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// exampleColumns is how many columns besides the primary key an example row shows.
+const exampleColumns = 4
+
+// rowExamples renders up to extraRowExamples of rows for an error message, e.g.
+// "; e.g. {ID=3, Name=Carol} (and 2 more)", or "" when there is nothing to show.
+func (v *Validator) rowExamples(rows []map[string]any, pk []string) string {
+	n := min(v.opts.ExtraRows(), len(rows))
+	if n == 0 {
+		return ""
+	}
+	examples := make([]string, n)
+	for i, row := range rows[:n] {
+		examples[i] = exampleRow(row, pk)
+	}
+	s := "; e.g. " + strings.Join(examples, ", ")
+	if more := len(rows) - n; more > 0 {
+		s += fmt.Sprintf(" (and %d more)", more)
+	}
+	return s
+}
+
+// exampleRow renders the primary key columns of a row, then its first other columns by name.
+func exampleRow(row map[string]any, pk []string) string {
+	var parts []string
+	for _, col := range pk {
+		parts = append(parts, col+"="+valueToPretty(row[col]))
+	}
+	var shown int
+	for _, col := range slices.Sorted(maps.Keys(row)) {
+		if slices.Contains(pk, col) {
+			continue
+		}
+		if shown == exampleColumns {
+			parts = append(parts, "...")
+			break
+		}
+		parts = append(parts, col+"="+valueToPretty(row[col]))
+		shown++
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
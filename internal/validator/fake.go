@@ -0,0 +1,115 @@
+package validator
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/nu0ma/spalidate/internal/errkind"
+	spannerClient "github.com/nu0ma/spalidate/internal/spanner"
+)
+
+// FakeDatabase is an in-memory Database, so code that wires up validation can be unit
+// tested without an emulator. It cannot run SQL: Read does not support Where filters, and
+// Query only answers the statements registered in Queries.
+type FakeDatabase struct {
+	// Tables holds the rows of each table. Values are what Spanner reads decode to
+	// (spanner.NullInt64, spanner.NullString, ...) or plain Go values.
+	Tables map[string][]map[string]any
+	// PrimaryKeys holds the primary key columns of each table.
+	PrimaryKeys map[string][]string
+	// Generated holds the generated columns of each table.
+	Generated map[string]map[string]spannerClient.GeneratedColumn
+	// Checks holds the CHECK constraints of each table, mapping names to expressions.
+	Checks map[string]map[string]string
+	// Options holds the options set on the database.
+	Options map[string]string
+	// Queries maps SQL text to the rows it returns.
+	Queries map[string][]map[string]any
+	// ChangeStreams holds the data changes of each change stream.
+	ChangeStreams map[string][]spannerClient.DataChange
+}
+
+var _ Database = (*FakeDatabase)(nil)
+
+// Read returns a copy of the table's rows, sorted by opts.OrderBy. Values are ordered as
+// numbers when both are numeric, else by their text.
+func (f *FakeDatabase) Read(_ context.Context, table string, opts ReadOptions) ([]map[string]any, error) {
+	rows, ok := f.Tables[table]
+	if !ok {
+		return nil, errkind.Mark(fmt.Errorf("fake: table %s not found", table), errkind.ErrTableNotFound)
+	}
+	if opts.Where != "" {
+		return nil, fmt.Errorf("fake: where filters are not supported (table %s)", table)
+	}
+	rows = slices.Clone(rows)
+	if len(opts.Exclude) > 0 {
+		for i, row := range rows {
+			rows[i] = maps.Clone(row)
+		}
+		rows = excludeColumns(rows, opts.Exclude)
+	}
+	slices.SortStableFunc(rows, func(a, b map[string]any) int {
+		for _, col := range opts.OrderBy {
+			if c := compareFakeValues(a[col], b[col]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return rows, nil
+}
+
+func compareFakeValues(a, b any) int {
+	as, bs := valueToPretty(a), valueToPretty(b)
+	af, aerr := strconv.ParseFloat(as, 64)
+	bf, berr := strconv.ParseFloat(bs, 64)
+	if aerr == nil && berr == nil {
+		return cmp.Compare(af, bf)
+	}
+	return cmp.Compare(as, bs)
+}
+
+// Query returns the rows registered for sql; params are ignored.
+func (f *FakeDatabase) Query(_ context.Context, sql string, _ map[string]any) ([]map[string]any, error) {
+	rows, ok := f.Queries[sql]
+	if !ok {
+		return nil, fmt.Errorf("fake: no result registered for query %q", sql)
+	}
+	return rows, nil
+}
+
+func (f *FakeDatabase) TableSchema(_ context.Context, table string) (TableSchema, error) {
+	pk, ok := f.PrimaryKeys[table]
+	if !ok {
+		return TableSchema{}, errkind.Mark(fmt.Errorf("fake: no primary key for table %s", table), errkind.ErrTableNotFound)
+	}
+	return TableSchema{PrimaryKey: pk}, nil
+}
+
+func (f *FakeDatabase) GeneratedColumns(_ context.Context, table string) (map[string]spannerClient.GeneratedColumn, error) {
+	return f.Generated[table], nil
+}
+
+func (f *FakeDatabase) CheckConstraints(_ context.Context, table string) (map[string]string, error) {
+	return f.Checks[table], nil
+}
+
+func (f *FakeDatabase) DatabaseOptions(context.Context) (map[string]string, error) {
+	return f.Options, nil
+}
+
+// ReadChangeStream returns the stream's changes committed after start and up to end.
+func (f *FakeDatabase) ReadChangeStream(_ context.Context, stream string, start, end time.Time) ([]spannerClient.DataChange, error) {
+	var changes []spannerClient.DataChange
+	for _, c := range f.ChangeStreams[stream] {
+		if c.CommitTimestamp.After(start) && !c.CommitTimestamp.After(end) {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,162 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/nu0ma/spalidate/internal/config"
+	spannerClient "github.com/nu0ma/spalidate/internal/spanner"
+)
+
+func TestFakeDatabase(t *testing.T) {
+	cfg, err := config.Parse([]byte(`tables:
+  Users:
+    strategy: ordered
+    columns:
+      - {ID: 1, Name: Alice}
+      - {ID: 2, Name: Bob}
+      - {ID: 10, Name: Carol}
+  Orders:
+    count: 3
+changeStreams:
+  UsersStream:
+    window: 1m
+    records:
+      - {table: Users, modType: INSERT, keys: {ID: 10}}
+`), ".")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	db := &FakeDatabase{
+		Tables: map[string][]map[string]any{"Users": {
+			{"ID": spanner.NullInt64{Int64: 10, Valid: true}, "Name": spanner.NullString{StringVal: "Carol", Valid: true}},
+			{"ID": spanner.NullInt64{Int64: 2, Valid: true}, "Name": spanner.NullString{StringVal: "Bob", Valid: true}},
+			{"ID": spanner.NullInt64{Int64: 1, Valid: true}, "Name": spanner.NullString{StringVal: "Alice", Valid: true}},
+		}},
+		PrimaryKeys: map[string][]string{"Users": {"ID"}},
+		Queries: map[string][]map[string]any{
+			"SELECT COUNT(*) AS n FROM Orders": {{"n": spanner.NullInt64{Int64: 3, Valid: true}}},
+		},
+		ChangeStreams: map[string][]spannerClient.DataChange{"UsersStream": {
+			{CommitTimestamp: time.Now().Add(-time.Hour), Table: "Users", ModType: "INSERT", Keys: map[string]any{"ID": "10"}},
+		}},
+	}
+
+	res := NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background())
+	failed := res.Failed()
+	if len(res.Tables) != 3 || len(failed) != 1 || failed[0].Table != "changeStream:UsersStream" {
+		t.Fatalf("Expected only the change stream to fail, got %+v", res.Tables)
+	}
+
+	db.ChangeStreams["UsersStream"][0].CommitTimestamp = time.Now().Add(-time.Second)
+	if err := NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background()).Err(); err != nil {
+		t.Errorf("Expected the run to pass, got %v", err)
+	}
+
+	if _, err := db.Read(context.Background(), "Users", ReadOptions{Where: "ID > 1"}); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Expected where filters to be rejected, got %v", err)
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	if _, err := config.Parse([]byte("tables: {T: {strategy: sideways}}"), "."); !errors.Is(err, ErrConfig) {
+		t.Errorf("Expected ErrConfig from Parse, got %v", err)
+	}
+
+	cfg, err := config.Parse([]byte(`tables:
+  Users:
+    columns:
+      - {ID: 1}
+  Missing:
+    columns:
+      - {ID: 1}
+`), ".")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	db := &FakeDatabase{Tables: map[string][]map[string]any{"Users": {{"ID": spanner.NullInt64{Int64: 2, Valid: true}}}}}
+	res := NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background())
+	for _, tt := range []struct {
+		table string
+		want  error
+	}{
+		{"Users", ErrRowMismatch},
+		{"Missing", ErrTableNotFound},
+	} {
+		for _, tr := range res.Tables {
+			if tr.Table == tt.table && !errors.Is(tr.Err, tt.want) {
+				t.Errorf("Expected %v for table %s, got %v", tt.want, tt.table, tr.Err)
+			}
+		}
+	}
+	err = res.Err()
+	if !errors.Is(err, ErrRowMismatch) || !errors.Is(err, ErrTableNotFound) || errors.Is(err, ErrConnection) {
+		t.Errorf("Expected the run error to carry both table errors, got %v", err)
+	}
+	if ReportOf(err) == "" {
+		t.Error("Expected the mismatch report to stay reachable from the run error")
+	}
+}
+
+// pgDatabase is a FakeDatabase reporting the PostgreSQL dialect.
+type pgDatabase struct{ *FakeDatabase }
+
+func (pgDatabase) Dialect(context.Context) (spannerClient.Dialect, error) {
+	return spannerClient.DialectPostgreSQL, nil
+}
+
+func TestPostgreSQLQueries(t *testing.T) {
+	cfg, err := config.Parse([]byte(`tables:
+  Orders:
+    count: 2
+    where: '"Status" = $1'
+    params: {p1: shipped}
+    columnBounds:
+      Price: {min: 0}
+`), ".")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	db := pgDatabase{&FakeDatabase{Queries: map[string][]map[string]any{
+		`SELECT COUNT(*) AS n FROM "Orders" WHERE "Status" = $1`: {{"n": spanner.NullInt64{Int64: 2, Valid: true}}},
+		`SELECT MIN("Price") AS min_0, MAX("Price") AS max_0 FROM "Orders" WHERE "Status" = $1`: {{
+			"min_0": spanner.NullInt64{Int64: 5, Valid: true}, "max_0": spanner.NullInt64{Int64: 9, Valid: true},
+		}},
+	}}}
+	if err := NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background()).Err(); err != nil {
+		t.Errorf("Expected quoted PostgreSQL queries, got %v", err)
+	}
+}
+
+func TestRowsByKey(t *testing.T) {
+	cfg, err := config.Parse([]byte(`tables:
+  Users:
+    rowsByKey:
+      user-001: {Name: Alice}
+      user-002: {Name: Bobby}
+`), ".")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	str := func(s string) spanner.NullString { return spanner.NullString{StringVal: s, Valid: true} }
+	db := &FakeDatabase{
+		Tables: map[string][]map[string]any{"Users": {
+			{"UserID": str("user-001"), "Name": str("Alice")},
+			{"UserID": str("user-002"), "Name": str("Bob")},
+		}},
+		PrimaryKeys: map[string][]string{"Users": {"UserID"}},
+	}
+	err = NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background()).Err()
+	if err == nil || !strings.Contains(err.Error(), "line 5: row with primary key user-002 does not match") {
+		t.Errorf("Expected the row of user-002 to differ, got %v", err)
+	}
+
+	db.PrimaryKeys["Users"] = []string{"TenantID", "UserID"}
+	if err := NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background()).Err(); !errors.Is(err, ErrConfig) {
+		t.Errorf("Expected a composite primary key to be a config error, got %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package validator
+
+// glyphSet holds the markers used by report builders.
+type glyphSet struct {
+	fail, pass, bullet, columns, example, minus, plus, arrow string
+}
+
+var (
+	unicodeGlyphs = glyphSet{fail: "✖️", pass: "✅", bullet: "▸", columns: "🧩", example: "🔎", minus: "➖", plus: "➕", arrow: "→"}
+	asciiGlyphs   = glyphSet{fail: "[FAIL]", pass: "[OK]", bullet: "-", columns: "*", example: "*", minus: "-", plus: "+", arrow: "->"}
+
+	glyphs = unicodeGlyphs
+)
+
+// SetASCII switches all reports to plain ASCII markers, for terminals and CI logs that
+// cannot render emoji.
+func SetASCII(ascii bool) {
+	if ascii {
+		glyphs = asciiGlyphs
+	} else {
+		glyphs = unicodeGlyphs
+	}
+}
@@ -0,0 +1,126 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/logging"
+	spannerClient "github.com/nu0ma/spalidate/internal/spanner"
+)
+
+// IndexDivergence describes how a secondary index disagrees with its base table.
+type IndexDivergence struct {
+	Table string
+	Index string
+	// BaseRows and IndexRows count the rows read through the base table and the index.
+	BaseRows, IndexRows int
+	// OnlyInBase and OnlyInIndex hold formatted primary keys present on one side only.
+	OnlyInBase  []string
+	OnlyInIndex []string
+}
+
+// Diverged reports whether the index and the base table disagree.
+func (d IndexDivergence) Diverged() bool {
+	return len(d.OnlyInBase) > 0 || len(d.OnlyInIndex) > 0
+}
+
+// CheckIndexes reads the primary keys of every configured table once from the base table
+// and once through each secondary index (FORCE_INDEX), and reports the keys found on one
+// side only. Rows of NULL_FILTERED indexes with a NULL key column are excluded on both sides.
+func CheckIndexes(ctx context.Context, cfg *config.Config, client *spannerClient.Client) ([]IndexDivergence, error) {
+	v := NewValidator(cfg, client)
+
+	var results []IndexDivergence
+	for _, tableName := range sortedTableNames(cfg.Tables) {
+		tableConfig := cfg.Tables[tableName]
+		enabled, err := tableConfig.Enabled()
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", tableName, err)
+		}
+		if !enabled {
+			logging.L().Info("Skipping table", "table", tableName, "when", tableConfig.When)
+			continue
+		}
+
+		pk, err := client.PrimaryKeyColumns(ctx, tableName)
+		if err != nil {
+			return nil, err
+		}
+		indexes, err := client.SecondaryIndexes(ctx, tableName)
+		if err != nil {
+			return nil, err
+		}
+		for _, idx := range indexes {
+			d, err := v.checkIndex(ctx, tableName, pk, idx)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, d)
+		}
+	}
+	return results, nil
+}
+
+func (v *Validator) checkIndex(ctx context.Context, tableName string, pk []string, idx spannerClient.Index) (IndexDivergence, error) {
+	dialect, err := v.dialect(ctx)
+	if err != nil {
+		return IndexDivergence{}, err
+	}
+	var where string
+	if idx.NullFiltered {
+		conds := make([]string, len(idx.Columns))
+		for i, c := range idx.Columns {
+			conds[i] = dialect.Ident(c) + " IS NOT NULL"
+		}
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+	quoted := make([]string, len(pk))
+	for i, c := range pk {
+		quoted[i] = dialect.Ident(c)
+	}
+	cols := strings.Join(quoted, ", ")
+	base, err := v.queryRows(ctx, tableName, fmt.Sprintf("SELECT %s FROM %s%s", cols, dialect.Ident(tableName), where), nil)
+	if err != nil {
+		return IndexDivergence{}, fmt.Errorf("reading table %s: %w", tableName, err)
+	}
+	indexed, err := v.queryRows(ctx, tableName, fmt.Sprintf("SELECT %s FROM %s%s", cols, dialect.ForceIndex(tableName, idx.Name), where), nil)
+	if err != nil {
+		return IndexDivergence{}, fmt.Errorf("reading index %s of table %s: %w", idx.Name, tableName, err)
+	}
+	d := IndexDivergence{Table: tableName, Index: idx.Name, BaseRows: len(base), IndexRows: len(indexed)}
+	d.OnlyInBase, d.OnlyInIndex = diffRowsets(base, indexed)
+	return d, nil
+}
+
+func buildIndexReport(d IndexDivergence) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s index %s on table %s: diverges from the table (%d rows in the table, %d in the index)\n",
+		glyphs.fail, d.Index, d.Table, d.BaseRows, d.IndexRows)
+	for _, r := range d.OnlyInBase {
+		fmt.Fprintf(&b, "     %s only in table: %s\n", glyphs.bullet, r)
+	}
+	for _, r := range d.OnlyInIndex {
+		fmt.Fprintf(&b, "     %s only in index: %s\n", glyphs.bullet, r)
+	}
+	return b.String()
+}
+
+// ReportIndexDivergences writes a report for every diverging index to w and returns an error if any diverged.
+func ReportIndexDivergences(w io.Writer, results []IndexDivergence) error {
+	var diverged []string
+	for _, d := range results {
+		if !d.Diverged() {
+			logging.L().Debug("Index consistent", "table", d.Table, "index", d.Index, "rows", d.BaseRows)
+			continue
+		}
+		fmt.Fprint(w, buildIndexReport(d))
+		diverged = append(diverged, d.Index)
+	}
+	if len(diverged) > 0 {
+		return fmt.Errorf("indexes diverge from their tables: %s", strings.Join(diverged, ", "))
+	}
+	return nil
+}
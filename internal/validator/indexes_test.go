@@ -0,0 +1,32 @@
+package validator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReportIndexDivergences(t *testing.T) {
+	base := []map[string]any{{"ID": int64(1)}, {"ID": int64(2)}, {"ID": int64(3)}}
+	indexed := []map[string]any{{"ID": int64(1)}, {"ID": int64(3)}, {"ID": int64(4)}}
+	d := IndexDivergence{Table: "Users", Index: "UsersByEmail", BaseRows: len(base), IndexRows: len(indexed)}
+	d.OnlyInBase, d.OnlyInIndex = diffRowsets(base, indexed)
+	consistent := IndexDivergence{Table: "Users", Index: "UsersByName", BaseRows: 3, IndexRows: 3}
+
+	var buf bytes.Buffer
+	err := ReportIndexDivergences(&buf, []IndexDivergence{consistent, d})
+	if err == nil || !strings.Contains(err.Error(), "UsersByEmail") || strings.Contains(err.Error(), "UsersByName") {
+		t.Errorf("Expected only UsersByEmail to be reported, got %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"only in table: {ID: 2}", "only in index: {ID: 4}", "3 rows in the table, 3 in the index"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := ReportIndexDivergences(&buf, []IndexDivergence{consistent}); err != nil || buf.Len() != 0 {
+		t.Errorf("Expected no report for consistent indexes, got %v %q", err, buf.String())
+	}
+}
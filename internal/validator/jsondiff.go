@@ -0,0 +1,104 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nu0ma/spalidate/internal/config"
+)
+
+// maxJSONDiffs caps the differences listed for one JSON value.
+const maxJSONDiffs = 10
+
+// maxJSONDiffValue caps the length of a value shown in a JSON difference.
+const maxJSONDiffValue = 80
+
+// jsonDiff lists the paths where the normalized JSON values differ, e.g.
+// `changed $.items[1].qty: 2 -> 3`. "added" paths exist only in the actual value and
+// "removed" paths only in the expected value.
+func jsonDiff(a, e any, opts config.ComparisonOptions) []string {
+	var diffs []string
+	collectJSONDiff(&diffs, "$", a, e, opts)
+	return diffs
+}
+
+func collectJSONDiff(diffs *[]string, path string, a, e any, opts config.ComparisonOptions) {
+	if jsonEqual(a, e, opts) {
+		return
+	}
+	switch ev := e.(type) {
+	case map[string]any:
+		av, ok := a.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(av)+len(ev))
+		for k := range ev {
+			keys = append(keys, k)
+		}
+		for k := range av {
+			if _, ok := ev[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := path + "." + k
+			aa, inActual := av[k]
+			ee, inExpected := ev[k]
+			switch {
+			case !inExpected:
+				*diffs = append(*diffs, fmt.Sprintf("added %s: %s", p, jsonSnippet(aa)))
+			case !inActual:
+				*diffs = append(*diffs, fmt.Sprintf("removed %s: %s", p, jsonSnippet(ee)))
+			default:
+				collectJSONDiff(diffs, p, aa, ee, opts)
+			}
+		}
+		return
+	case []any:
+		av, ok := a.([]any)
+		if !ok || opts.JSONArrayOrder == config.JSONArrayOrderIgnore {
+			// unordered arrays have no positions to point at
+			break
+		}
+		for i := 0; i < len(av) || i < len(ev); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(ev):
+				*diffs = append(*diffs, fmt.Sprintf("added %s: %s", p, jsonSnippet(av[i])))
+			case i >= len(av):
+				*diffs = append(*diffs, fmt.Sprintf("removed %s: %s", p, jsonSnippet(ev[i])))
+			default:
+				collectJSONDiff(diffs, p, av[i], ev[i], opts)
+			}
+		}
+		return
+	}
+	*diffs = append(*diffs, fmt.Sprintf("changed %s: %s -> %s", path, jsonSnippet(e), jsonSnippet(a)))
+}
+
+// jsonDiffError describes a JSON mismatch by its differing paths rather than by both documents.
+func jsonDiffError(a, e any, opts config.ComparisonOptions) error {
+	diffs := jsonDiff(a, e, opts)
+	n := len(diffs)
+	if n > maxJSONDiffs {
+		diffs = append(diffs[:maxJSONDiffs], fmt.Sprintf("... %d more", n-maxJSONDiffs))
+	}
+	return fmt.Errorf("JSON mismatch (%d differences, expected -> actual): %s", n, strings.Join(diffs, "; "))
+}
+
+// jsonSnippet renders a JSON value on one line, shortened to maxJSONDiffValue characters.
+func jsonSnippet(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	s := string(b)
+	if len(s) > maxJSONDiffValue {
+		s = s[:maxJSONDiffValue] + "..."
+	}
+	return s
+}
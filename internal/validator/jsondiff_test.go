@@ -0,0 +1,49 @@
+package validator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nu0ma/spalidate/internal/config"
+)
+
+func TestJSONDiff(t *testing.T) {
+	actual := map[string]any{
+		"name":  "widget",
+		"price": 12.5,
+		"tags":  []any{"a", "c", "d"},
+		"meta":  map[string]any{"color": "red", "size": "L"},
+	}
+	expected := map[string]any{
+		"name":  "widget",
+		"price": 10.0,
+		"tags":  []any{"a", "b"},
+		"meta":  map[string]any{"color": "red", "weight": 3.0},
+	}
+	want := []string{
+		`added $.meta.size: "L"`,
+		`removed $.meta.weight: 3`,
+		`changed $.price: 10 -> 12.5`,
+		`changed $.tags[1]: "b" -> "c"`,
+		`added $.tags[2]: "d"`,
+	}
+	if got := jsonDiff(actual, expected, config.ComparisonOptions{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("jsonDiff() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	unordered := config.ComparisonOptions{JSONArrayOrder: config.JSONArrayOrderIgnore}
+	got := jsonDiff(map[string]any{"tags": []any{"b", "a"}}, map[string]any{"tags": []any{"a", "c"}}, unordered)
+	if len(got) != 1 || !strings.HasPrefix(got[0], "changed $.tags: ") {
+		t.Errorf("Expected an unordered array to be reported as a whole, got %v", got)
+	}
+
+	long := make(map[string]any)
+	for i := 0; i < 2*maxJSONDiffs; i++ {
+		long[string(rune('a'+i))] = float64(i)
+	}
+	err := compareJSON(long, map[string]any{}, config.ComparisonOptions{})
+	if err == nil || !strings.Contains(err.Error(), "20 differences") || !strings.Contains(err.Error(), "... 10 more") {
+		t.Errorf("Expected a capped difference list, got %v", err)
+	}
+}
@@ -0,0 +1,266 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+	"unicode/utf8"
+
+	"cloud.google.com/go/civil"
+	"cloud.google.com/go/spanner"
+
+	"github.com/nu0ma/spalidate/internal/config"
+)
+
+// validateMatcher evaluates expected values that are matchers rather than literal values.
+// It reports handled=false when expectedData is a plain value.
+func (v *Validator) validateMatcher(record any, expectedData any) (handled bool, err error) {
+	switch m := expectedData.(type) {
+	case config.Any:
+		if b, ok := record.([]byte); isNull(record) || ok && b == nil {
+			return true, fmt.Errorf("expected any value, got NULL")
+		}
+		return true, nil
+	case config.OneOf:
+		for _, candidate := range m.Values {
+			if v.validateData(record, candidate) == nil {
+				return true, nil
+			}
+		}
+		return true, fmt.Errorf("value %s is not %s", valueToPretty(record), m)
+	case config.StrLen:
+		n, ok := valueLength(record)
+		if !ok {
+			return true, typeMismatchError("string or bytes", record)
+		}
+		if (m.Min != nil && n < *m.Min) || (m.Max != nil && n > *m.Max) {
+			return true, fmt.Errorf("length %d does not satisfy %s", n, m)
+		}
+		return true, nil
+	case config.Regex:
+		var str string
+		switch r := record.(type) {
+		case spanner.NullString:
+			if !r.Valid {
+				return true, fmt.Errorf("expected a value matching %s, got NULL", m)
+			}
+			str = r.StringVal
+		case string:
+			str = r
+		default:
+			return true, typeMismatchError("string", record)
+		}
+		if !m.MatchString(str) {
+			return true, fmt.Errorf("value %s does not match %s", valueToPretty(record), m)
+		}
+		return true, nil
+	case config.Range:
+		return true, v.validateRange(record, m)
+	case config.FloatSpecial:
+		f, ok := floatValue(record)
+		if !ok {
+			return true, typeMismatchError("float64", record)
+		}
+		var match bool
+		switch m {
+		case config.NaN:
+			match = math.IsNaN(f)
+		case config.PosInf:
+			match = math.IsInf(f, 1)
+		case config.NegInf:
+			match = math.IsInf(f, -1)
+		}
+		if !match {
+			return true, valueMismatchError(f, m)
+		}
+		return true, nil
+	case config.File:
+		content, err := valueContent(record)
+		if err != nil {
+			return true, err
+		}
+		if content == nil {
+			return true, fmt.Errorf("expected contents of %s, got NULL", m.Path)
+		}
+		if digest := sha256Hex(content); int64(len(content)) != m.Size || digest != m.SHA256 {
+			return true, fmt.Errorf("value differs from %s: actual %d bytes (sha256 %s), file %d bytes (sha256 %s)",
+				m.Path, len(content), digest, m.Size, m.SHA256)
+		}
+		return true, nil
+	case config.SHA256:
+		content, err := valueContent(record)
+		if err != nil {
+			return true, err
+		}
+		if content == nil {
+			return true, fmt.Errorf("expected %s, got NULL", m)
+		}
+		if digest := sha256Hex(content); digest != string(m) {
+			return true, fmt.Errorf("digest mismatch: actual %d bytes with sha256 %s, expected sha256 %s", len(content), digest, string(m))
+		}
+		return true, nil
+	case config.DateBetween:
+		d, ok := dateValue(record)
+		if !ok {
+			return true, typeMismatchError("date", record)
+		}
+		if (m.From != nil && d.Before(*m.From)) || (m.To != nil && d.After(*m.To)) {
+			return true, fmt.Errorf("date %s does not satisfy %s", d, m)
+		}
+		return true, nil
+	case config.Today:
+		d, ok := dateValue(record)
+		if !ok {
+			return true, typeMismatchError("date", record)
+		}
+		if today := civil.DateOf(now().In(m.Location)); d != today {
+			return true, valueMismatchError(d, fmt.Sprintf("%s (%s)", today, m))
+		}
+		return true, nil
+	case config.Recent:
+		ts, ok := timeValue(record)
+		if !ok {
+			return true, typeMismatchError("timestamp", record)
+		}
+		at := now()
+		if d := at.Sub(ts); d > m.Within || d < -m.Within {
+			return true, fmt.Errorf("timestamp %s is not within %s of %s", ts.Format(time.RFC3339Nano), m.Within, at.Format(time.RFC3339Nano))
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// valueContent returns the bytes digested by `!file` and `!sha256`: BYTES as is, STRING
+// as UTF-8 and JSON in compact form with sorted keys. NULL values return nil.
+func valueContent(record any) ([]byte, error) {
+	switch r := record.(type) {
+	case []byte:
+		return r, nil
+	case spanner.NullString:
+		if !r.Valid {
+			return nil, nil
+		}
+		return []byte(r.StringVal), nil
+	case string:
+		return []byte(r), nil
+	case spanner.NullJSON:
+		if !r.Valid {
+			return nil, nil
+		}
+		return json.Marshal(r.Value)
+	}
+	return nil, typeMismatchError("bytes, string or json", record)
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// now is the clock used by `!today`.
+var now = time.Now
+
+// dateValue returns a non-NULL DATE value.
+func dateValue(record any) (civil.Date, bool) {
+	switch r := record.(type) {
+	case spanner.NullDate:
+		return r.Date, r.Valid
+	case civil.Date:
+		return r, true
+	}
+	return civil.Date{}, false
+}
+
+// validateRange checks a value against the bounds of a `!range` or `!between` matcher.
+func (v *Validator) validateRange(record any, m config.Range) error {
+	actual := nullable(record)
+	for _, b := range []struct {
+		limit any
+		fails func(c int) bool
+		word  string
+	}{
+		{m.Gt, func(c int) bool { return c <= 0 }, "greater than"},
+		{m.Gte, func(c int) bool { return c < 0 }, "at least"},
+		{m.Lt, func(c int) bool { return c >= 0 }, "less than"},
+		{m.Lte, func(c int) bool { return c > 0 }, "at most"},
+	} {
+		if b.limit == nil {
+			continue
+		}
+		c, ok, err := compareOrdered(actual, b.limit)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// NULL, or a NaN, which is unordered
+			return fmt.Errorf("value %s is not in %s", valueToPretty(record), m)
+		}
+		if b.fails(c) {
+			return fmt.Errorf("value %s is not %s %s", valueToPretty(record), b.word, valueToPretty(b.limit))
+		}
+	}
+	return nil
+}
+
+// nullable returns the Spanner Null type of a non-NULL value, as compareOrdered expects.
+func nullable(record any) any {
+	switch r := record.(type) {
+	case int64:
+		return spanner.NullInt64{Int64: r, Valid: true}
+	case float64:
+		return spanner.NullFloat64{Float64: r, Valid: true}
+	case string:
+		return spanner.NullString{StringVal: r, Valid: true}
+	case time.Time:
+		return spanner.NullTime{Time: r, Valid: true}
+	case civil.Date:
+		return spanner.NullDate{Date: r, Valid: true}
+	}
+	return record
+}
+
+// timeValue returns a non-NULL TIMESTAMP value.
+func timeValue(record any) (time.Time, bool) {
+	switch r := record.(type) {
+	case spanner.NullTime:
+		return r.Time, r.Valid
+	case time.Time:
+		return r, true
+	}
+	return time.Time{}, false
+}
+
+// floatValue returns a non-NULL FLOAT64 value.
+func floatValue(record any) (float64, bool) {
+	switch r := record.(type) {
+	case spanner.NullFloat64:
+		return r.Float64, r.Valid
+	case float64:
+		return r, true
+	}
+	return 0, false
+}
+
+// valueLength returns the character count of a STRING or the byte count of a BYTES value.
+// NULL values have no length.
+func valueLength(record any) (int, bool) {
+	switch r := record.(type) {
+	case spanner.NullString:
+		if !r.Valid {
+			return 0, false
+		}
+		return utf8.RuneCountInString(r.StringVal), true
+	case string:
+		return utf8.RuneCountInString(r), true
+	case []byte:
+		if r == nil {
+			return 0, false
+		}
+		return len(r), true
+	}
+	return 0, false
+}
@@ -0,0 +1,399 @@
+package validator
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"cloud.google.com/go/spanner"
+	"github.com/nu0ma/spalidate/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// decodeExpected parses a single-row YAML snippet the same way configs are loaded.
+func decodeExpected(t *testing.T, src string) map[string]any {
+	t.Helper()
+	var rows config.Rows
+	if err := yaml.Unmarshal([]byte(src), &rows); err != nil {
+		t.Fatalf("failed to decode %q: %v", src, err)
+	}
+	return rows[0]
+}
+
+func TestOneOfMatcher(t *testing.T) {
+	v := NewValidator(&config.Config{}, nil)
+	exp := decodeExpected(t, `- Status: !oneOf ["pending", "processing"]`)
+
+	if err := v.validateData(spanner.NullString{StringVal: "processing", Valid: true}, exp["Status"]); err != nil {
+		t.Errorf("Expected processing to match: %v", err)
+	}
+	if err := v.validateData(spanner.NullString{StringVal: "done", Valid: true}, exp["Status"]); err == nil {
+		t.Error("Expected done not to match")
+	}
+
+	exp = decodeExpected(t, `- Count: !oneOf [1, 2, null]`)
+	if err := v.validateData(spanner.NullInt64{}, exp["Count"]); err != nil {
+		t.Errorf("Expected NULL to match: %v", err)
+	}
+}
+
+func TestStrLenMatcher(t *testing.T) {
+	v := NewValidator(&config.Config{}, nil)
+	exp := decodeExpected(t, `- Hash: !strlen {min: 3, max: 5}
+  Token: !strlen 4`)
+
+	tests := []struct {
+		col     string
+		actual  any
+		wantErr bool
+	}{
+		{"Hash", spanner.NullString{StringVal: "abcd", Valid: true}, false},
+		{"Hash", spanner.NullString{StringVal: "ab", Valid: true}, true},
+		{"Hash", spanner.NullString{StringVal: "日本語", Valid: true}, false},
+		{"Hash", spanner.NullString{}, true},
+		{"Token", []byte{1, 2, 3, 4}, false},
+		{"Token", []byte{1, 2, 3}, true},
+		{"Token", int64(4), true},
+	}
+	for _, tt := range tests {
+		err := v.validateData(tt.actual, exp[tt.col])
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s %v: got err=%v, wantErr=%v", tt.col, tt.actual, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRegexMatcher(t *testing.T) {
+	v := NewValidator(&config.Config{}, nil)
+	exp := decodeExpected(t, `- Email: !re "^user-\\d+@example\\.com$"`)
+
+	tests := []struct {
+		actual  any
+		wantErr string
+	}{
+		{spanner.NullString{StringVal: "user-42@example.com", Valid: true}, ""},
+		{"user-7@example.com", ""},
+		{spanner.NullString{StringVal: "admin@example.com", Valid: true}, `does not match re(^user-\d+@example\.com$)`},
+		{spanner.NullString{}, "got NULL"},
+		{spanner.NullInt64{Int64: 1, Valid: true}, "expected string"},
+	}
+	for _, tt := range tests {
+		err := v.validateData(tt.actual, exp["Email"])
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%v: got err=%v, want %q", tt.actual, err, tt.wantErr)
+		}
+	}
+
+	out, err := yaml.Marshal(config.Rows{exp})
+	if err != nil || !strings.Contains(string(out), `!re ^user-\d+@example\.com$`) {
+		t.Errorf("Expected !re to round-trip, got %q (%v)", out, err)
+	}
+	var rows config.Rows
+	if err := yaml.Unmarshal([]byte(`- Email: !re "a("`), &rows); err == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+}
+
+func TestAnyMatcher(t *testing.T) {
+	v := NewValidator(&config.Config{}, nil)
+	exp := decodeExpected(t, `- CreatedAt: !any`)
+
+	tests := []struct {
+		actual  any
+		wantErr bool
+	}{
+		{spanner.NullTime{Time: time.Now(), Valid: true}, false},
+		{spanner.NullInt64{Int64: 0, Valid: true}, false},
+		{[]byte{}, false},
+		{spanner.NullTime{}, true},
+		{[]byte(nil), true},
+		{nil, true},
+	}
+	for _, tt := range tests {
+		if err := v.validateData(tt.actual, exp["CreatedAt"]); (err != nil) != tt.wantErr {
+			t.Errorf("%#v: got err=%v, wantErr %v", tt.actual, err, tt.wantErr)
+		}
+	}
+
+	out, err := yaml.Marshal(config.Rows{exp})
+	if err != nil || !strings.Contains(string(out), "CreatedAt: !any") {
+		t.Errorf("Expected !any to round-trip, got %q (%v)", out, err)
+	}
+	var rows config.Rows
+	if err := yaml.Unmarshal([]byte(`- CreatedAt: !any 1`), &rows); err == nil {
+		t.Error("Expected !any with a value to be rejected")
+	}
+}
+
+func TestAnyRowWildcard(t *testing.T) {
+	v := NewValidator(&config.Config{}, nil)
+	var rows config.Rows
+	src := `
+- UserID: "user-001"
+  Name: "Alice"
+- !anyRow {count: 2}
+`
+	if err := yaml.Unmarshal([]byte(src), &rows); err != nil {
+		t.Fatalf("failed to decode rows: %v", err)
+	}
+	expected, anyRows := rows.Split()
+	if len(expected) != 1 || anyRows != 2 {
+		t.Fatalf("Split() = %d rows, %d wildcards; want 1, 2", len(expected), anyRows)
+	}
+
+	row := func(id, name string) map[string]any {
+		return map[string]any{
+			"UserID": spanner.NullString{StringVal: id, Valid: true},
+			"Name":   spanner.NullString{StringVal: name, Valid: true},
+		}
+	}
+	actual := []map[string]any{row("user-002", "Bob"), row("user-001", "Alice"), row("user-003", "Carol")}
+	if err := v.validateStrictRowset("Users", actual, expected, anyRows); err != nil {
+		t.Errorf("Expected rows to match with wildcards: %v", err)
+	}
+	if err := v.validateStrictRowset("Users", actual[:2], expected, anyRows); err == nil {
+		t.Error("Expected row count mismatch with too few rows")
+	}
+	actual[1] = row("user-001", "Alicia")
+	if err := v.validateStrictRowset("Users", actual, expected, anyRows); err == nil {
+		t.Error("Expected failure when the explicit row does not match")
+	}
+
+	out, err := yaml.Marshal(rows)
+	if err != nil {
+		t.Fatalf("failed to marshal rows: %v", err)
+	}
+	if !strings.Contains(string(out), "!anyRow") {
+		t.Errorf("Expected !anyRow to round-trip, got:\n%s", out)
+	}
+}
+
+func TestFloatSpecialMatchers(t *testing.T) {
+	v := NewValidator(&config.Config{Options: config.ComparisonOptions{RelativeTolerance: tolerance(0.1)}}, nil)
+	exp := decodeExpected(t, `- NaN: !nan
+  Inf: !inf
+  NegInf: !-inf`)
+
+	tests := []struct {
+		col     string
+		actual  any
+		wantErr bool
+	}{
+		{"NaN", spanner.NullFloat64{Float64: math.NaN(), Valid: true}, false},
+		{"NaN", spanner.NullFloat64{Float64: 1, Valid: true}, true},
+		{"NaN", spanner.NullFloat64{}, true},
+		{"Inf", math.Inf(1), false},
+		{"Inf", math.Inf(-1), true},
+		{"Inf", math.MaxFloat64, true},
+		{"NegInf", math.Inf(-1), false},
+		{"NegInf", int64(1), true},
+	}
+	for _, tt := range tests {
+		err := v.validateData(tt.actual, exp[tt.col])
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s %v: got err=%v, wantErr=%v", tt.col, tt.actual, err, tt.wantErr)
+		}
+	}
+
+	if err := v.validateData(math.NaN(), math.NaN()); err == nil {
+		t.Error("Expected a plain NaN not to match")
+	}
+	if err := v.validateData(math.Inf(1), math.MaxFloat64); err == nil {
+		t.Error("Expected +Inf not to match a finite value within the relative tolerance")
+	}
+
+	out, err := yaml.Marshal(exp)
+	if err != nil || !strings.Contains(string(out), "!-inf") {
+		t.Errorf("Expected matchers to round-trip, got %q (err=%v)", out, err)
+	}
+}
+
+func TestDateMatchers(t *testing.T) {
+	v := NewValidator(&config.Config{}, nil)
+	exp := decodeExpected(t, `- Range: !dateBetween {from: 2024-01-01, to: 2024-12-31}
+  Since: !dateBetween {from: 2024-06-01}
+  Today: !today UTC`)
+
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return time.Date(2024, 3, 10, 23, 0, 0, 0, time.FixedZone("", -5*3600)) }
+
+	date := func(y int, m time.Month, d int) spanner.NullDate {
+		return spanner.NullDate{Date: civil.Date{Year: y, Month: m, Day: d}, Valid: true}
+	}
+	tests := []struct {
+		col     string
+		actual  any
+		wantErr bool
+	}{
+		{"Range", date(2024, 1, 1), false},
+		{"Range", date(2024, 12, 31), false},
+		{"Range", date(2025, 1, 1), true},
+		{"Range", spanner.NullDate{}, true},
+		{"Since", date(2030, 1, 1), false},
+		{"Since", date(2024, 5, 31), true},
+		{"Today", date(2024, 3, 11), false},
+		{"Today", date(2024, 3, 10), true},
+		{"Today", "2024-03-11", true},
+	}
+	for _, tt := range tests {
+		err := v.validateData(tt.actual, exp[tt.col])
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s %v: got err=%v, wantErr=%v", tt.col, tt.actual, err, tt.wantErr)
+		}
+	}
+
+	for _, src := range []string{
+		`- D: !dateBetween {from: 2024-12-31, to: 2024-01-01}`,
+		`- D: !dateBetween {}`,
+		`- D: !dateBetween {from: "2024/01/01"}`,
+		`- D: !today Nowhere/City`,
+	} {
+		var rows config.Rows
+		if err := yaml.Unmarshal([]byte(src), &rows); err == nil {
+			t.Errorf("Expected %q to be rejected", src)
+		}
+	}
+}
+
+func TestRecentMatcher(t *testing.T) {
+	v := NewValidator(&config.Config{}, nil)
+	exp := decodeExpected(t, `- CreatedAt: !recent 10m`)
+
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return at }
+
+	tests := []struct {
+		actual  any
+		wantErr string
+	}{
+		{spanner.NullTime{Time: at.Add(-9 * time.Minute), Valid: true}, ""},
+		{at.Add(-10 * time.Minute), ""},
+		{spanner.NullTime{Time: at.Add(time.Second), Valid: true}, ""},
+		{spanner.NullTime{Time: at.Add(-11 * time.Minute), Valid: true}, "is not within 10m0s of 2024-06-01T12:00:00Z"},
+		{spanner.NullTime{}, "expected timestamp"},
+		{"2024-06-01T12:00:00Z", "expected timestamp"},
+	}
+	for _, tt := range tests {
+		err := v.validateData(tt.actual, exp["CreatedAt"])
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%v: got err=%v, want %q", tt.actual, err, tt.wantErr)
+		}
+	}
+
+	out, err := yaml.Marshal(config.Rows{exp})
+	if err != nil || !strings.Contains(string(out), "CreatedAt: !recent 10m0s") {
+		t.Errorf("Expected !recent to round-trip, got %q (%v)", out, err)
+	}
+	for _, src := range []string{`- T: !recent`, `- T: !recent soon`, `- T: !recent -5m`} {
+		var rows config.Rows
+		if err := yaml.Unmarshal([]byte(src), &rows); err == nil {
+			t.Errorf("Expected %q to be rejected", src)
+		}
+	}
+}
+
+func TestRangeMatcher(t *testing.T) {
+	v := NewValidator(&config.Config{}, nil)
+	exp := decodeExpected(t, `- Price: !range {gt: 0}
+  Qty: !between [1, 10]
+  Rate: !range {gte: 0.5, lt: "1.5"}`)
+
+	rat := func(s string) spanner.NullNumeric {
+		r, _ := new(big.Rat).SetString(s)
+		return spanner.NullNumeric{Numeric: *r, Valid: true}
+	}
+	tests := []struct {
+		col     string
+		actual  any
+		wantErr string
+	}{
+		{"Price", spanner.NullInt64{Int64: 1, Valid: true}, ""},
+		{"Price", int64(0), "value 0 is not greater than 0"},
+		{"Price", rat("0.01"), ""},
+		{"Price", spanner.NullInt64{}, "NULL(int64) is not in range{gt=0}"},
+		{"Qty", spanner.NullInt64{Int64: 1, Valid: true}, ""},
+		{"Qty", spanner.NullInt64{Int64: 10, Valid: true}, ""},
+		{"Qty", spanner.NullInt64{Int64: 11, Valid: true}, "is not at most 10"},
+		{"Rate", spanner.NullFloat64{Float64: 0.5, Valid: true}, ""},
+		{"Rate", 1.5, "is not less than 1.5"},
+		{"Rate", spanner.NullFloat64{Float64: math.NaN(), Valid: true}, "is not in range"},
+		{"Rate", spanner.NullBool{Bool: true, Valid: true}, "not supported"},
+	}
+	for _, tt := range tests {
+		err := v.validateData(tt.actual, exp[tt.col])
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s %v: got err=%v, want %q", tt.col, tt.actual, err, tt.wantErr)
+		}
+	}
+
+	for _, src := range []string{
+		`- P: !range {}`,
+		`- P: !range {gt: 0, gte: 1}`,
+		`- P: !range {min: 0}`,
+		`- P: !range 5`,
+		`- P: !between [1]`,
+		`- P: !between [null, 2]`,
+	} {
+		var rows config.Rows
+		if err := yaml.Unmarshal([]byte(src), &rows); err == nil {
+			t.Errorf("Expected %q to be rejected", src)
+		}
+	}
+}
+
+func TestFileMatcher(t *testing.T) {
+	v := NewValidator(&config.Config{}, nil)
+	m := config.File{Path: "fixtures/logo.png", Size: 3, SHA256: sha256Hex([]byte("PNG"))}
+
+	tests := []struct {
+		actual  any
+		wantErr bool
+	}{
+		{[]byte("PNG"), false},
+		{spanner.NullString{StringVal: "PNG", Valid: true}, false},
+		{[]byte("GIF"), true},
+		{[]byte(nil), true},
+		{int64(3), true},
+	}
+	for _, tt := range tests {
+		err := v.validateData(tt.actual, m)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: got err=%v, wantErr=%v", tt.actual, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSHA256Matcher(t *testing.T) {
+	v := NewValidator(&config.Config{}, nil)
+	// sha256("test"), written in upper case
+	exp := decodeExpected(t, `- Text: !sha256 "9F86D081884C7D659A2FEAA0C55AD015A3BF4F1B2B0B822CD15D6C15B0F00A08"
+  Doc: !sha256 "`+sha256Hex([]byte(`{"a":1,"b":[2]}`))+`"`)
+
+	tests := []struct {
+		col     string
+		actual  any
+		wantErr bool
+	}{
+		{"Text", spanner.NullString{StringVal: "test", Valid: true}, false},
+		{"Text", []byte("test"), false},
+		{"Text", spanner.NullString{StringVal: "Test", Valid: true}, true},
+		{"Text", spanner.NullString{}, true},
+		{"Doc", spanner.NullJSON{Value: map[string]any{"b": []any{2.0}, "a": 1.0}, Valid: true}, false},
+		{"Doc", int64(1), true},
+	}
+	for _, tt := range tests {
+		err := v.validateData(tt.actual, exp[tt.col])
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s %v: got err=%v, wantErr=%v", tt.col, tt.actual, err, tt.wantErr)
+		}
+	}
+
+	var rows config.Rows
+	if err := yaml.Unmarshal([]byte(`- D: !sha256 "abc"`), &rows); err == nil {
+		t.Error("Expected a short digest to be rejected")
+	}
+}
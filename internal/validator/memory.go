@@ -0,0 +1,95 @@
+package validator
+
+import (
+	"runtime"
+
+	"cloud.google.com/go/civil"
+	"cloud.google.com/go/spanner"
+)
+
+// MemoryStats describes the memory used to validate a table, to judge when a table is too
+// large to buffer.
+type MemoryStats struct {
+	// Rows is the number of rows read and held in memory.
+	Rows int
+	// RowBytes estimates the size of those rows.
+	RowBytes int64
+	// PeakHeapBytes is the largest Go heap size sampled while the table was validated. The
+	// heap is shared, so with WithConcurrency it includes other tables.
+	PeakHeapBytes uint64
+}
+
+// heapSampler records the largest heap size seen across samples.
+type heapSampler struct{ peak uint64 }
+
+// sample reads the current heap size. It briefly stops the world, so it is only used when
+// memory stats are enabled.
+func (h *heapSampler) sample() {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	h.peak = max(h.peak, ms.HeapAlloc)
+}
+
+// Per-value overheads of the buffered rows: a map entry holds the column name and an
+// interface, and strings and slices carry a header.
+const (
+	mapEntryBytes = 16 + 16
+	headerBytes   = 24
+)
+
+// estimateRowBytes approximates the memory held by decoded rows.
+func estimateRowBytes(rows []map[string]any) int64 {
+	var n int64
+	for _, row := range rows {
+		n += 48 // map header
+		for col, val := range row {
+			n += mapEntryBytes + int64(len(col)) + estimateValueBytes(val)
+		}
+	}
+	return n
+}
+
+func estimateValueBytes(val any) int64 {
+	switch x := val.(type) {
+	case nil:
+		return 0
+	case spanner.NullString:
+		return headerBytes + 8 + int64(len(x.StringVal))
+	case string:
+		return headerBytes + int64(len(x))
+	case []byte:
+		return headerBytes + int64(len(x))
+	case spanner.NullNumeric:
+		// a big.Rat holds two big.Ints
+		return 2*headerBytes + 16 + int64(len(x.Numeric.Num().Bits())+len(x.Numeric.Denom().Bits()))*8
+	case spanner.NullJSON:
+		return 16 + estimateJSONBytes(x.Value)
+	case spanner.NullTime:
+		return 32
+	case spanner.NullDate, civil.Date:
+		return 24
+	default:
+		return 16
+	}
+}
+
+func estimateJSONBytes(val any) int64 {
+	switch x := val.(type) {
+	case map[string]any:
+		n := int64(48)
+		for k, v := range x {
+			n += mapEntryBytes + int64(len(k)) + estimateJSONBytes(v)
+		}
+		return n
+	case []any:
+		n := int64(headerBytes)
+		for _, v := range x {
+			n += 16 + estimateJSONBytes(v)
+		}
+		return n
+	case string:
+		return headerBytes + int64(len(x))
+	default:
+		return 16
+	}
+}
@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	"github.com/nu0ma/spalidate/internal/config"
+)
+
+func TestMemoryStats(t *testing.T) {
+	cfg, err := config.Parse([]byte(`tables:
+  Users:
+    columns:
+      - {UserID: "u1", Name: "Alice"}
+      - {UserID: "u2", Name: "Bob"}
+`), ".")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	db := &FakeDatabase{
+		Tables: map[string][]map[string]any{"Users": {
+			{"UserID": spanner.NullString{StringVal: "u1", Valid: true}, "Name": spanner.NullString{StringVal: "Alice", Valid: true}},
+			{"UserID": spanner.NullString{StringVal: "u2", Valid: true}, "Name": spanner.NullString{StringVal: "Bob", Valid: true}},
+		}},
+		PrimaryKeys: map[string][]string{"Users": {"UserID"}},
+	}
+
+	res := NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background())
+	if m := res.Tables[0].Memory; m != nil {
+		t.Errorf("Expected no memory stats by default, got %+v", m)
+	}
+
+	res = NewValidator(cfg, nil, WithDatabase(db), WithMemoryStats(true)).Run(context.Background())
+	if err := res.Err(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	m := res.Tables[0].Memory
+	if m == nil || m.Rows != 2 || m.PeakHeapBytes == 0 {
+		t.Fatalf("Expected stats of 2 rows, got %+v", m)
+	}
+	if want := estimateRowBytes(db.Tables["Users"]); m.RowBytes != want || want < int64(len("u1Alice")) {
+		t.Errorf("Expected %d row bytes, got %d", want, m.RowBytes)
+	}
+
+	bigger := estimateRowBytes([]map[string]any{{"Name": spanner.NullString{StringVal: "a much longer name", Valid: true}}})
+	smaller := estimateRowBytes([]map[string]any{{"Name": spanner.NullString{StringVal: "a", Valid: true}}})
+	if bigger-smaller != int64(len("a much longer name")-1) {
+		t.Errorf("Expected string length to drive the estimate, got %d and %d", bigger, smaller)
+	}
+}
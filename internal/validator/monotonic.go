@@ -0,0 +1,110 @@
+package validator
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/errkind"
+)
+
+// checkMonotonic reads a column in the table's order, by primary key unless orderBy is
+// given, and checks that no value goes against the monotonic direction. NULLs are skipped.
+func (v *Validator) checkMonotonic(ctx context.Context, tableName string, tableConfig config.TableConfig) error {
+	dialect, err := v.dialect(ctx)
+	if err != nil {
+		return err
+	}
+	m := tableConfig.Monotonic
+	order := m.OrderBy
+	if len(order) == 0 {
+		schema, err := v.db.TableSchema(ctx, tableName)
+		if err != nil {
+			return err
+		}
+		order = schema.PrimaryKey
+	}
+	exprs := []string{dialect.Ident(m.Column) + " AS value"}
+	idents := make([]string, len(order))
+	for i, col := range order {
+		idents[i] = dialect.Ident(col)
+		exprs = append(exprs, idents[i])
+	}
+	query := aggregateQuery(dialect, tableName, strings.Join(exprs, ", "), tableConfig) + " ORDER BY " + strings.Join(idents, ", ")
+	rows, err := v.queryRows(ctx, tableName, query, v.queryParams(tableConfig))
+	if err != nil {
+		return err
+	}
+
+	var prev map[string]any
+	var violations int
+	var first string
+	for _, row := range rows {
+		if isNull(row["value"]) {
+			continue
+		}
+		if prev != nil {
+			c, err := compareValues(prev["value"], row["value"])
+			if err != nil {
+				return fmt.Errorf("column %s: %w", m.Column, err)
+			}
+			if m.Direction == config.Descending {
+				c = -c
+			}
+			if c > 0 {
+				if violations == 0 {
+					first = fmt.Sprintf("%s at %s follows %s at %s", valueToPretty(row["value"]), keyString(row, order),
+						valueToPretty(prev["value"]), keyString(prev, order))
+				}
+				violations++
+			}
+		}
+		prev = row
+	}
+	if violations > 0 {
+		word := "ascending"
+		if m.Direction == config.Descending {
+			word = "descending"
+		}
+		return errkind.Mark(fmt.Errorf("column %s of table %s is not %s in %s order: %d out-of-order rows, first %s",
+			m.Column, tableName, word, strings.Join(order, ", "), violations, first), errkind.ErrRowMismatch)
+	}
+	return nil
+}
+
+// compareValues orders two non-NULL values of the same column type.
+func compareValues(a, b any) (int, error) {
+	switch x := a.(type) {
+	case spanner.NullInt64:
+		if y, ok := b.(spanner.NullInt64); ok {
+			return cmp.Compare(x.Int64, y.Int64), nil
+		}
+	case spanner.NullFloat64:
+		if y, ok := b.(spanner.NullFloat64); ok {
+			return cmp.Compare(x.Float64, y.Float64), nil
+		}
+	case spanner.NullNumeric:
+		if y, ok := b.(spanner.NullNumeric); ok {
+			return x.Numeric.Cmp(&y.Numeric), nil
+		}
+	case spanner.NullString:
+		if y, ok := b.(spanner.NullString); ok {
+			return strings.Compare(x.StringVal, y.StringVal), nil
+		}
+	case spanner.NullTime:
+		if y, ok := b.(spanner.NullTime); ok {
+			return x.Time.Compare(y.Time), nil
+		}
+	case spanner.NullDate:
+		if y, ok := b.(spanner.NullDate); ok {
+			return x.Date.Compare(y.Date), nil
+		}
+	default:
+		return 0, fmt.Errorf("monotonic is not supported for %T values", a)
+	}
+	return 0, fmt.Errorf("cannot compare %T with %T", a, b)
+}
@@ -0,0 +1,68 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	"github.com/nu0ma/spalidate/internal/config"
+)
+
+func TestMonotonic(t *testing.T) {
+	row := func(id, seq int64) map[string]any {
+		v := spanner.NullInt64{Int64: seq, Valid: seq != 0}
+		return map[string]any{"value": v, "EventID": spanner.NullInt64{Int64: id, Valid: true}}
+	}
+	tests := []struct {
+		name    string
+		config  string
+		query   string
+		rows    []map[string]any
+		wantErr string
+	}{
+		{
+			"ascending by primary key",
+			"monotonic: {column: Seq}",
+			"SELECT Seq AS value, EventID FROM Events ORDER BY EventID",
+			[]map[string]any{row(1, 10), row(2, 10), row(3, 0), row(4, 12)},
+			"",
+		},
+		{
+			"out of order",
+			"monotonic: {column: Seq}",
+			"SELECT Seq AS value, EventID FROM Events ORDER BY EventID",
+			[]map[string]any{row(1, 10), row(2, 9), row(3, 11), row(4, 8)},
+			"column Seq of table Events is not ascending in EventID order: 2 out-of-order rows, first 9 at 2 follows 10 at 1",
+		},
+		{
+			"descending by orderBy",
+			"monotonic: {column: Seq, direction: DESC, orderBy: [EventID]}\n    where: EventID > 0",
+			"SELECT Seq AS value, EventID FROM Events WHERE EventID > 0 ORDER BY EventID",
+			[]map[string]any{row(1, 3), row(2, 2), row(3, 2)},
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.Parse([]byte("tables:\n  Events:\n    "+tt.config+"\n"), ".")
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			db := &FakeDatabase{
+				PrimaryKeys: map[string][]string{"Events": {"EventID"}},
+				Queries:     map[string][]map[string]any{tt.query: tt.rows},
+			}
+			err = NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background()).Err()
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("got err=%v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	for _, src := range []string{"monotonic: {direction: asc}", "monotonic: {column: Seq, direction: up}"} {
+		if _, err := config.Parse([]byte("tables:\n  Events:\n    "+src+"\n"), "."); err == nil {
+			t.Errorf("Expected %q to be rejected", src)
+		}
+	}
+}
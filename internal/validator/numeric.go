@@ -0,0 +1,93 @@
+package validator
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+	"github.com/nu0ma/spalidate/internal/config"
+)
+
+// compareNumeric compares a NUMERIC value according to opts.NumericMode:
+//
+//	exact     (default) the decimal values must be equal
+//	round(n)  both values are rounded half away from zero to n decimal places first
+//	tolerance the float tolerances of opts apply
+func compareNumeric(actual *big.Rat, expected any, opts config.ComparisonOptions) error {
+	if _, ok := expected.(float64); ok && opts.Strict() {
+		return fmt.Errorf("%w; strict types: quote NUMERIC values as strings", typeMismatchError("numeric", expected))
+	}
+	e, err := toRat(expected)
+	if err != nil {
+		return err
+	}
+
+	mode, places, err := config.ParseNumericMode(opts.NumericMode)
+	if err != nil {
+		return err
+	}
+	switch mode {
+	case "round":
+		a := roundRat(actual, places)
+		er := roundRat(e, places)
+		if a.Cmp(er) != 0 {
+			return valueMismatchError(a.FloatString(places), er.FloatString(places))
+		}
+		return nil
+	case "tolerance":
+		af, _ := actual.Float64()
+		ef, _ := e.Float64()
+		if !floatsEqual(af, ef, opts) {
+			return valueMismatchError(spanner.NumericString(actual), spanner.NumericString(e))
+		}
+		return nil
+	default:
+		if actual.Cmp(e) != 0 {
+			return valueMismatchError(spanner.NumericString(actual), spanner.NumericString(e))
+		}
+		return nil
+	}
+}
+
+// toRat converts an expected value to an exact decimal. Floats are converted through their
+// shortest decimal representation so that 0.1 means 1/10 rather than its binary approximation.
+func toRat(expected any) (*big.Rat, error) {
+	if i, ok := toInt64(expected); ok {
+		return new(big.Rat).SetInt64(i), nil
+	}
+	var s string
+	switch ev := expected.(type) {
+	case string:
+		s = strings.TrimSpace(ev)
+	case float64:
+		s = strconv.FormatFloat(ev, 'g', -1, 64)
+	case float32:
+		s = strconv.FormatFloat(float64(ev), 'g', -1, 32)
+	default:
+		return nil, typeMismatchError("numeric", expected)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid numeric value for expected value: %q", s)
+	}
+	return r, nil
+}
+
+// roundRat rounds r half away from zero to the given number of decimal places.
+func roundRat(r *big.Rat, places int) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(scale))
+	num, den := scaled.Num(), scaled.Denom()
+	q, m := new(big.Int).QuoRem(num, den, new(big.Int))
+	// Round half away from zero: compare 2*|remainder| with the denominator.
+	if new(big.Int).Mul(new(big.Int).Abs(m), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return new(big.Rat).SetFrac(q, scale)
+}
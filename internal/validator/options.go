@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"time"
+
+	"github.com/nu0ma/spalidate/internal/config"
+)
+
+// Option configures a Validator; see NewValidator.
+type Option func(*Validator)
+
+// Reporter receives the result of each table as soon as it is validated, e.g. to stream
+// progress. Calls are never concurrent, but with WithConcurrency they follow completion
+// order rather than table name order.
+type Reporter func(TableResult)
+
+// WithComparisonOptions replaces the config's global comparison options. Per-table
+// options still apply on top of them.
+func WithComparisonOptions(opts config.ComparisonOptions) Option {
+	return func(v *Validator) { v.opts = opts }
+}
+
+// WithReporter calls r with each table result during Run.
+func WithReporter(r Reporter) Option {
+	return func(v *Validator) { v.reporter = r }
+}
+
+// WithConcurrency validates up to n tables at a time; n below 1 means one.
+func WithConcurrency(n int) Option {
+	return func(v *Validator) { v.concurrency = max(n, 1) }
+}
+
+// WithQueryTimeout bounds each query reading table rows, overriding the client's per-query
+// limit; zero keeps the client's.
+func WithQueryTimeout(d time.Duration) Option {
+	return func(v *Validator) { v.queryTimeout = d }
+}
+
+// WithDatabase reads through db instead of the Spanner client, e.g. a FakeDatabase in
+// unit tests.
+func WithDatabase(db Database) Option {
+	return func(v *Validator) { v.db = db }
+}
+
+// WithShowMatches logs a line for every column that matches its expectation.
+func WithShowMatches(show bool) Option {
+	return func(v *Validator) { v.showMatches = show }
+}
+
+// WithAnchor binds t, typically when the run under test started, to the @runStart
+// parameter of where filters.
+func WithAnchor(t time.Time) Option {
+	return func(v *Validator) { v.anchor = t }
+}
+
+// WithMemoryStats records the rows buffered and the peak heap size of each table in
+// TableResult.Memory, and logs them at debug level.
+func WithMemoryStats(enabled bool) Option {
+	return func(v *Validator) { v.memoryStats = enabled }
+}
@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/errkind"
+)
+
+var (
+	runStartRef = regexp.MustCompile(`@` + config.RunStartParam + `\b`)
+	runEndRef   = regexp.MustCompile(`@` + config.RunEndParam + `\b`)
+)
+
+// checkRunParams rejects where filters using @runStart when no anchor was given.
+func (v *Validator) checkRunParams(tableConfig config.TableConfig) error {
+	if v.anchor.IsZero() && runStartRef.MatchString(tableConfig.Where) {
+		return errkind.Mark(fmt.Errorf("where filter uses @%s, which needs an anchor (--anchor)", config.RunStartParam), errkind.ErrConfig)
+	}
+	return nil
+}
+
+// queryParams returns the parameters of the table's where filter, with the built-in
+// @runStart and @runEnd bound when the filter uses them.
+func (v *Validator) queryParams(tableConfig config.TableConfig) map[string]any {
+	params := tableConfig.QueryParams()
+	for _, p := range []struct {
+		ref   *regexp.Regexp
+		name  string
+		value any
+	}{{runStartRef, config.RunStartParam, v.anchor}, {runEndRef, config.RunEndParam, v.runEnd}} {
+		if !p.ref.MatchString(tableConfig.Where) {
+			continue
+		}
+		if params == nil {
+			params = make(map[string]any, 2)
+		}
+		params[p.name] = p.value
+	}
+	return params
+}
@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/errkind"
+)
+
+// paramsDatabase records the parameters of each query.
+type paramsDatabase struct {
+	*FakeDatabase
+	params []map[string]any
+}
+
+func (d *paramsDatabase) Query(ctx context.Context, sql string, params map[string]any) ([]map[string]any, error) {
+	d.params = append(d.params, params)
+	return d.FakeDatabase.Query(ctx, sql, params)
+}
+
+func TestRunParams(t *testing.T) {
+	cfg, err := config.Parse([]byte(`tables:
+  Events:
+    where: CreatedAt BETWEEN @runStart AND @runEnd AND Kind = @kind
+    params: {kind: test}
+    count: 2
+`), ".")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	db := &paramsDatabase{FakeDatabase: &FakeDatabase{Queries: map[string][]map[string]any{
+		"SELECT COUNT(*) AS n FROM Events WHERE CreatedAt BETWEEN @runStart AND @runEnd AND Kind = @kind": {{"n": spanner.NullInt64{Int64: 2, Valid: true}}},
+	}}}
+
+	err = NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background()).Err()
+	if !errors.Is(err, errkind.ErrConfig) || !strings.Contains(err.Error(), "needs an anchor") {
+		t.Errorf("Expected @runStart without an anchor to be rejected, got %v", err)
+	}
+
+	at := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return at }
+	anchor := at.Add(-time.Hour)
+	if err := NewValidator(cfg, nil, WithDatabase(db), WithAnchor(anchor)).Run(context.Background()).Err(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	got := db.params[len(db.params)-1]
+	if got["runStart"] != anchor || got["runEnd"] != at || got["kind"] != "test" {
+		t.Errorf("Expected runStart, runEnd and kind to be bound, got %v", got)
+	}
+
+	if _, err := config.Parse([]byte("tables:\n  T:\n    where: A = @runEnd\n    params: {runEnd: 1}\n"), "."); err == nil {
+		t.Error("Expected a param named like a built-in to be rejected")
+	}
+}
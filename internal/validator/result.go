@@ -0,0 +1,171 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Result collects the outcome of every table in a validation run, in table name order.
+type Result struct {
+	Tables []TableResult
+	// Interrupted is set when the run stopped early because its context was cancelled.
+	// Tables that had not started are missing from Tables.
+	Interrupted bool
+}
+
+// TableResult is the outcome of validating a single table.
+type TableResult struct {
+	Table   string
+	Skipped bool
+	Err     error
+	// Report is the detailed mismatch report of a failing table, if any.
+	Report string
+	// Rows holds the actual rows read for a failing table.
+	Rows []map[string]any
+	// ExpectedFailure is the table's expectedFailure marker when Err was expected; such a
+	// table does not count as failed.
+	ExpectedFailure string
+	// XFail lists the rows marked as expected failures that did fail, as "row N (marker)".
+	XFail []string
+	// XPass lists the expected failures, of the table or its rows, that unexpectedly passed.
+	XPass []string
+	// Memory describes the memory used to validate the table; it is set with
+	// WithMemoryStats for tables whose rows were read.
+	Memory *MemoryStats
+}
+
+// collector gathers the results of concurrent workers. Each result has a slot, its position
+// in the run, so the merged Result does not depend on completion order.
+type collector struct {
+	mu       sync.Mutex
+	slots    []*TableResult
+	reporter Reporter
+}
+
+func newCollector(n int, reporter Reporter) *collector {
+	return &collector{slots: make([]*TableResult, n), reporter: reporter}
+}
+
+// add stores the result of slot i and passes it to the reporter, if any.
+func (c *collector) add(i int, tr TableResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.slots[i] = &tr
+	if c.reporter != nil {
+		c.reporter(tr)
+	}
+}
+
+// result merges the collected results in slot order, leaving out slots that never ran.
+// Call it once every worker has finished.
+func (c *collector) result() *Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := &Result{}
+	for _, tr := range c.slots {
+		if tr != nil {
+			res.Tables = append(res.Tables, *tr)
+		}
+	}
+	return res
+}
+
+// Passed reports whether the table was validated without errors.
+func (t TableResult) Passed() bool {
+	return !t.Skipped && t.Err == nil
+}
+
+// Failed returns the results of tables whose validation failed unexpectedly.
+func (r *Result) Failed() []TableResult {
+	var failed []TableResult
+	for _, t := range r.Tables {
+		if t.Err != nil && t.ExpectedFailure == "" {
+			failed = append(failed, t)
+		}
+	}
+	return failed
+}
+
+// Err combines the errors of all failing tables, or returns nil when every table passed.
+// The table errors stay reachable with errors.Is and errors.As, e.g. for ErrRowMismatch.
+func (r *Result) Err() error {
+	var errs []error
+	for _, t := range r.Failed() {
+		errs = append(errs, fmt.Errorf("validation failed for table %s: %w", t.Table, t.Err))
+	}
+	if r.Interrupted {
+		errs = append(errs, errors.New("validation interrupted before all tables ran"))
+	}
+	if len(errs) > 0 {
+		return resultError(errs)
+	}
+	return nil
+}
+
+// resultError joins table errors with "; ".
+type resultError []error
+
+func (e resultError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e resultError) Unwrap() []error { return e }
+
+// WriteText writes the human-readable report of the run to w: one line per table,
+// followed by the detailed mismatch report of each failing table that has one.
+func (r *Result) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, t := range r.Tables {
+		switch {
+		case t.Skipped:
+			fmt.Fprintf(&b, "%s table %s: skipped\n", glyphs.bullet, t.Table)
+		case t.ExpectedFailure != "":
+			fmt.Fprintf(&b, "%s table %s: expected failure (%s): %v\n", glyphs.bullet, t.Table, t.ExpectedFailure, t.Err)
+		case t.Err != nil:
+			if t.Report != "" {
+				b.WriteString(t.Report)
+			} else {
+				fmt.Fprintf(&b, "%s table %s: %v\n", glyphs.fail, t.Table, t.Err)
+			}
+		default:
+			fmt.Fprintf(&b, "%s table %s: passed\n", glyphs.pass, t.Table)
+		}
+		for _, x := range t.XFail {
+			fmt.Fprintf(&b, "    %s %s: expected failure\n", glyphs.bullet, x)
+		}
+		for _, x := range t.XPass {
+			fmt.Fprintf(&b, "    %s %s: unexpectedly passing, remove the expectedFailure marker\n", glyphs.bullet, x)
+		}
+	}
+	if r.Interrupted {
+		fmt.Fprintf(&b, "%s validation interrupted before all tables ran\n", glyphs.fail)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ReportOf returns the detailed report carried by err, or "" when it has none.
+func ReportOf(err error) string {
+	var re *reportError
+	if errors.As(err, &re) {
+		return re.report
+	}
+	return ""
+}
+
+// reportError is a validation error that carries a detailed, human-readable report.
+type reportError struct {
+	err    error
+	report string
+}
+
+func (e *reportError) Error() string { return e.err.Error() }
+
+func (e *reportError) Unwrap() error { return e.err }
@@ -0,0 +1,148 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/errkind"
+	spannerClient "github.com/nu0ma/spalidate/internal/spanner"
+)
+
+// checkSchema runs the table's schema assertions: generated column expressions, and CHECK
+// constraint expressions.
+func (v *Validator) checkSchema(ctx context.Context, tableName string, tableConfig config.TableConfig) error {
+	var diffs []string
+	if len(tableConfig.GeneratedColumns) > 0 {
+		actual, err := v.db.GeneratedColumns(ctx, tableName)
+		if err != nil {
+			return err
+		}
+		diffs = append(diffs, generatedColumnDiffs(tableConfig.GeneratedColumns, actual)...)
+	}
+	if len(tableConfig.CheckConstraints) > 0 {
+		actual, err := v.db.CheckConstraints(ctx, tableName)
+		if err != nil {
+			return err
+		}
+		diffs = append(diffs, checkConstraintDiffs(tableConfig.CheckConstraints, actual)...)
+	}
+	if len(diffs) > 0 {
+		return errkind.Mark(fmt.Errorf("schema of table %s differs: %s", tableName, strings.Join(diffs, "; ")), errkind.ErrRowMismatch)
+	}
+	return nil
+}
+
+// generatedColumnDiffs compares generated columns with their assertions. An expression
+// ending in STORED asserts a stored column; without it the column must not be stored.
+func generatedColumnDiffs(expected map[string]string, actual map[string]spannerClient.GeneratedColumn) []string {
+	var diffs []string
+	for _, col := range slices.Sorted(maps.Keys(expected)) {
+		want := parseGenerated(expected[col])
+		got, ok := actual[col]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("%s is not a generated column", col))
+		case normalizeExpression(got.Expression) != normalizeExpression(want.Expression) || got.Stored != want.Stored:
+			diffs = append(diffs, fmt.Sprintf("%s: expected %s, got %s", col, formatGenerated(want), formatGenerated(got)))
+		}
+	}
+	return diffs
+}
+
+// checkConstraintDiffs compares CHECK constraints with their assertions.
+func checkConstraintDiffs(expected, actual map[string]string) []string {
+	var diffs []string
+	for _, name := range slices.Sorted(maps.Keys(expected)) {
+		got, ok := actual[name]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("check constraint %s does not exist", name))
+		case normalizeExpression(got) != normalizeExpression(expected[name]):
+			diffs = append(diffs, fmt.Sprintf("check constraint %s: expected %s, got %s", name, expected[name], got))
+		}
+	}
+	return diffs
+}
+
+// parseGenerated splits a trailing STORED off an asserted expression.
+func parseGenerated(s string) spannerClient.GeneratedColumn {
+	s = strings.TrimSpace(s)
+	if i := len(s) - len("STORED"); i > 0 && strings.EqualFold(s[i:], "STORED") && unicode.IsSpace(rune(s[i-1])) {
+		return spannerClient.GeneratedColumn{Expression: strings.TrimSpace(s[:i]), Stored: true}
+	}
+	return spannerClient.GeneratedColumn{Expression: s}
+}
+
+func formatGenerated(c spannerClient.GeneratedColumn) string {
+	if c.Stored {
+		return c.Expression + " STORED"
+	}
+	return c.Expression
+}
+
+// normalizeExpression makes expressions comparable across formatting: whitespace outside
+// string literals is collapsed, and dropped next to parentheses and commas, and parentheses
+// around the whole expression are removed.
+func normalizeExpression(s string) string {
+	var (
+		b     strings.Builder
+		prev  rune
+		quote rune
+		space bool
+	)
+	for _, r := range strings.TrimSpace(s) {
+		if quote == 0 && unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space && prev != '(' && prev != ',' && r != ')' && r != ',' {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteRune(r)
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		}
+		prev = r
+	}
+	out := b.String()
+	for len(out) >= 2 && out[0] == '(' && closes(out) == len(out)-1 {
+		out = out[1 : len(out)-1]
+	}
+	return out
+}
+
+// closes returns the index of the parenthesis closing the one at the start of s, skipping
+// string literals, or -1.
+func closes(s string) int {
+	depth := 0
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
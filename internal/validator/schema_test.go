@@ -0,0 +1,95 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	spannerClient "github.com/nu0ma/spalidate/internal/spanner"
+)
+
+func TestNormalizeExpression(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"CONCAT(First, ' ', Last)", "CONCAT(First,' ',Last)"},
+		{"  ( CONCAT( First ,\n  ' ',  Last ) )", "CONCAT(First,' ',Last)"},
+		{"Price * Quantity", "Price * Quantity"},
+		{"(a + b) * (c + d)", "(a + b) * (c + d)"},
+		{"'a  b'", "'a  b'"},
+		{"IF(x, ')', '(')", "IF(x,')','(')"},
+	}
+	for _, tt := range tests {
+		if got := normalizeExpression(tt.in); got != tt.want {
+			t.Errorf("normalizeExpression(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGeneratedColumns(t *testing.T) {
+	cfg, err := config.Parse([]byte(`tables:
+  Users:
+    generatedColumns:
+      FullName: "CONCAT(First, ' ', Last) STORED"
+      Initials: SUBSTR(First, 1, 1)
+`), ".")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	db := &FakeDatabase{Generated: map[string]map[string]spannerClient.GeneratedColumn{"Users": {
+		"FullName": {Expression: "CONCAT(First,' ',Last)", Stored: true},
+		"Initials": {Expression: "(SUBSTR(First, 1, 1))"},
+	}}}
+	if err := NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background()).Err(); err != nil {
+		t.Errorf("Expected the generated columns to match, got %v", err)
+	}
+
+	db.Generated["Users"]["FullName"] = spannerClient.GeneratedColumn{Expression: "CONCAT(Last, ' ', First)", Stored: true}
+	delete(db.Generated["Users"], "Initials")
+	err = NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background()).Err()
+	for _, want := range []string{
+		"FullName: expected CONCAT(First, ' ', Last) STORED, got CONCAT(Last, ' ', First) STORED",
+		"Initials is not a generated column",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q, got %v", want, err)
+		}
+	}
+
+	if _, err := config.Parse([]byte("tables:\n  T:\n    generatedColumns: {A: \"\"}\n"), "."); err == nil {
+		t.Error("Expected an empty expression to be rejected")
+	}
+}
+
+func TestCheckConstraints(t *testing.T) {
+	cfg, err := config.Parse([]byte(`tables:
+  Products:
+    checkConstraints:
+      PriceNonNegative: Price >= 0
+      NameNotBlank: LENGTH(Name) > 0
+`), ".")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	db := &FakeDatabase{Checks: map[string]map[string]string{"Products": {
+		"PriceNonNegative":              "(Price >= 0)",
+		"NameNotBlank":                  "LENGTH(Name) > 0",
+		"CK_IS_NOT_NULL_Products_Price": "Price IS NOT NULL",
+	}}}
+	if err := NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background()).Err(); err != nil {
+		t.Errorf("Expected the check constraints to match, got %v", err)
+	}
+
+	db.Checks["Products"]["PriceNonNegative"] = "Price > 0"
+	delete(db.Checks["Products"], "NameNotBlank")
+	err = NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background()).Err()
+	for _, want := range []string{
+		"check constraint NameNotBlank does not exist",
+		"check constraint PriceNonNegative: expected Price >= 0, got Price > 0",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/errkind"
+)
+
+// rowStrategy pairs the expected rows of a table with its actual rows; see config.RowStrategy.
+type rowStrategy interface {
+	// needsPrimaryKey reports whether the rows must be read with the primary key columns,
+	// in primary key order.
+	needsPrimaryKey() bool
+	validate(v *Validator, tableName string, actual []map[string]any, entries config.Rows, pk []string) error
+}
+
+type (
+	strictStrategy     struct{}
+	subsetStrategy     struct{}
+	primaryKeyStrategy struct{}
+	orderedStrategy    struct{}
+)
+
+// strategy returns the row strategy of a table under the validator's options.
+func (v *Validator) strategy(tableConfig config.TableConfig) rowStrategy {
+	switch tableConfig.RowStrategy(v.opts) {
+	case config.StrategySubset:
+		return subsetStrategy{}
+	case config.StrategyPrimaryKey:
+		return primaryKeyStrategy{}
+	case config.StrategyOrdered:
+		return orderedStrategy{}
+	default:
+		return strictStrategy{}
+	}
+}
+
+// readTable fetches the rows of a table, along with its primary key columns when the
+// table's row strategy needs them.
+func (v *Validator) readTable(ctx context.Context, tableName string, tableConfig config.TableConfig) ([]map[string]any, []string, error) {
+	var pk []string
+	if len(tableConfig.Columns) > 0 && v.strategy(tableConfig).needsPrimaryKey() {
+		schema, err := v.db.TableSchema(ctx, tableName)
+		if err != nil {
+			return nil, nil, err
+		}
+		pk = schema.PrimaryKey
+		for _, col := range pk {
+			if slices.Contains(tableConfig.IgnoreColumns, col) {
+				return nil, nil, errkind.Mark(fmt.Errorf("table %s: primary key column %s cannot be ignored under the %s strategy",
+					tableName, col, tableConfig.RowStrategy(v.opts)), errkind.ErrConfig)
+			}
+		}
+	}
+	rows, err := v.fetchRowsOrdered(ctx, tableName, tableConfig, pk)
+	return rows, pk, err
+}
+
+func (strictStrategy) needsPrimaryKey() bool { return false }
+
+func (strictStrategy) validate(v *Validator, tableName string, actual []map[string]any, entries config.Rows, _ []string) error {
+	expected, anyRows := entries.Split()
+	return v.validateStrictRowset(tableName, actual, expected, anyRows)
+}
+
+func (subsetStrategy) needsPrimaryKey() bool { return false }
+
+// validate requires the expected rows, and at least the rows of any `!anyRow` wildcards,
+// while ignoring every other row of the table.
+func (subsetStrategy) validate(v *Validator, tableName string, actual []map[string]any, entries config.Rows, _ []string) error {
+	expected, anyRows := entries.Split()
+	if len(actual) < len(expected)+anyRows {
+		return fmt.Errorf("too few rows in table %s: expected at least %d, got %d", tableName, len(expected)+anyRows, len(actual))
+	}
+	_, err := v.matchRows(tableName, actual, expected)
+	return err
+}
+
+func (orderedStrategy) needsPrimaryKey() bool { return true }
+
+func (orderedStrategy) validate(v *Validator, tableName string, actual []map[string]any, entries config.Rows, _ []string) error {
+	return v.validateOrderedRows(tableName, actual, entries)
+}
+
+func (primaryKeyStrategy) needsPrimaryKey() bool { return true }
+
+// validate pairs each expected row with the actual row of the same primary key, so a
+// difference is reported against its key instead of the closest row. Expected rows must
+// spell out every primary key column.
+func (primaryKeyStrategy) validate(v *Validator, tableName string, actual []map[string]any, entries config.Rows, pk []string) error {
+	expected, anyRows := entries.Split()
+	used := make([]bool, len(actual))
+	for ei, exp := range expected {
+		for _, k := range pk {
+			if _, ok := exp[k]; !ok {
+				return v.withRowMessage(exp, fmt.Errorf("expected row %d of table %s lacks primary key column %s", ei+1, tableName, k))
+			}
+		}
+		ai := v.findByKey(actual, used, exp, pk)
+		if ai < 0 {
+			return v.withRowMessage(exp, fmt.Errorf("no row with primary key %s in table %s", keyString(exp, pk), tableName))
+		}
+		used[ai] = true
+		act := actual[ai]
+		var diffs []colDiff
+		if v.columnsMatch(act, exp) {
+			if diffs = v.diffRow(act, exp); len(diffs) == 0 {
+				if v.showMatches {
+					logMatches(tableName, ei+1, exp, act)
+				}
+				continue
+			}
+		}
+		return v.withRowMessage(exp, &reportError{
+			err:    fmt.Errorf("row with primary key %s does not match in table %s", keyString(exp, pk), tableName),
+			report: rowReport(tableName, exp, act, diffs),
+		})
+	}
+
+	var extra []string
+	var extraRows []map[string]any
+	for ai, u := range used {
+		if !u {
+			extra = append(extra, keyString(actual[ai], pk))
+			extraRows = append(extraRows, actual[ai])
+		}
+	}
+	switch {
+	case len(extra) > anyRows:
+		return fmt.Errorf("unexpected rows present in table %s: %d beyond the expected rows (keys %s)%s",
+			tableName, len(extra)-anyRows, strings.Join(extra, ", "), v.rowExamples(extraRows, pk))
+	case len(extra) < anyRows:
+		return fmt.Errorf("unexpected row count for table %s: expected %d, got %d", tableName, len(expected)+anyRows, len(actual))
+	}
+	return nil
+}
+
+// findByKey returns the index of the unused actual row whose primary key columns match the
+// expected row, or -1.
+func (v *Validator) findByKey(actual []map[string]any, used []bool, exp map[string]any, pk []string) int {
+	for ai, act := range actual {
+		if used[ai] {
+			continue
+		}
+		match := true
+		for _, k := range pk {
+			if err := v.validateData(act[k], exp[k]); err != nil {
+				match = false
+				break
+			}
+		}
+		if match {
+			return ai
+		}
+	}
+	return -1
+}
+
+// keyString renders the primary key of a row as `a/b`.
+func keyString(row map[string]any, pk []string) string {
+	parts := make([]string, len(pk))
+	for i, k := range pk {
+		parts[i] = valueToPretty(row[k])
+	}
+	return strings.Join(parts, "/")
+}
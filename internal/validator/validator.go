@@ -1,25 +1,43 @@
 package validator
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"math"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"cloud.google.com/go/civil"
 	"cloud.google.com/go/spanner"
 	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/errkind"
 	"github.com/nu0ma/spalidate/internal/logging"
 	spannerClient "github.com/nu0ma/spalidate/internal/spanner"
-	"google.golang.org/api/iterator"
 )
 
 type Validator struct {
-	config        *config.Config
-	spannerClient *spannerClient.Client
+	config *config.Config
+	db     Database
+	// opts are the comparison options in effect; see forTable.
+	opts config.ComparisonOptions
+	// showMatches logs a line for every matching column, independently of the log level.
+	showMatches  bool
+	reporter     Reporter
+	concurrency  int
+	queryTimeout time.Duration
+	memoryStats  bool
+	// anchor and runEnd are bound to the built-in @runStart and @runEnd parameters.
+	anchor time.Time
+	runEnd time.Time
 }
 
 type colDiff struct {
@@ -28,132 +46,594 @@ type colDiff struct {
 	actual   any
 }
 
-func NewValidator(config *config.Config, client *spannerClient.Client) *Validator {
-	return &Validator{
-		config:        config,
-		spannerClient: client,
+// NewValidator returns a validator of cfg reading through client, or through the
+// WithDatabase option. Without options it uses the config's comparison options and
+// validates one table at a time.
+func NewValidator(cfg *config.Config, client *spannerClient.Client, options ...Option) *Validator {
+	v := &Validator{
+		config:      cfg,
+		opts:        cfg.Options,
+		concurrency: 1,
 	}
+	for _, o := range options {
+		o(v)
+	}
+	if v.db == nil && client != nil {
+		v.db = &spannerDatabase{client: client, timeout: v.queryTimeout}
+	}
+	return v
+}
+
+// forTable returns a copy of the validator using the table's comparison options.
+func (v *Validator) forTable(tableConfig config.TableConfig) *Validator {
+	tv := *v
+	tv.opts = v.opts.Merge(tableConfig.Options)
+	return &tv
 }
 
 func (v *Validator) Validate() error {
-	ctx := context.Background()
+	return v.Run(context.Background()).Err()
+}
+
+// Run validates every configured table and change stream assertion, and returns their
+// results with the tables in name order followed by the change streams. Once ctx is
+// cancelled no further table is started, but the tables in progress are given
+// shutdownGrace to finish so shutdowns do not leave a half-read table behind.
+func (v *Validator) Run(ctx context.Context) *Result {
+	started := now()
+	// the jobs bind @runEnd from this copy
+	run := *v
+	run.runEnd = started
+	v = &run
+	var jobs []func(context.Context) TableResult
+	for _, tableName := range sortedTableNames(v.config.Tables) {
+		jobs = append(jobs, func(ctx context.Context) TableResult {
+			return v.runTable(ctx, tableName, v.config.Tables[tableName])
+		})
+	}
+	if len(v.config.DatabaseOptions) > 0 {
+		jobs = append(jobs, v.runDatabaseOptions)
+	}
+	for _, name := range slices.Sorted(maps.Keys(v.config.ChangeStreams)) {
+		jobs = append(jobs, func(ctx context.Context) TableResult {
+			return v.runChangeStream(ctx, name, v.config.ChangeStreams[name], started)
+		})
+	}
+
+	jobCtx, cancelJobs := graceContext(ctx, shutdownGrace)
+	defer cancelJobs()
+	c := newCollector(len(jobs), v.reporter)
+	var (
+		wg          sync.WaitGroup
+		workers     = make(chan struct{}, max(v.concurrency, 1))
+		interrupted bool
+	)
+	for i, job := range jobs {
+		workers <- struct{}{}
+		if ctx.Err() != nil {
+			interrupted = true
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			c.add(i, job(jobCtx))
+		}()
+	}
+	wg.Wait()
+	res := c.result()
+	res.Interrupted = interrupted
+	return res
+}
+
+// shutdownGrace is how long the tables in progress may run on once Run's context is cancelled.
+var shutdownGrace = 30 * time.Second
+
+// graceContext returns a context that is cancelled grace after ctx, so work in progress can
+// finish after a shutdown request but a hung query cannot block it forever.
+func graceContext(ctx context.Context, grace time.Duration) (context.Context, context.CancelFunc) {
+	graced, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() { time.AfterFunc(grace, cancel) })
+	return graced, func() {
+		stop()
+		cancel()
+	}
+}
 
-	names := sortedTableNames(v.config.Tables)
-	var errs []string
-	for _, tableName := range names {
-		tableConfig := v.config.Tables[tableName]
-		if err := v.validateTable(ctx, tableName, tableConfig); err != nil {
-			errs = append(errs, fmt.Sprintf("validation failed for table %s: %v", tableName, err))
+func (v *Validator) runTable(ctx context.Context, tableName string, tableConfig config.TableConfig) TableResult {
+	tr := TableResult{Table: tableName}
+	enabled, err := tableConfig.Enabled()
+	if err != nil {
+		tr.Err = err
+		return tr
+	}
+	if !enabled {
+		logging.L().Info("Skipping table", "table", tableName, "when", tableConfig.When)
+		tr.Skipped = true
+		return tr
+	}
+
+	if err := v.checkRunParams(tableConfig); err != nil {
+		tr.Err = err
+		return tr
+	}
+	tv := v.forTable(tableConfig)
+	schema := len(tableConfig.GeneratedColumns) > 0 || len(tableConfig.CheckConstraints) > 0
+	if schema {
+		if err := tv.checkSchema(ctx, tableName, tableConfig); err != nil {
+			tr.Err = withMessage(tableConfig.Message, err)
+			return tr
 		}
 	}
+	aggregates := tableConfig.Count != nil || tableConfig.Distribution != nil || len(tableConfig.ColumnBounds) > 0 ||
+		tableConfig.Monotonic != nil
+	if aggregates {
+		if err := tv.checkAggregates(ctx, tableName, tableConfig); err != nil {
+			tr.Err = withMessage(tableConfig.Message, err)
+			return tr
+		}
+	}
+	if len(tableConfig.Columns) == 0 && (aggregates || schema) {
+		// only schema and aggregate assertions; the rows are never read
+		return tr
+	}
+	rows, pk, err := tv.readTable(ctx, tableName, tableConfig)
+	if err != nil {
+		tr.Err = withMessage(tableConfig.Message, err)
+		return tr
+	}
+	var heap heapSampler
+	if v.memoryStats {
+		heap.sample()
+	}
+	err = withMessage(tableConfig.Message, tv.validateRows(tableName, rows, pk, tableConfig))
+	if v.memoryStats {
+		heap.sample()
+		tr.Memory = &MemoryStats{Rows: len(rows), RowBytes: estimateRowBytes(rows), PeakHeapBytes: heap.peak}
+		logging.L().Debug("Table memory", "table", tableName, "rows", tr.Memory.Rows,
+			"rowBytes", tr.Memory.RowBytes, "peakHeapBytes", tr.Memory.PeakHeapBytes)
+	}
+	if err != nil {
+		var re *reportError
+		if errors.As(err, &re) {
+			tr.Report = re.report
+		}
+		tr.Err = err
+		tr.Rows = rows
+	}
+	tr.XFail, tr.XPass = tv.checkExpectedFailures(rows, tableConfig)
+	if marker := tableConfig.ExpectedFailure; marker != "" {
+		if err != nil {
+			tr.ExpectedFailure = marker
+		} else {
+			tr.XPass = append(tr.XPass, fmt.Sprintf("table (%s)", marker))
+		}
+	}
+	for _, x := range tr.XPass {
+		logging.L().Warn("Expected failure passed unexpectedly", "table", tableName, "marker", x)
+	}
+	return tr
+}
 
-	if len(errs) > 0 {
-		return errors.New(strings.Join(errs, "; "))
+// checkAggregates runs the table's count, distribution, bounds and monotonic assertions.
+func (v *Validator) checkAggregates(ctx context.Context, tableName string, tableConfig config.TableConfig) error {
+	if tableConfig.Count != nil {
+		if err := v.checkCount(ctx, tableName, tableConfig); err != nil {
+			return err
+		}
+	}
+	if tableConfig.Distribution != nil {
+		if err := v.checkDistribution(ctx, tableName, tableConfig); err != nil {
+			return err
+		}
+	}
+	if len(tableConfig.ColumnBounds) > 0 {
+		if err := v.checkBounds(ctx, tableName, tableConfig); err != nil {
+			return err
+		}
+	}
+	if tableConfig.Monotonic != nil {
+		return v.checkMonotonic(ctx, tableName, tableConfig)
 	}
 	return nil
 }
 
-func (v *Validator) validateTable(ctx context.Context, tableName string, tableConfig config.TableConfig) error {
-	query := fmt.Sprintf("SELECT * FROM %s", tableName)
-	iter := v.spannerClient.Query(ctx, query)
-	defer iter.Stop()
+// checkCount compares the number of rows matching the table's where filter with its count
+// assertion, within countTolerance.
+func (v *Validator) checkCount(ctx context.Context, tableName string, tableConfig config.TableConfig) error {
+	dialect, err := v.dialect(ctx)
+	if err != nil {
+		return err
+	}
+	rows, err := v.queryRows(ctx, tableName, aggregateQuery(dialect, tableName, "COUNT(*) AS n", tableConfig), v.queryParams(tableConfig))
+	if err != nil {
+		return err
+	}
+	n, _ := rows[0]["n"].(spanner.NullInt64)
+	return errkind.Mark(countError(tableName, n.Int64, tableConfig), errkind.ErrRowMismatch)
+}
 
-	var rows []map[string]any
-	// Read column data
-	err := iter.Do(func(row *spanner.Row) error {
-		columnNames := row.ColumnNames()
-		rowData := make(map[string]any)
+// aggregateQuery selects expr from the rows matching the table's where filter.
+func aggregateQuery(dialect spannerClient.Dialect, tableName, expr string, tableConfig config.TableConfig) string {
+	query := fmt.Sprintf("SELECT %s FROM %s", expr, dialect.Ident(tableName))
+	if tableConfig.Where != "" {
+		query += " WHERE " + tableConfig.Where
+	}
+	return query
+}
 
-		for i, colName := range columnNames {
-			var gcv spanner.GenericColumnValue
-			if err := row.Column(i, &gcv); err != nil {
-				return fmt.Errorf("failed to get column %s: %w", colName, err)
+// checkDistribution counts the rows per value of the distribution column and compares the
+// counts with the expected buckets.
+func (v *Validator) checkDistribution(ctx context.Context, tableName string, tableConfig config.TableConfig) error {
+	dialect, err := v.dialect(ctx)
+	if err != nil {
+		return err
+	}
+	col := dialect.Ident(tableConfig.Distribution.Column)
+	query := aggregateQuery(dialect, tableName, fmt.Sprintf("%s AS value, COUNT(*) AS n", col), tableConfig) + " GROUP BY " + col
+	rows, err := v.queryRows(ctx, tableName, query, v.queryParams(tableConfig))
+	if err != nil {
+		return err
+	}
+	return errkind.Mark(v.distributionError(tableName, rows, *tableConfig.Distribution), errkind.ErrRowMismatch)
+}
+
+// distributionError lists the buckets whose counts differ; groups are `{value, n}` rows.
+func (v *Validator) distributionError(tableName string, groups []map[string]any, d config.Distribution) error {
+	used := make([]bool, len(groups))
+	var diffs []string
+	for _, b := range d.Expect {
+		var got int64
+		for i, g := range groups {
+			if !used[i] && v.validateData(g["value"], b.Value) == nil {
+				used[i] = true
+				n, _ := g["n"].(spanner.NullInt64)
+				got = n.Int64
+				break
 			}
-			val, derr := decodeGenericValue(&gcv)
-			if derr != nil {
-				return fmt.Errorf("failed to decode column %s: %w", colName, derr)
+		}
+		if got != b.Count {
+			diffs = append(diffs, fmt.Sprintf("%s: expected %d, got %d", valueToPretty(b.Value), b.Count, got))
+		}
+	}
+	for i, g := range groups {
+		if !used[i] {
+			n, _ := g["n"].(spanner.NullInt64)
+			diffs = append(diffs, fmt.Sprintf("%s: expected 0, got %d", valueToPretty(g["value"]), n.Int64))
+		}
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("distribution of %s in table %s differs: %s", d.Column, tableName, strings.Join(diffs, "; "))
+	}
+	return nil
+}
+
+// countError reports a row count outside the range accepted by the table's count assertion.
+func countError(tableName string, n int64, tableConfig config.TableConfig) error {
+	lo, hi, err := tableConfig.CountRange()
+	if err != nil {
+		return err
+	}
+	if n >= lo && n <= hi {
+		return nil
+	}
+	if lo == hi {
+		return fmt.Errorf("unexpected row count for table %s: expected %d, got %d", tableName, *tableConfig.Count, n)
+	}
+	return fmt.Errorf("unexpected row count for table %s: expected %d +/- %s (%d-%d), got %d",
+		tableName, *tableConfig.Count, tableConfig.CountTolerance, lo, hi, n)
+}
+
+// checkExpectedFailures checks each row marked with `__expectedFailure` against the actual
+// rows. A marked row that matches some actual row is unexpectedly passing.
+func (v *Validator) checkExpectedFailures(rows []map[string]any, tableConfig config.TableConfig) (xfail, xpass []string) {
+	marked := tableConfig.Columns.ExpectedFailures()
+	if len(marked) == 0 {
+		return nil, nil
+	}
+	rows = withMissingColumns(rows, tableConfig.AllowMissingColumns)
+	for _, m := range marked {
+		desc := fmt.Sprintf("row %d (%s)", m.Pos, m.Marker)
+		matched := false
+		for _, act := range rows {
+			if v.columnsMatch(act, m.Row) && len(v.diffRow(act, m.Row)) == 0 {
+				matched = true
+				break
 			}
-			rowData[colName] = val
 		}
+		if matched {
+			xpass = append(xpass, desc)
+		} else {
+			xfail = append(xfail, desc)
+		}
+	}
+	return xfail, xpass
+}
+
+// fetchRows reads every row of the table and decodes each column into a comparable value.
+func (v *Validator) fetchRows(ctx context.Context, tableName string) ([]map[string]any, error) {
+	return v.fetchRowsOrdered(ctx, tableName, config.TableConfig{}, nil)
+}
+
+// fetchRowsOrdered is fetchRows restricted by the table's where filter, with the rows
+// sorted by the orderBy columns, if any.
+func (v *Validator) fetchRowsOrdered(ctx context.Context, tableName string, tableConfig config.TableConfig, orderBy []string) ([]map[string]any, error) {
+	rows, err := v.db.Read(ctx, tableName, ReadOptions{Where: tableConfig.Where, Params: v.queryParams(tableConfig), OrderBy: orderBy,
+		Exclude: tableConfig.IgnoreColumns})
+	if err != nil {
+		return nil, fmt.Errorf("query for table %s: %w", tableName, err)
+	}
+	return rows, nil
+}
+
+// queryRows runs a query on a table and decodes every row into a column map.
+func (v *Validator) queryRows(ctx context.Context, tableName, query string, params map[string]any) ([]map[string]any, error) {
+	rows, err := v.db.Query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("query for table %s: %w", tableName, err)
+	}
+	return rows, nil
+}
 
-		rows = append(rows, rowData)
+// validateRows checks the actual rows against the table's expected rows using its row
+// strategy. pk holds the primary key columns for strategies that need them.
+func (v *Validator) validateRows(tableName string, rows []map[string]any, pk []string, tableConfig config.TableConfig) error {
+	if len(tableConfig.Columns) == 0 {
 		return nil
-	})
+	}
+	entries, err := tableConfig.ExpectedRows()
+	if err == nil {
+		entries, err = entries.WithKeys(pk)
+	}
+	if err != nil {
+		return errkind.Mark(fmt.Errorf("table %s: %w", tableName, err), errkind.ErrConfig)
+	}
+	for _, row := range entries {
+		for _, col := range tableConfig.IgnoreColumns {
+			if _, ok := row[col]; ok {
+				err := fmt.Errorf("table %s: expected row sets column %s, which is in ignoreColumns", tableName, col)
+				return errkind.Mark(withMessage(v.config.RowPosition(row), err), errkind.ErrConfig)
+			}
+		}
+	}
+	for _, n := range entries.Ignored() {
+		logging.L().Warn("Ignoring expected row", "table", tableName, "row", n)
+	}
+	rows = withMissingColumns(rows, tableConfig.AllowMissingColumns)
+	return errkind.Mark(v.strategy(tableConfig).validate(v, tableName, rows, entries, pk), errkind.ErrRowMismatch)
+}
 
-	if err != nil && err != iterator.Done {
-		return fmt.Errorf("query execution failed: %w", err)
+// withMissingColumns returns rows with every absent column of defaults filled in with its
+// default value. Rows that lack none of them are returned as they are.
+func withMissingColumns(rows []map[string]any, defaults map[string]any) []map[string]any {
+	if len(defaults) == 0 {
+		return rows
 	}
+	out := make([]map[string]any, len(rows))
+	for i, row := range rows {
+		out[i] = row
+		var filled map[string]any
+		for col, def := range defaults {
+			if _, ok := row[col]; ok {
+				continue
+			}
+			if filled == nil {
+				filled = make(map[string]any, len(row)+len(defaults))
+				for k, v := range row {
+					filled[k] = v
+				}
+				out[i] = filled
+			}
+			// YAML integers decode as int; actual INT64 values are int64
+			if n, ok := def.(int); ok {
+				def = int64(n)
+			}
+			filled[col] = def
+		}
+	}
+	return out
+}
 
-	if len(tableConfig.Columns) > 0 {
-		// デフォルトで行集合の完全一致を要求
-		if err := v.validateStrictRowset(tableName, rows, tableConfig.Columns); err != nil {
+// validateStrictRowset requires every expected row to match a distinct actual row. anyRows
+// additional actual rows, declared with !anyRow, are accepted without checking their content.
+func (v *Validator) validateStrictRowset(tableName string, actualRows []map[string]any, expectedRows []map[string]any, anyRows int) error {
+	if len(actualRows) != len(expectedRows)+anyRows {
+		err := fmt.Errorf("unexpected row count for table %s: expected %d, got %d", tableName, len(expectedRows)+anyRows, len(actualRows))
+		if len(actualRows) < len(expectedRows)+anyRows {
 			return err
 		}
+		// when the expected rows are all present, the rows left over are worth showing
+		used, matchErr := v.matchRows(tableName, actualRows, expectedRows)
+		if matchErr != nil {
+			return err
+		}
+		var unmatched []map[string]any
+		for ai, u := range used {
+			if !u {
+				unmatched = append(unmatched, actualRows[ai])
+			}
+		}
+		return fmt.Errorf("%w%s", err, v.rowExamples(unmatched, nil))
+	}
+	used, err := v.matchRows(tableName, actualRows, expectedRows)
+	if err != nil {
+		return err
 	}
 
+	// any unmatched actual row beyond the !anyRow wildcards?
+	unmatched := 0
+	for _, u := range used {
+		if !u {
+			unmatched++
+		}
+	}
+	if unmatched > anyRows {
+		return fmt.Errorf("unexpected rows present in table %s", tableName)
+	}
 	return nil
 }
 
-func (v *Validator) validateStrictRowset(tableName string, actualRows []map[string]any, expectedRows []map[string]any) error {
-	if len(actualRows) != len(expectedRows) {
-		return fmt.Errorf("unexpected row count for table %s: expected %d, got %d", tableName, len(expectedRows), len(actualRows))
-	}
+// matchRows pairs every expected row with a distinct matching actual row and returns which
+// actual rows were used. Rows are paired by maximum matching, so a loose expected row
+// (e.g. `!any`) cannot take the only actual row a stricter one matches. The first expected
+// row left without a match is reported with its closest unpaired actual row.
+func (v *Validator) matchRows(tableName string, actualRows []map[string]any, expectedRows []map[string]any) ([]bool, error) {
+	paired := maximumMatching(len(expectedRows), len(actualRows), func(ei, ai int) bool {
+		return v.columnsMatch(actualRows[ai], expectedRows[ei]) && len(v.diffRow(actualRows[ai], expectedRows[ei])) == 0
+	})
 	used := make([]bool, len(actualRows))
-
+	for _, ai := range paired {
+		if ai >= 0 {
+			used[ai] = true
+		}
+	}
 	for ei, exp := range expectedRows {
-		found := false
+		if ai := paired[ei]; ai >= 0 {
+			if v.showMatches {
+				logMatches(tableName, ei+1, exp, actualRows[ai])
+			}
+			continue
+		}
 		var bestDiffs []colDiff
 		for ai, act := range actualRows {
-			if used[ai] {
+			if used[ai] || !v.columnsMatch(act, exp) {
 				continue
 			}
-			if !sameKeySet(act, exp) {
-				continue
-			}
-			diffs := make([]colDiff, 0)
-			ok := true
-			for key, actualValue := range act {
-				expectedValue := exp[key]
-				if err := v.validateData(actualValue, expectedValue); err != nil {
-					ok = false
-					diffs = append(diffs, colDiff{column: key, expected: expectedValue, actual: actualValue})
-				}
-			}
-			if ok {
-				used[ai] = true
-				found = true
-				break
-			}
-			if len(bestDiffs) == 0 || len(diffs) < len(bestDiffs) {
+			if diffs := v.diffRow(act, exp); len(bestDiffs) == 0 || len(diffs) < len(bestDiffs) {
 				bestDiffs = diffs
 			}
 		}
-		if !found {
-			if len(bestDiffs) > 0 {
-				logging.L().Error(buildMismatchReport(tableName, bestDiffs))
-			} else {
-				expKeys := sortedKeys(exp)
-				var exampleKeys []string
-				if len(actualRows) > 0 {
-					exampleKeys = sortedKeys(actualRows[0])
+		var example map[string]any
+		if len(actualRows) > 0 {
+			example = actualRows[0]
+		}
+		return nil, v.withRowMessage(exp, &reportError{
+			err:    fmt.Errorf("expected row %d not found in table %s", ei+1, tableName),
+			report: rowReport(tableName, exp, example, bestDiffs),
+		})
+	}
+	return used, nil
+}
+
+// validateOrderedRows requires the actual rows, in primary key order, to match the expected
+// entries position by position. An `!anyRow` entry stands for Count rows at its position.
+func (v *Validator) validateOrderedRows(tableName string, actualRows []map[string]any, entries config.Rows) error {
+	expected, anyRows := entries.Split()
+	if len(actualRows) != len(expected)+anyRows {
+		return fmt.Errorf("unexpected row count for table %s: expected %d, got %d", tableName, len(expected)+anyRows, len(actualRows))
+	}
+
+	pos, ei := 0, 0
+	for _, entry := range entries {
+		if m, ok := config.AsAnyRow(entry); ok {
+			pos += m.Count
+			continue
+		}
+		act := actualRows[pos]
+		pos++
+		ei++
+		var diffs []colDiff
+		if v.columnsMatch(act, entry) {
+			if diffs = v.diffRow(act, entry); len(diffs) == 0 {
+				if v.showMatches {
+					logMatches(tableName, ei, entry, act)
 				}
-				logging.L().Error(buildColumnSetMismatchReport(tableName, expKeys, exampleKeys))
+				continue
 			}
-			return fmt.Errorf("expected row %d not found in table %s", ei+1, tableName)
 		}
+
+		// Say so when the rows would match in another order, since that is the usual cause.
+		unordered := *v
+		unordered.showMatches = false
+		if unordered.validateStrictRowset(tableName, actualRows, expected, anyRows) == nil {
+			return v.withRowMessage(entry, fmt.Errorf("rows of table %s match only in a different order: expected row %d differs from row %d in primary key order", tableName, ei, pos))
+		}
+		return v.withRowMessage(entry, &reportError{
+			err:    fmt.Errorf("expected row %d does not match row %d of table %s", ei, pos, tableName),
+			report: rowReport(tableName, entry, act, diffs),
+		})
 	}
+	return nil
+}
 
-	// any unmatched actual row?
-	for _, u := range used {
-		if !u {
-			return fmt.Errorf("unexpected rows present in table %s", tableName)
+// diffRow compares the expected columns of a row; see columnsMatch for the column sets.
+// An explicit null in the expected row requires the actual value to be NULL.
+func (v *Validator) diffRow(act, exp map[string]any) []colDiff {
+	var diffs []colDiff
+	for _, key := range expectedColumns(exp) {
+		expectedValue, actualValue := exp[key], act[key]
+		if err := v.validateData(actualValue, expectedValue); err != nil {
+			diffs = append(diffs, colDiff{column: key, expected: expectedValue, actual: actualValue})
 		}
 	}
-	return nil
+	return diffs
+}
+
+// rowReport explains why an expected row did not match: the column differences when
+// there are any, otherwise the column set compared with an example actual row.
+func rowReport(tableName string, exp, example map[string]any, diffs []colDiff) string {
+	if len(diffs) > 0 {
+		return buildMismatchReport(tableName, diffs)
+	}
+	var exampleKeys []string
+	if example != nil {
+		exampleKeys = sortedKeys(example)
+	}
+	return buildColumnSetMismatchReport(tableName, expectedColumns(exp), exampleKeys)
+}
+
+// expectedColumns returns the sorted column names of an expected row, without annotations.
+func expectedColumns(exp map[string]any) []string {
+	var cols []string
+	for _, k := range sortedKeys(exp) {
+		if !config.IsMetaColumn(k) {
+			cols = append(cols, k)
+		}
+	}
+	return cols
+}
+
+// withRowMessage prepends the `__message` of an expected row and its position in the
+// config to err.
+func (v *Validator) withRowMessage(exp map[string]any, err error) error {
+	return withMessage(config.MessageOf(exp), withMessage(v.config.RowPosition(exp), err))
+}
+
+// withMessage prepends a configured message to err and to its report, if it has one.
+func withMessage(msg string, err error) error {
+	if msg == "" || err == nil {
+		return err
+	}
+	var re *reportError
+	if errors.As(err, &re) {
+		return &reportError{err: fmt.Errorf("%s: %w", msg, err), report: msg + "\n" + re.report}
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// logMatches reports each column of a matched row, for --show-matches.
+func logMatches(tableName string, row int, exp, act map[string]any) {
+	for _, key := range expectedColumns(exp) {
+		logging.L().Info(fmt.Sprintf("%s table %s row %d column %s: value matches", glyphs.pass, tableName, row, key),
+			"value", valueToPretty(act[key]))
+	}
 }
 
 func (v *Validator) validateData(record any, expectedData any) error {
+	if handled, err := v.validateMatcher(record, expectedData); handled {
+		return err
+	}
+
 	switch r := record.(type) {
+	case nil:
+		// a column from allowMissingColumns defaulting to NULL
+		if expectedData == nil {
+			return nil
+		}
+		return fmt.Errorf("expected %v, got NULL", expectedData)
 	case spanner.NullDate:
 		if !r.Valid {
 			if expectedData == nil {
@@ -171,9 +651,9 @@ func (v *Validator) validateData(record any, expectedData any) error {
 			}
 			return fmt.Errorf("expected %v, got NULL(string)", expectedData)
 		}
-		return compareStrings(r.StringVal, expectedData)
+		return compareStrings(r.StringVal, expectedData, v.opts)
 	case string:
-		return compareStrings(r, expectedData)
+		return compareStrings(r, expectedData, v.opts)
 	case spanner.NullInt64:
 		if !r.Valid {
 			if expectedData == nil {
@@ -181,9 +661,9 @@ func (v *Validator) validateData(record any, expectedData any) error {
 			}
 			return fmt.Errorf("expected %v, got NULL(int64)", expectedData)
 		}
-		return compareNumbers(r.Int64, expectedData)
+		return compareNumbers(r.Int64, expectedData, v.opts)
 	case int64:
-		return compareNumbers(r, expectedData)
+		return compareNumbers(r, expectedData, v.opts)
 	case spanner.NullFloat64:
 		if !r.Valid {
 			if expectedData == nil {
@@ -191,9 +671,9 @@ func (v *Validator) validateData(record any, expectedData any) error {
 			}
 			return fmt.Errorf("expected %v, got NULL(float64)", expectedData)
 		}
-		return compareNumbers(r.Float64, expectedData)
+		return compareNumbers(r.Float64, expectedData, v.opts)
 	case float64:
-		return compareNumbers(r, expectedData)
+		return compareNumbers(r, expectedData, v.opts)
 	case spanner.NullJSON:
 		if !r.Valid {
 			if expectedData == nil {
@@ -201,7 +681,15 @@ func (v *Validator) validateData(record any, expectedData any) error {
 			}
 			return fmt.Errorf("expected %v, got NULL(json)", expectedData)
 		}
-		return compareJSON(r.Value, expectedData)
+		return compareJSON(r.Value, expectedData, v.opts)
+	case spanner.NullNumeric:
+		if !r.Valid {
+			if expectedData == nil {
+				return nil
+			}
+			return fmt.Errorf("expected %v, got NULL(numeric)", expectedData)
+		}
+		return compareNumeric(&r.Numeric, expectedData, v.opts)
 	case spanner.NullBool:
 		if !r.Valid {
 			if expectedData == nil {
@@ -209,7 +697,7 @@ func (v *Validator) validateData(record any, expectedData any) error {
 			}
 			return fmt.Errorf("expected %v, got NULL(bool)", expectedData)
 		}
-		ev, ok := expectedData.(bool)
+		ev, ok := v.expectedBool(expectedData)
 		if !ok {
 			return typeMismatchError("bool", expectedData)
 		}
@@ -218,7 +706,7 @@ func (v *Validator) validateData(record any, expectedData any) error {
 		}
 		return nil
 	case bool:
-		ev, ok := expectedData.(bool)
+		ev, ok := v.expectedBool(expectedData)
 		if !ok {
 			return typeMismatchError("bool", expectedData)
 		}
@@ -233,9 +721,17 @@ func (v *Validator) validateData(record any, expectedData any) error {
 			}
 			return fmt.Errorf("expected %v, got NULL(timestamp)", expectedData)
 		}
-		return compareTimestamps(r.Time, expectedData)
+		return compareTimestamps(r.Time, expectedData, v.opts)
 	case time.Time:
-		return compareTimestamps(r, expectedData)
+		return compareTimestamps(r, expectedData, v.opts)
+	case []byte:
+		if r == nil {
+			if expectedData == nil {
+				return nil
+			}
+			return fmt.Errorf("expected %v, got NULL(bytes)", expectedData)
+		}
+		return compareBytes(r, expectedData, v.opts)
 	}
 
 	return fmt.Errorf("unsupported type: %T (value=%v)", record, record)
@@ -243,6 +739,26 @@ func (v *Validator) validateData(record any, expectedData any) error {
 
 // --- Helpers ---
 
+// expectedBool returns the expected BOOL value, accepting boolean strings when
+// the CoerceBooleans option is enabled and StrictTypes is not.
+func (v *Validator) expectedBool(expected any) (bool, bool) {
+	switch ev := expected.(type) {
+	case bool:
+		return ev, true
+	case string:
+		if !v.opts.BooleanCoercion() || v.opts.Strict() {
+			return false, false
+		}
+		switch strings.ToLower(strings.TrimSpace(ev)) {
+		case "true", "1":
+			return true, true
+		case "false", "0":
+			return false, true
+		}
+	}
+	return false, false
+}
+
 func typeMismatchError(expectedKind string, got any) error {
 	return fmt.Errorf("type mismatch: expected %s, got %T (value=%v)", expectedKind, got, got)
 }
@@ -251,39 +767,50 @@ func valueMismatchError(actual, expected any) error {
 	return fmt.Errorf("value mismatch: actual=%v, expected=%v", actual, expected)
 }
 
-func compareStrings(actual string, expected any) error {
+// compareStrings compares STRING values exactly, except that an expected JSON object or
+// array is compared as JSON, as for documents stored in STRING(MAX) columns. StrictTypes
+// keeps that comparison exact too.
+func compareStrings(actual string, expected any, opts config.ComparisonOptions) error {
 	switch ev := expected.(type) {
 	case string:
-		// If expected looks like JSON, compare as JSON
-		if looksLikeJSON(ev) {
-			var a any
-			if err := json.Unmarshal([]byte(actual), &a); err != nil {
-				return fmt.Errorf("actual is not valid JSON: %w", err)
-			}
-			var e any
-			if err := json.Unmarshal([]byte(ev), &e); err != nil {
-				return fmt.Errorf("expected is not valid JSON: %w", err)
-			}
-			if !deepEqualJSON(a, e) {
-				// Keep diff representation concise
-				aa, _ := json.Marshal(a)
-				ee, _ := json.Marshal(e)
-				return valueMismatchError(string(aa), string(ee))
-			}
+		if actual == ev {
 			return nil
 		}
-		if actual != ev {
-			return valueMismatchError(actual, ev)
+		if looksLikeJSON(ev) && !opts.Strict() {
+			return compareJSON(actual, ev, opts)
 		}
-		return nil
+		return valueMismatchError(actual, ev)
+	case int, int64, uint64, float64, bool:
+		// YAML read an unquoted scalar as a number or bool; never stringify it silently
+		return fmt.Errorf("%w; quote it to expect the string %q", typeMismatchError("string", expected), fmt.Sprint(ev))
 	default:
-		// Avoid stringifying when YAML gives numbers/bools; report type mismatch
 		return typeMismatchError("string", expected)
 	}
 }
 
+// compareBytes accepts the expected value as base64 (Spanner's canonical encoding) or raw text.
+func compareBytes(actual []byte, expected any, opts config.ComparisonOptions) error {
+	switch ev := expected.(type) {
+	case string:
+		if decoded, err := base64.StdEncoding.DecodeString(ev); err == nil && bytes.Equal(actual, decoded) {
+			return nil
+		}
+		if !opts.Strict() && string(actual) == ev {
+			return nil
+		}
+		return valueMismatchError(base64.StdEncoding.EncodeToString(actual), ev)
+	case []byte:
+		if !bytes.Equal(actual, ev) {
+			return valueMismatchError(base64.StdEncoding.EncodeToString(actual), base64.StdEncoding.EncodeToString(ev))
+		}
+		return nil
+	default:
+		return typeMismatchError("bytes(base64 string)", expected)
+	}
+}
+
 // JSON comparison (Spanner JSON or generic)
-func compareJSON(actual any, expected any) error {
+func compareJSON(actual any, expected any, opts config.ComparisonOptions) error {
 	var a any
 	var e any
 
@@ -312,41 +839,140 @@ func compareJSON(actual any, expected any) error {
 		e = v
 	}
 
-	if !deepEqualJSON(a, e) {
-		aa, _ := json.Marshal(a)
-		ee, _ := json.Marshal(e)
-		return valueMismatchError(string(aa), string(ee))
+	// Round-trip both sides so YAML integers and JSON numbers are both float64
+	a, err := normalizeJSON(a)
+	if err != nil {
+		return fmt.Errorf("actual is not valid JSON: %w", err)
+	}
+	if e, err = normalizeJSON(e); err != nil {
+		return fmt.Errorf("expected is not valid JSON: %w", err)
+	}
+	if !jsonEqual(a, e, opts) {
+		return jsonDiffError(a, e, opts)
 	}
 	return nil
 }
 
+// normalizeJSON converts a decoded value to the types produced by encoding/json.
+func normalizeJSON(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	err = json.Unmarshal(b, &out)
+	return out, err
+}
+
 func looksLikeJSON(s string) bool {
 	t := strings.TrimSpace(s)
 	return (strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}")) ||
 		(strings.HasPrefix(t, "[") && strings.HasSuffix(t, "]"))
 }
 
-func deepEqualJSON(a, b any) bool {
-	// JSON numbers become float64; DeepEqual is sufficient.
-	return reflect.DeepEqual(a, b)
+// jsonEqual compares normalized JSON values. Object keys are unordered; arrays are compared
+// by position unless opts.JSONArrayOrder is "ignore". Numbers use the float tolerances.
+func jsonEqual(a, e any, opts config.ComparisonOptions) bool {
+	switch ev := e.(type) {
+	case map[string]any:
+		av, ok := a.(map[string]any)
+		if !ok || len(av) != len(ev) {
+			return false
+		}
+		for k, ee := range ev {
+			aa, ok := av[k]
+			if !ok || !jsonEqual(aa, ee, opts) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		av, ok := a.([]any)
+		if !ok || len(av) != len(ev) {
+			return false
+		}
+		if opts.JSONArrayOrder == config.JSONArrayOrderIgnore {
+			return jsonMultisetEqual(av, ev, opts)
+		}
+		for i := range ev {
+			if !jsonEqual(av[i], ev[i], opts) {
+				return false
+			}
+		}
+		return true
+	case float64:
+		av, ok := a.(float64)
+		return ok && floatsEqual(av, ev, opts)
+	default:
+		return reflect.DeepEqual(a, e)
+	}
 }
 
-// sameKeySet checks whether two maps have exactly the same key set.
-func sameKeySet(a, b map[string]any) bool {
-	if len(a) != len(b) {
+// jsonMultisetEqual pairs every expected element with a distinct equal actual element.
+// Equality within float tolerances is not transitive, so taking the first equal element
+// can starve a later one; the pairing is a bipartite matching found by augmenting paths.
+func jsonMultisetEqual(actual, expected []any, opts config.ComparisonOptions) bool {
+	paired := maximumMatching(len(expected), len(actual), func(i, j int) bool {
+		return jsonEqual(actual[j], expected[i], opts)
+	})
+	return !slices.Contains(paired, -1)
+}
+
+// maximumMatching pairs each of n expected items with a distinct one of m actual items
+// that equal accepts, pairing as many as possible by augmenting paths. It returns the
+// actual item paired with each expected one, or -1.
+func maximumMatching(n, m int, equal func(i, j int) bool) []int {
+	eq := make([][]bool, n)
+	for i := range eq {
+		eq[i] = make([]bool, m)
+		for j := range eq[i] {
+			eq[i][j] = equal(i, j)
+		}
+	}
+	// owner holds the expected item paired with each actual item, or -1
+	owner := make([]int, m)
+	for j := range owner {
+		owner[j] = -1
+	}
+	var pair func(i int, visited []bool) bool
+	pair = func(i int, visited []bool) bool {
+		for j := range m {
+			if !eq[i][j] || visited[j] {
+				continue
+			}
+			visited[j] = true
+			if owner[j] < 0 || pair(owner[j], visited) {
+				owner[j] = i
+				return true
+			}
+		}
 		return false
 	}
-	for k := range a {
-		if _, ok := b[k]; !ok {
-			return false
+	for i := range n {
+		pair(i, make([]bool, m))
+	}
+	paired := make([]int, n)
+	for i := range paired {
+		paired[i] = -1
+	}
+	for j, i := range owner {
+		if i >= 0 {
+			paired[i] = j
 		}
 	}
-	for k := range b {
-		if _, ok := a[k]; !ok {
+	return paired
+}
+
+// columnsMatch reports whether an actual row has the columns of an expected row: exactly
+// the same columns, or a superset of them with AllowExtraColumns.
+func (v *Validator) columnsMatch(act, exp map[string]any) bool {
+	cols := expectedColumns(exp)
+	for _, k := range cols {
+		if _, ok := act[k]; !ok {
 			return false
 		}
 	}
-	return true
+	return v.opts.ExtraColumns() || len(act) == len(cols)
 }
 
 func valueToPretty(v any) string {
@@ -381,6 +1007,11 @@ func valueToPretty(v any) string {
 			return "NULL(date)"
 		}
 		return x.Date.String()
+	case spanner.NullNumeric:
+		if !x.Valid {
+			return "NULL(numeric)"
+		}
+		return spanner.NumericString(&x.Numeric)
 	case spanner.NullJSON:
 		if !x.Valid {
 			return "NULL(json)"
@@ -398,6 +1029,11 @@ func valueToPretty(v any) string {
 		return x.String()
 	case time.Time:
 		return x.Format(time.RFC3339)
+	case []byte:
+		if x == nil {
+			return "NULL(bytes)"
+		}
+		return base64.StdEncoding.EncodeToString(x)
 	case string:
 		// Keep as-is; if it looks like JSON, compact it to one line
 		if looksLikeJSON(x) {
@@ -420,6 +1056,22 @@ func valueToPretty(v any) string {
 	}
 }
 
+// canonicalValue renders a value losslessly, for keys and hashes: like valueToPretty, but
+// timestamps keep their sub-second digits and strings are never reformatted.
+func canonicalValue(v any) string {
+	switch x := v.(type) {
+	case spanner.NullTime:
+		if x.Valid {
+			return x.Time.UTC().Format(time.RFC3339Nano)
+		}
+	case time.Time:
+		return x.UTC().Format(time.RFC3339Nano)
+	case string:
+		return x
+	}
+	return valueToPretty(v)
+}
+
 func sortedKeys(m map[string]any) []string {
 	ks := make([]string, 0, len(m))
 	for k := range m {
@@ -453,12 +1105,12 @@ func sortedTableNames(m map[string]config.TableConfig) []string {
 
 func buildMismatchReport(table string, diffs []colDiff) string {
 	var b strings.Builder
-	fmt.Fprintf(&b, "✖️ table %s: expected row does not match\n", table)
+	fmt.Fprintf(&b, "%s table %s: expected row does not match\n", glyphs.fail, table)
 	fmt.Fprintf(&b, "    column mismatch: %d\n", len(diffs))
 	for i, d := range diffs {
 		fmt.Fprintf(&b, "\n  %d)  column: %s\n", i+1, d.column)
-		fmt.Fprintf(&b, "     ▸ expected: %s\n", valueToPretty(d.expected))
-		fmt.Fprintf(&b, "     ▸   actual: %s\n", valueToPretty(d.actual))
+		fmt.Fprintf(&b, "     %s expected: %s\n", glyphs.bullet, valueToPretty(d.expected))
+		fmt.Fprintf(&b, "     %s   actual: %s\n", glyphs.bullet, valueToPretty(d.actual))
 
 	}
 	return b.String()
@@ -466,15 +1118,66 @@ func buildMismatchReport(table string, diffs []colDiff) string {
 
 func buildColumnSetMismatchReport(table string, expectedCols, exampleActualCols []string) string {
 	var b strings.Builder
-	fmt.Fprintf(&b, "✖️ table %s: expected column set does not match\n", table)
-	fmt.Fprintf(&b, "   🧩 expected columns: %s\n", strings.Join(expectedCols, ", "))
+	fmt.Fprintf(&b, "%s table %s: expected column set does not match\n", glyphs.fail, table)
+	fmt.Fprintf(&b, "   %s expected columns: %s\n", glyphs.columns, strings.Join(expectedCols, ", "))
 	if len(exampleActualCols) > 0 {
-		fmt.Fprintf(&b, "   🔎 example actual:  %s\n", strings.Join(exampleActualCols, ", "))
+		fmt.Fprintf(&b, "   %s example actual:  %s\n", glyphs.example, strings.Join(exampleActualCols, ", "))
+		omitted, unknown := columnSetDiff(expectedCols, exampleActualCols)
+		if len(omitted) > 0 {
+			fmt.Fprintf(&b, "   %s omitted from expected row (write null to expect NULL): %s\n", glyphs.minus, strings.Join(omitted, ", "))
+		}
+		if len(unknown) > 0 {
+			fmt.Fprintf(&b, "   %s not in table: %s\n", glyphs.plus, strings.Join(unknown, ", "))
+		}
 	}
 	return b.String()
 }
 
-func compareNumbers(actual any, expected any) error {
+// columnSetDiff returns the actual columns missing from expected and the expected columns
+// missing from actual. Both inputs must be sorted.
+func columnSetDiff(expected, actual []string) (omitted, unknown []string) {
+	inExpected := make(map[string]bool, len(expected))
+	for _, c := range expected {
+		inExpected[c] = true
+	}
+	inActual := make(map[string]bool, len(actual))
+	for _, c := range actual {
+		inActual[c] = true
+		if !inExpected[c] {
+			omitted = append(omitted, c)
+		}
+	}
+	for _, c := range expected {
+		if !inActual[c] {
+			unknown = append(unknown, c)
+		}
+	}
+	return omitted, unknown
+}
+
+func compareNumbers(actual any, expected any, opts config.ComparisonOptions) error {
+	// Numeric strings such as "42" or "1.5e6" are normalized first.
+	switch ev := expected.(type) {
+	case string:
+		if opts.Strict() {
+			return fmt.Errorf("%w; strict types: write numbers unquoted", typeMismatchError("number", expected))
+		}
+		n, err := parseNumberString(ev)
+		if err != nil {
+			if errors.Is(err, strconv.ErrRange) {
+				return fmt.Errorf("expected value %s is out of INT64 range", ev)
+			}
+			return typeMismatchError("number", expected)
+		}
+		expected = n
+	case uint64:
+		// YAML decodes integer literals above MaxInt64 as uint64.
+		if ev > math.MaxInt64 {
+			return fmt.Errorf("expected value %d is out of INT64 range", ev)
+		}
+		expected = int64(ev)
+	}
+
 	// 'actual' is expected to be int64 or float64
 	avInt, aIsInt := toInt64(actual)
 	avFloat, aIsFloat := toFloat64(actual)
@@ -489,19 +1192,34 @@ func compareNumbers(actual any, expected any) error {
 			return valueMismatchError(avInt, evInt)
 		}
 		return nil
-	case aIsFloat && eIsFloat:
-		// No epsilon for floats (simplified). Add tolerance if needed.
-		if avFloat != evFloat {
-			return valueMismatchError(avFloat, evFloat)
-		}
-		return nil
+	case opts.Strict() && aIsInt && eIsFloat:
+		return fmt.Errorf("%w; strict types: INT64 columns need an integer", typeMismatchError("int64", expected))
+	case opts.Strict() && aIsFloat && eIsInt:
+		return fmt.Errorf("%w; strict types: FLOAT64 columns need a float such as %d.0", typeMismatchError("float64", expected), evInt)
 	case aIsInt && eIsFloat:
-		if float64(avInt) != evFloat {
+		// Beyond 2^53 a float cannot represent every integer, so the literal was likely
+		// rounded by the YAML parser; refuse rather than compare a different number.
+		if math.Abs(evFloat) >= 1<<53 {
+			return fmt.Errorf("expected value %v exceeds float64 integer precision; quote large INT64 values as strings", evFloat)
+		}
+		// Integral floats (e.g. 1.5e6) are compared exactly as INT64 to avoid precision loss.
+		if ev, ok := integralFloat(evFloat); ok {
+			if avInt != ev {
+				return valueMismatchError(avInt, ev)
+			}
+			return nil
+		}
+		if !floatsEqual(float64(avInt), evFloat, opts) {
 			return valueMismatchError(float64(avInt), evFloat)
 		}
 		return nil
+	case aIsFloat && eIsFloat:
+		if !floatsEqual(avFloat, evFloat, opts) {
+			return valueMismatchError(avFloat, evFloat)
+		}
+		return nil
 	case aIsFloat && eIsInt:
-		if avFloat != float64(evInt) {
+		if !floatsEqual(avFloat, float64(evInt), opts) {
 			return valueMismatchError(avFloat, float64(evInt))
 		}
 		return nil
@@ -510,7 +1228,55 @@ func compareNumbers(actual any, expected any) error {
 	}
 }
 
-func compareTimestamps(actual time.Time, expected any) error {
+// parseNumberString parses an integer or floating point literal, including scientific notation.
+// Integer literals outside the INT64 range return an error wrapping strconv.ErrRange instead
+// of silently becoming floats.
+func parseNumberString(s string) (any, error) {
+	s = strings.TrimSpace(s)
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err == nil {
+		return i, nil
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return nil, err
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// integralFloat converts f to int64 when it has no fractional part and fits the INT64 range.
+func integralFloat(f float64) (int64, bool) {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// floatsEqual compares floats exactly unless an absolute or relative tolerance is configured;
+// the values match when either tolerance is satisfied.
+func floatsEqual(a, e float64, opts config.ComparisonOptions) bool {
+	if a == e {
+		return true
+	}
+	// NaN and infinities only match through the !nan, !inf and !-inf matchers
+	if math.IsNaN(a) || math.IsNaN(e) || math.IsInf(a, 0) || math.IsInf(e, 0) {
+		return false
+	}
+	diff := math.Abs(a - e)
+	if opts.AbsTolerance() > 0 && diff <= opts.AbsTolerance() {
+		return true
+	}
+	if opts.RelTolerance() > 0 && diff <= opts.RelTolerance()*math.Max(math.Abs(a), math.Abs(e)) {
+		return true
+	}
+	return false
+}
+
+func compareTimestamps(actual time.Time, expected any, opts config.ComparisonOptions) error {
+	actual = actual.Truncate(opts.TimestampPrecision())
 	switch ev := expected.(type) {
 	case string:
 		// Prefer RFC3339 formats
@@ -518,11 +1284,13 @@ func compareTimestamps(actual time.Time, expected any) error {
 		if err != nil {
 			return fmt.Errorf("invalid timestamp format for expected value: %w", err)
 		}
+		t = t.Truncate(opts.TimestampPrecision())
 		if !actual.Equal(t) {
 			return valueMismatchError(actual.UTC().Format(time.RFC3339Nano), t.UTC().Format(time.RFC3339Nano))
 		}
 		return nil
 	case time.Time:
+		ev = ev.Truncate(opts.TimestampPrecision())
 		if !actual.Equal(ev) {
 			return valueMismatchError(actual.UTC().Format(time.RFC3339Nano), ev.UTC().Format(time.RFC3339Nano))
 		}
@@ -627,89 +1395,3 @@ func toFloat64(v any) (float64, bool) {
 		return 0, false
 	}
 }
-
-// decodeGenericValue decodes a Spanner GenericColumnValue into supported concrete types.
-// It returns types that validateData can consume (spanner.Null* or primitives).
-func decodeGenericValue(gcv *spanner.GenericColumnValue) (any, error) {
-	// DATE type
-	{
-		var v spanner.NullDate
-		if err := gcv.Decode(&v); err == nil {
-			return v, nil
-		}
-	}
-	{
-		var v civil.Date
-		if err := gcv.Decode(&v); err == nil {
-			return v, nil
-		}
-	}
-	// JSON type
-	{
-		var v spanner.NullJSON
-		if err := gcv.Decode(&v); err == nil {
-			return v, nil
-		}
-	}
-	{
-		var v spanner.NullString
-		if err := gcv.Decode(&v); err == nil {
-			return v, nil
-		}
-	}
-	{
-		var v spanner.NullInt64
-		if err := gcv.Decode(&v); err == nil {
-			return v, nil
-		}
-	}
-	{
-		var v spanner.NullFloat64
-		if err := gcv.Decode(&v); err == nil {
-			return v, nil
-		}
-	}
-	{
-		var v spanner.NullBool
-		if err := gcv.Decode(&v); err == nil {
-			return v, nil
-		}
-	}
-	{
-		var v spanner.NullTime
-		if err := gcv.Decode(&v); err == nil {
-			return v, nil
-		}
-	}
-	{
-		var v string
-		if err := gcv.Decode(&v); err == nil {
-			return v, nil
-		}
-	}
-	{
-		var v int64
-		if err := gcv.Decode(&v); err == nil {
-			return v, nil
-		}
-	}
-	{
-		var v float64
-		if err := gcv.Decode(&v); err == nil {
-			return v, nil
-		}
-	}
-	{
-		var v bool
-		if err := gcv.Decode(&v); err == nil {
-			return v, nil
-		}
-	}
-	{
-		var v time.Time
-		if err := gcv.Decode(&v); err == nil {
-			return v, nil
-		}
-	}
-	return nil, fmt.Errorf("unsupported column type: %v", gcv.Type)
-}
@@ -0,0 +1,908 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	sppb "cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/errkind"
+	"google.golang.org/protobuf/types/known/structpb"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCompareNumbersTolerance(t *testing.T) {
+	tests := []struct {
+		name     string
+		actual   any
+		expected any
+		opts     config.ComparisonOptions
+		wantErr  bool
+	}{
+		{"exact", 1.5, 1.5, config.ComparisonOptions{}, false},
+		{"no tolerance", 1.5000001, 1.5, config.ComparisonOptions{}, true},
+		{"absolute", 1.5000001, 1.5, config.ComparisonOptions{FloatTolerance: tolerance(1e-6)}, false},
+		{"absolute too large", 1.51, 1.5, config.ComparisonOptions{FloatTolerance: tolerance(1e-6)}, true},
+		{"relative large magnitude", 1_000_000_500.0, 1e9, config.ComparisonOptions{RelativeTolerance: tolerance(0.001)}, false},
+		{"relative small magnitude", 0.0010005, 0.001, config.ComparisonOptions{RelativeTolerance: tolerance(0.001)}, false},
+		{"relative exceeded", 1.01, 1.0, config.ComparisonOptions{RelativeTolerance: tolerance(0.001)}, true},
+		{"int actual float expected", int64(100), 100.05, config.ComparisonOptions{FloatTolerance: tolerance(0.1)}, false},
+		{"ints stay exact", int64(101), 100, config.ComparisonOptions{RelativeTolerance: tolerance(0.1)}, true},
+	}
+	for _, tt := range tests {
+		err := compareNumbers(tt.actual, tt.expected, tt.opts)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got err=%v, wantErr=%v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCompareNumeric(t *testing.T) {
+	rat := func(s string) *big.Rat {
+		r, ok := new(big.Rat).SetString(s)
+		if !ok {
+			t.Fatalf("bad rat %q", s)
+		}
+		return r
+	}
+	tests := []struct {
+		name     string
+		actual   string
+		expected any
+		mode     string
+		wantErr  bool
+	}{
+		{"exact string", "12.340000000", "12.34", "", false},
+		{"exact float", "0.1", 0.1, "exact", false},
+		{"exact mismatch", "12.345", "12.34", "", true},
+		{"round cents", "12.345", "12.35", "round(2)", false},
+		{"round negative", "-12.345", -12.35, "round(2)", false},
+		{"round mismatch", "12.344", "12.35", "round(2)", true},
+		{"round zero places", "99.5", 100, "round(0)", false},
+		{"invalid mode", "1", 1, "round(x)", true},
+	}
+	for _, tt := range tests {
+		err := compareNumeric(rat(tt.actual), tt.expected, config.ComparisonOptions{NumericMode: tt.mode})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got err=%v, wantErr=%v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	opts := config.ComparisonOptions{NumericMode: "tolerance", FloatTolerance: tolerance(0.01)}
+	if err := compareNumeric(rat("10.005"), "10", opts); err != nil {
+		t.Errorf("Expected tolerance mode to accept 10.005 vs 10: %v", err)
+	}
+}
+
+func TestCompareNumbersScientificNotation(t *testing.T) {
+	tests := []struct {
+		actual   any
+		expected any
+		wantErr  bool
+	}{
+		{int64(1500000), 1.5e6, false},
+		{int64(1500000), "1.5e6", false},
+		{int64(1500001), "1.5e6", true},
+		{1500000.0, "1.5E+6", false},
+		{int64(42), "42", false},
+		{int64(42), "forty-two", true},
+	}
+	for _, tt := range tests {
+		err := compareNumbers(tt.actual, tt.expected, config.ComparisonOptions{})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("compareNumbers(%v, %v): got err=%v, wantErr=%v", tt.actual, tt.expected, err, tt.wantErr)
+		}
+	}
+
+	r, _ := new(big.Rat).SetString("1500000")
+	for _, e := range []any{1.5e6, "1.5e6"} {
+		if err := compareNumeric(r, e, config.ComparisonOptions{}); err != nil {
+			t.Errorf("compareNumeric(1500000, %v): %v", e, err)
+		}
+	}
+}
+
+func TestCompareNumbersLargeIntegers(t *testing.T) {
+	var cfg struct {
+		Quoted   any `yaml:"quoted"`
+		Overflow any `yaml:"overflow"`
+		Huge     any `yaml:"huge"`
+	}
+	src := "quoted: \"9223372036854775807\"\noverflow: 9223372036854775808\nhuge: 99999999999999999999\n"
+	if err := yaml.Unmarshal([]byte(src), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := compareNumbers(int64(math.MaxInt64), cfg.Quoted, config.ComparisonOptions{}); err != nil {
+		t.Errorf("Expected quoted MaxInt64 to match: %v", err)
+	}
+	if err := compareNumbers(int64(math.MaxInt64-1), cfg.Quoted, config.ComparisonOptions{}); err == nil {
+		t.Error("Expected MaxInt64-1 not to match MaxInt64")
+	}
+	for _, e := range []any{cfg.Overflow, cfg.Huge, "9223372036854775808"} {
+		err := compareNumbers(int64(math.MaxInt64), e, config.ComparisonOptions{})
+		if err == nil || !strings.Contains(err.Error(), "INT64") {
+			t.Errorf("Expected INT64 range error for %v (%T), got %v", e, e, err)
+		}
+	}
+}
+
+func TestBooleanCoercion(t *testing.T) {
+	strict := NewValidator(&config.Config{}, nil)
+	coercing := NewValidator(&config.Config{Options: config.ComparisonOptions{CoerceBooleans: &yes}}, nil)
+
+	for _, e := range []any{"true", "1", "TRUE"} {
+		if err := strict.validateData(true, e); err == nil {
+			t.Errorf("Expected %q to be rejected without coercion", e)
+		}
+		if err := coercing.validateData(true, e); err != nil {
+			t.Errorf("Expected %q to match true with coercion: %v", e, err)
+		}
+	}
+	if err := coercing.validateData(spanner.NullBool{Bool: false, Valid: true}, "0"); err != nil {
+		t.Errorf("Expected \"0\" to match false: %v", err)
+	}
+	if err := coercing.validateData(true, "yes"); err == nil {
+		t.Error("Expected \"yes\" to be rejected")
+	}
+}
+
+// yes is a true value to point boolean options at.
+var yes = true
+
+// tolerance points a tolerance option at f.
+func tolerance(f float64) *float64 { return &f }
+
+func TestRunInterrupted(t *testing.T) {
+	cfg := &config.Config{Tables: map[string]config.TableConfig{"Users": {}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	res := NewValidator(cfg, nil).Run(ctx)
+	if !res.Interrupted || len(res.Tables) != 0 {
+		t.Fatalf("Expected an interrupted run without tables, got %+v", res)
+	}
+	if err := res.Err(); err == nil || !strings.Contains(err.Error(), "interrupted") {
+		t.Errorf("Expected interrupted error, got %v", err)
+	}
+}
+
+func TestGraceContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	graced, stop := graceContext(ctx, 20*time.Millisecond)
+	defer stop()
+
+	cancel()
+	if graced.Err() != nil {
+		t.Fatal("Expected work in progress to outlive the cancellation")
+	}
+	select {
+	case <-graced.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the context to be cancelled after the grace period")
+	}
+}
+
+func TestValidatorOptions(t *testing.T) {
+	tables := make(map[string]config.TableConfig)
+	for _, name := range []string{"A", "B", "C", "D", "E"} {
+		tables[name] = config.TableConfig{When: "false"}
+	}
+	cfg := &config.Config{Tables: tables, Options: config.ComparisonOptions{CoerceBooleans: &yes}}
+
+	var reported []string
+	v := NewValidator(cfg, nil,
+		WithConcurrency(3),
+		WithReporter(func(tr TableResult) { reported = append(reported, tr.Table) }),
+		WithComparisonOptions(config.ComparisonOptions{}),
+		WithQueryTimeout(time.Second),
+	)
+	res := v.Run(context.Background())
+	var names []string
+	for _, tr := range res.Tables {
+		names = append(names, tr.Table)
+	}
+	if strings.Join(names, ",") != "A,B,C,D,E" {
+		t.Errorf("Expected results in table order, got %v", names)
+	}
+	sort.Strings(reported)
+	if strings.Join(reported, ",") != "A,B,C,D,E" {
+		t.Errorf("Expected every table to be reported once, got %v", reported)
+	}
+	if err := v.validateData(true, "true"); err == nil {
+		t.Error("Expected WithComparisonOptions to replace the config options")
+	}
+	if v.queryTimeout != time.Second {
+		t.Errorf("Expected the query timeout option, got %v", v.queryTimeout)
+	}
+	if NewValidator(cfg, nil, WithConcurrency(0)).concurrency != 1 {
+		t.Error("Expected concurrency below 1 to mean one")
+	}
+}
+
+func TestASCIIReports(t *testing.T) {
+	SetASCII(true)
+	defer SetASCII(false)
+
+	report := buildMismatchReport("Users", []colDiff{{column: "Name", expected: "Alice", actual: "Bob"}})
+	report += buildColumnSetMismatchReport("Users", []string{"ID"}, []string{"ID", "Name"})
+	for _, r := range report {
+		if r > 0x7F {
+			t.Fatalf("Expected ASCII-only report, got %q", report)
+		}
+	}
+	if !strings.Contains(report, "[FAIL] table Users") {
+		t.Errorf("Expected ASCII fail marker, got %q", report)
+	}
+}
+
+func TestResultWriteText(t *testing.T) {
+	res := &Result{Tables: []TableResult{
+		{Table: "Orders", Err: errors.New("unexpected row count"), Report: "✖️ table Orders: expected row does not match\n"},
+		{Table: "Products", Skipped: true},
+		{Table: "Users"},
+	}}
+	var b strings.Builder
+	if err := res.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := "✖️ table Orders: expected row does not match\n" +
+		"▸ table Products: skipped\n" +
+		"✅ table Users: passed\n"
+	if b.String() != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, b.String())
+	}
+}
+
+// TestConfigOptionsApplied checks that options parsed from a config reach the table
+// validation used by Run, including per-table overrides.
+func TestConfigOptionsApplied(t *testing.T) {
+	src := `
+options:
+  floatTolerance: 0.01
+tables:
+  Events:
+    options:
+      timestampTruncateTo: 1s
+    columns:
+      - ID: 1
+        Amount: 10.0
+        At: "2025-01-02T03:04:05Z"
+  Strict:
+    columns:
+      - ID: 1
+        Amount: 10.0
+        At: "2025-01-02T03:04:05Z"
+  Exact:
+    options:
+      floatTolerance: 0
+      timestampTruncateTo: 1s
+    columns:
+      - ID: 1
+        Amount: 10.0
+        At: "2025-01-02T03:04:05Z"
+`
+	cfg, err := config.Parse([]byte(src), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := []map[string]any{{
+		"ID":     int64(1),
+		"Amount": 10.004,
+		"At":     time.Date(2025, 1, 2, 3, 4, 5, 123456000, time.UTC),
+	}}
+	v := NewValidator(cfg, nil)
+
+	events := cfg.Tables["Events"]
+	if err := v.forTable(events).validateRows("Events", rows, nil, events); err != nil {
+		t.Errorf("Expected tolerance and truncation to apply: %v", err)
+	}
+	strict := cfg.Tables["Strict"]
+	err = v.forTable(strict).validateRows("Strict", rows, nil, strict)
+	if err == nil || !strings.Contains(ReportOf(err), "column: At") {
+		t.Errorf("Expected untruncated timestamp mismatch, got %v", err)
+	}
+	exact := cfg.Tables["Exact"]
+	err = v.forTable(exact).validateRows("Exact", rows, nil, exact)
+	if err == nil || !strings.Contains(ReportOf(err), "column: Amount") {
+		t.Errorf("Expected the table's floatTolerance: 0 to override the global one, got %v", err)
+	}
+}
+
+func TestOrderedRows(t *testing.T) {
+	var cols config.Rows
+	src := "- ID: 1\n- !anyRow\n- ID: 3\n"
+	if err := yaml.Unmarshal([]byte(src), &cols); err != nil {
+		t.Fatal(err)
+	}
+	ordered := false
+	cfg := &config.Config{Options: config.ComparisonOptions{AllowUnorderedRows: &ordered}}
+	table := config.TableConfig{Columns: cols}
+	v := NewValidator(cfg, nil)
+
+	rows := func(ids ...int64) []map[string]any {
+		var out []map[string]any
+		for _, id := range ids {
+			out = append(out, map[string]any{"ID": id})
+		}
+		return out
+	}
+	tests := []struct {
+		name    string
+		actual  []map[string]any
+		wantErr string
+	}{
+		{"in order", rows(1, 2, 3), ""},
+		{"wildcard position", rows(1, 9, 3), ""},
+		{"order only", rows(3, 2, 1), "match only in a different order"},
+		{"mismatch", rows(1, 2, 4), "expected row 2 does not match row 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.forTable(table).validateRows("Users", tt.actual, nil, table)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected match, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// the same rows pass when the table allows any order
+	unordered := true
+	table.Options = &config.ComparisonOptions{AllowUnorderedRows: &unordered}
+	if err := v.forTable(table).validateRows("Users", rows(3, 2, 1), nil, table); err != nil {
+		t.Errorf("Expected unordered match with table override, got %v", err)
+	}
+}
+
+func TestRowStrategies(t *testing.T) {
+	expected := config.Rows{
+		{"ID": 1, "Name": "Alice"},
+		{"ID": 2, "Name": "Bob"},
+	}
+	row := func(id int64, name string) map[string]any {
+		return map[string]any{"ID": id, "Name": name}
+	}
+	pk := []string{"ID"}
+
+	tests := []struct {
+		strategy string
+		actual   []map[string]any
+		wantErr  string
+	}{
+		{config.StrategyStrict, []map[string]any{row(2, "Bob"), row(1, "Alice")}, ""},
+		{config.StrategyStrict, []map[string]any{row(1, "Alice"), row(2, "Bob"), row(3, "Carol")}, "expected 2, got 3; e.g. {ID=3, Name=Carol}"},
+		{config.StrategySubset, []map[string]any{row(1, "Alice"), row(2, "Bob"), row(3, "Carol")}, ""},
+		{config.StrategySubset, []map[string]any{row(1, "Alice")}, "too few rows"},
+		{config.StrategyPrimaryKey, []map[string]any{row(2, "Bob"), row(1, "Alice")}, ""},
+		{config.StrategyPrimaryKey, []map[string]any{row(1, "Alice"), row(2, "Robert")}, "row with primary key 2 does not match"},
+		{config.StrategyPrimaryKey, []map[string]any{row(1, "Alice"), row(3, "Bob")}, "no row with primary key 2"},
+		{config.StrategyPrimaryKey, []map[string]any{row(1, "Alice"), row(2, "Bob"), row(3, "Carol")}, "(keys 3); e.g. {ID=3, Name=Carol}"},
+		{config.StrategyOrdered, []map[string]any{row(1, "Alice"), row(2, "Bob")}, ""},
+		{config.StrategyOrdered, []map[string]any{row(2, "Bob"), row(1, "Alice")}, "different order"},
+	}
+	for _, tt := range tests {
+		table := config.TableConfig{Columns: expected, Strategy: tt.strategy}
+		v := NewValidator(&config.Config{}, nil)
+		err := v.forTable(table).validateRows("Users", tt.actual, pk, table)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: expected match, got %v", tt.strategy, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.strategy, tt.wantErr, err)
+		}
+	}
+}
+
+func TestRowMatching(t *testing.T) {
+	cfg, err := config.Parse([]byte("tables:\n  T:\n    columns:\n      - S: !any\n      - S: 1\n"), ".")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	table := cfg.Tables["T"]
+	v := NewValidator(cfg, nil)
+	// the !any row must not take the only row S: 1 matches
+	if err := v.forTable(table).validateRows("T", []map[string]any{{"S": int64(1)}, {"S": int64(2)}}, nil, table); err != nil {
+		t.Errorf("Expected rows to match, got %v", err)
+	}
+	err = v.forTable(table).validateRows("T", []map[string]any{{"S": int64(2)}, {"S": int64(3)}}, nil, table)
+	if err == nil || !strings.Contains(err.Error(), "expected row 2 not found in table T") {
+		t.Errorf("Expected row 2 to be reported, got %v", err)
+	}
+}
+
+func TestAllowExtraColumns(t *testing.T) {
+	table := config.TableConfig{Columns: config.Rows{{"ID": 1, "Name": "Alice"}}}
+	actual := []map[string]any{{"ID": int64(1), "Name": "Alice", "UpdatedAt": time.Now()}}
+
+	strict := NewValidator(&config.Config{}, nil)
+	if err := strict.forTable(table).validateRows("Users", actual, nil, table); err == nil {
+		t.Error("Expected extra column to fail without allowExtraColumns")
+	}
+
+	table.Options = &config.ComparisonOptions{AllowExtraColumns: &yes}
+	if err := strict.forTable(table).validateRows("Users", actual, nil, table); err != nil {
+		t.Errorf("Expected extra column to be ignored, got %v", err)
+	}
+	actual[0]["Name"] = "Bob"
+	if err := strict.forTable(table).validateRows("Users", actual, nil, table); err == nil {
+		t.Error("Expected listed columns to still be compared")
+	}
+
+	// a table opts out of a global true
+	actual[0]["Name"] = "Alice"
+	no := false
+	relaxed := NewValidator(&config.Config{Options: config.ComparisonOptions{AllowExtraColumns: &yes}}, nil)
+	table.Options = &config.ComparisonOptions{AllowExtraColumns: &no}
+	if err := relaxed.forTable(table).validateRows("Users", actual, nil, table); err == nil {
+		t.Error("Expected the table's allowExtraColumns: false to override the global option")
+	}
+}
+
+func TestRowExamples(t *testing.T) {
+	rows := []map[string]any{
+		{"ID": int64(3), "A": "a", "B": "b", "C": "c", "D": "d", "E": "e"},
+		{"ID": int64(4), "A": "a"},
+		{"ID": int64(5), "A": "a"},
+	}
+	two, none := 2, 0
+	tests := []struct {
+		examples *int
+		pk       []string
+		want     string
+	}{
+		{nil, []string{"ID"}, "; e.g. {ID=3, A=a, B=b, C=c, D=d, ...}, {ID=4, A=a}, {ID=5, A=a}"},
+		{&two, []string{"ID"}, "; e.g. {ID=3, A=a, B=b, C=c, D=d, ...}, {ID=4, A=a} (and 1 more)"},
+		{&two, nil, "; e.g. {A=a, B=b, C=c, D=d, ...}, {A=a, ID=4} (and 1 more)"},
+		{&none, []string{"ID"}, ""},
+	}
+	for _, tt := range tests {
+		v := NewValidator(&config.Config{Options: config.ComparisonOptions{ExtraRowExamples: tt.examples}}, nil)
+		if got := v.rowExamples(rows, tt.pk); got != tt.want {
+			t.Errorf("rowExamples(%v) = %q, want %q", tt.pk, got, tt.want)
+		}
+	}
+}
+
+func TestAllowMissingColumns(t *testing.T) {
+	src := `
+tables:
+  UserView:
+    allowMissingColumns:
+      Nickname: null
+      Tier: 1
+    columns:
+      - ID: 1
+        Nickname: null
+        Tier: 1
+`
+	cfg, err := config.Parse([]byte(src), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := cfg.Tables["UserView"]
+	v := NewValidator(cfg, nil)
+
+	old := []map[string]any{{"ID": int64(1)}}
+	if err := v.forTable(table).validateRows("UserView", old, nil, table); err != nil {
+		t.Errorf("Expected defaults for missing columns, got %v", err)
+	}
+	if _, ok := old[0]["Nickname"]; ok {
+		t.Error("Expected the fetched rows to be left unchanged")
+	}
+
+	current := []map[string]any{{"ID": int64(1), "Nickname": spanner.NullString{StringVal: "al", Valid: true}, "Tier": int64(1)}}
+	if err := v.forTable(table).validateRows("UserView", current, nil, table); err == nil {
+		t.Error("Expected present columns to be compared against the expected row")
+	}
+}
+
+func TestIgnoreColumns(t *testing.T) {
+	src := `
+tables:
+  Users:
+    ignoreColumns: [CreatedAt]
+    columns:
+      - ID: 1
+        Name: "Alice"
+`
+	cfg, err := config.Parse([]byte(src), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := &FakeDatabase{Tables: map[string][]map[string]any{"Users": {{
+		"ID":        spanner.NullInt64{Int64: 1, Valid: true},
+		"Name":      spanner.NullString{StringVal: "Alice", Valid: true},
+		"CreatedAt": spanner.NullTime{Time: time.Now(), Valid: true},
+	}}}}
+	if err := NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background()).Err(); err != nil {
+		t.Errorf("Expected the ignored column to be left out, got %v", err)
+	}
+	if _, ok := db.Tables["Users"][0]["CreatedAt"]; !ok {
+		t.Error("Expected the fake's rows to be left unchanged")
+	}
+
+	table := cfg.Tables["Users"]
+	table.Columns[0]["CreatedAt"] = "2024-01-01T00:00:00Z"
+	err = NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background()).Err()
+	if !errors.Is(err, errkind.ErrConfig) || !strings.Contains(err.Error(), "line 6: table Users: expected row sets column CreatedAt, which is in ignoreColumns") {
+		t.Errorf("Expected an ignored expected column to be rejected, got %v", err)
+	}
+	delete(table.Columns[0], "CreatedAt")
+
+	table.Strategy = config.StrategyPrimaryKey
+	table.IgnoreColumns = []string{"ID"}
+	cfg.Tables["Users"] = table
+	db.PrimaryKeys = map[string][]string{"Users": {"ID"}}
+	err = NewValidator(cfg, nil, WithDatabase(db)).Run(context.Background()).Err()
+	if err == nil || !strings.Contains(err.Error(), "primary key column ID cannot be ignored") {
+		t.Errorf("Expected an ignored primary key column to be rejected, got %v", err)
+	}
+}
+
+func TestIgnoredRows(t *testing.T) {
+	src := `
+tables:
+  Users:
+    columns:
+      - ID: 1
+      - ID: 99
+        __ignore: true
+      - ID: 98
+  Orders:
+    skipRows: [2]
+    columns:
+      - ID: 1
+      - ID: 99
+`
+	cfg, err := config.Parse([]byte(src), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewValidator(cfg, nil)
+	actual := []map[string]any{{"ID": int64(1)}, {"ID": int64(2)}, {"ID": int64(3)}}
+
+	users := cfg.Tables["Users"]
+	if got := users.Columns.Ignored(); len(got) != 1 || got[0] != 2 {
+		t.Errorf("Expected row 2 to be ignored, got %v", got)
+	}
+	if err := v.forTable(users).validateRows("Users", actual, nil, users); err == nil || !strings.Contains(err.Error(), "expected row 2 not found") {
+		t.Errorf("Expected the row after the ignored one to be checked, got %v", err)
+	}
+	actual[2]["ID"] = int64(98)
+	if err := v.forTable(users).validateRows("Users", actual, nil, users); err != nil {
+		t.Errorf("Expected ignored row to stand for any row, got %v", err)
+	}
+
+	orders := cfg.Tables["Orders"]
+	if err := v.forTable(orders).validateRows("Orders", actual[:2], nil, orders); err != nil {
+		t.Errorf("Expected skipRows to ignore row 2, got %v", err)
+	}
+	orders.SkipRows = []int{3}
+	if err := v.forTable(orders).validateRows("Orders", actual[:2], nil, orders); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("Expected out of range error, got %v", err)
+	}
+}
+
+func TestExpectedFailures(t *testing.T) {
+	src := `
+tables:
+  Users:
+    columns:
+      - ID: 1
+      - ID: 2
+        __expectedFailure: "JIRA-1"
+      - ID: 3
+        __expectedFailure: "JIRA-2"
+`
+	cfg, err := config.Parse([]byte(src), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := cfg.Tables["Users"]
+	v := NewValidator(cfg, nil)
+	actual := []map[string]any{{"ID": int64(1)}, {"ID": int64(3)}, {"ID": int64(7)}}
+
+	if err := v.forTable(users).validateRows("Users", actual, nil, users); err != nil {
+		t.Errorf("Expected marked rows not to fail the table, got %v", err)
+	}
+	xfail, xpass := v.forTable(users).checkExpectedFailures(actual, users)
+	if len(xfail) != 1 || xfail[0] != "row 2 (JIRA-1)" {
+		t.Errorf("Expected row 2 to fail as expected, got %v", xfail)
+	}
+	if len(xpass) != 1 || xpass[0] != "row 3 (JIRA-2)" {
+		t.Errorf("Expected row 3 to pass unexpectedly, got %v", xpass)
+	}
+
+	res := &Result{Tables: []TableResult{{Table: "Users", Err: errors.New("boom"), ExpectedFailure: "JIRA-3"}}}
+	if err := res.Err(); err != nil {
+		t.Errorf("Expected an expected table failure not to fail the run, got %v", err)
+	}
+}
+
+func TestFailureMessages(t *testing.T) {
+	src := `
+tables:
+  Users:
+    message: "seeded by migration 042"
+    columns:
+      - ID: 1
+        __message: "admin user"
+`
+	cfg, err := config.Parse([]byte(src), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := cfg.Tables["Users"]
+	v := NewValidator(cfg, nil)
+
+	if err := v.forTable(users).validateRows("Users", []map[string]any{{"ID": int64(1)}}, nil, users); err != nil {
+		t.Fatalf("Expected __message not to be compared as a column, got %v", err)
+	}
+	err = withMessage(users.Message, v.forTable(users).validateRows("Users", []map[string]any{{"ID": int64(2)}}, nil, users))
+	if err == nil || !strings.HasPrefix(err.Error(), "seeded by migration 042: admin user: line 6: expected row 1 not found") {
+		t.Errorf("Expected table and row messages and the row line before the error, got %v", err)
+	}
+	if report := ReportOf(err); !strings.HasPrefix(report, "seeded by migration 042\nadmin user\nline 6\n") {
+		t.Errorf("Expected messages before the report, got %q", report)
+	}
+}
+
+func TestSchemaAwareCoercion(t *testing.T) {
+	v := NewValidator(&config.Config{}, nil)
+
+	gcv := &spanner.GenericColumnValue{Type: &sppb.Type{Code: sppb.TypeCode_INT64}, Value: structpb.NewStringValue("42")}
+	got, err := decodeGenericValue(gcv)
+	if err != nil {
+		t.Fatalf("decode INT64: %v", err)
+	}
+	if err := v.validateData(got, "42"); err != nil {
+		t.Errorf("Expected \"42\" to match an INT64 column: %v", err)
+	}
+
+	gcv = &spanner.GenericColumnValue{Type: &sppb.Type{Code: sppb.TypeCode_STRING}, Value: structpb.NewStringValue("42")}
+	if got, err = decodeGenericValue(gcv); err != nil {
+		t.Fatalf("decode STRING: %v", err)
+	}
+	if _, ok := got.(spanner.NullString); !ok {
+		t.Fatalf("Expected a STRING column to decode as NullString, got %T", got)
+	}
+	if err := v.validateData(got, 42); err == nil || !strings.Contains(err.Error(), "quote it") {
+		t.Errorf("Expected unquoted 42 to be rejected for a STRING column with a hint, got %v", err)
+	}
+	if err := v.validateData(got, "42"); err != nil {
+		t.Errorf("Expected \"42\" to match a STRING column: %v", err)
+	}
+
+	// JSON text in a STRING column is compared as JSON, unless types are strict
+	if err := v.validateData(`{"b": 2, "a": 1}`, `{"a":1,"b":2}`); err != nil {
+		t.Errorf("Expected JSON in a STRING column to match regardless of key order: %v", err)
+	}
+	if err := v.validateData(`{"a": 1}`, `{"a":2}`); err == nil {
+		t.Error("Expected differing JSON in a STRING column to fail")
+	}
+	strict := NewValidator(&config.Config{Options: config.ComparisonOptions{StrictTypes: &yes}}, nil)
+	if err := strict.validateData(`{"a": 1}`, `{"a":1}`); err == nil {
+		t.Error("Expected STRING comparison to be exact with strict types")
+	}
+}
+
+func TestStrictTypes(t *testing.T) {
+	loose := NewValidator(&config.Config{Options: config.ComparisonOptions{CoerceBooleans: &yes}}, nil)
+	strict := NewValidator(&config.Config{Options: config.ComparisonOptions{CoerceBooleans: &yes, StrictTypes: &yes}}, nil)
+
+	tests := []struct {
+		name     string
+		actual   any
+		expected any
+		strictOK bool
+	}{
+		{"int to int", int64(3), 3, true},
+		{"int to float", int64(3), 3.0, false},
+		{"float to int", 3.0, 3, false},
+		{"float to float", 3.0, 3.0, true},
+		{"numeric string for INT64", int64(42), "42", false},
+		{"boolean string", true, "true", false},
+		{"NUMERIC string", spanner.NullNumeric{Numeric: *big.NewRat(1234, 100), Valid: true}, "12.34", true},
+		{"NUMERIC float", spanner.NullNumeric{Numeric: *big.NewRat(1, 2), Valid: true}, 0.5, false},
+		{"raw bytes text", []byte("abc"), "abc", false},
+		{"base64 bytes", []byte("abc"), "YWJj", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := loose.validateData(tt.actual, tt.expected); err != nil {
+				t.Errorf("Expected a match without strict types: %v", err)
+			}
+			err := strict.validateData(tt.actual, tt.expected)
+			if tt.strictOK && err != nil {
+				t.Errorf("Expected a match with strict types: %v", err)
+			}
+			if !tt.strictOK && err == nil {
+				t.Error("Expected strict types to reject the coercion")
+			}
+		})
+	}
+}
+
+func TestJSONArrayOrder(t *testing.T) {
+	ordered := config.ComparisonOptions{}
+	unordered := config.ComparisonOptions{JSONArrayOrder: config.JSONArrayOrderIgnore}
+	actual := map[string]any{"tags": []any{"b", "a", "a"}, "items": []any{map[string]any{"id": 2.0}, map[string]any{"id": 1.0}}}
+
+	tests := []struct {
+		name        string
+		expected    any
+		orderedOK   bool
+		unorderedOK bool
+	}{
+		{"same order", `{"tags": ["b", "a", "a"], "items": [{"id": 2}, {"id": 1}]}`, true, true},
+		{"reordered", `{"tags": ["a", "b", "a"], "items": [{"id": 1}, {"id": 2}]}`, false, true},
+		{"different multiplicity", `{"tags": ["a", "b", "b"], "items": [{"id": 1}, {"id": 2}]}`, false, false},
+		{"yaml mapping", map[string]any{"tags": []any{"a", "a", "b"}, "items": []any{map[string]any{"id": 1}, map[string]any{"id": 2}}}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := compareJSON(actual, tt.expected, ordered); (err == nil) != tt.orderedOK {
+				t.Errorf("strict order: got err=%v, want match=%v", err, tt.orderedOK)
+			}
+			if err := compareJSON(actual, tt.expected, unordered); (err == nil) != tt.unorderedOK {
+				t.Errorf("ignored order: got err=%v, want match=%v", err, tt.unorderedOK)
+			}
+		})
+	}
+
+	// 1.0 is within the tolerance of both 1.1 and 1.2, so pairing it first must not strand 1.2
+	tolerant := config.ComparisonOptions{JSONArrayOrder: config.JSONArrayOrderIgnore, FloatTolerance: tolerance(0.15)}
+	if err := compareJSON(`[1.1, 1.0]`, `[1.0, 1.2]`, tolerant); err != nil {
+		t.Errorf("Expected tolerant elements to pair up, got %v", err)
+	}
+	if err := compareJSON(`[1.1, 1.0]`, `[1.0, 1.4]`, tolerant); err == nil {
+		t.Error("Expected an element outside the tolerance to fail")
+	}
+
+	if _, err := config.Parse([]byte("options:\n  jsonArrayOrder: sorted\n"), "."); err == nil {
+		t.Error("Expected an unknown jsonArrayOrder to be rejected")
+	}
+}
+
+func TestJSONNumberTolerance(t *testing.T) {
+	actual := map[string]any{"price": 0.30000000000000004, "qty": 3.0, "history": []any{1.0000001, 2.0}}
+	expected := `{"price": 0.3, "qty": 3, "history": [1, 2]}`
+
+	if err := compareJSON(actual, expected, config.ComparisonOptions{}); err == nil {
+		t.Error("Expected exact comparison to reject the rounding differences")
+	}
+	if err := compareJSON(actual, expected, config.ComparisonOptions{FloatTolerance: tolerance(1e-6)}); err != nil {
+		t.Errorf("Expected a match within floatTolerance: %v", err)
+	}
+	if err := compareJSON(actual, expected, config.ComparisonOptions{RelativeTolerance: tolerance(1e-6)}); err != nil {
+		t.Errorf("Expected a match within relativeTolerance: %v", err)
+	}
+	if err := compareJSON(actual, `{"price": 0.4, "qty": 3, "history": [1, 2]}`, config.ComparisonOptions{FloatTolerance: tolerance(1e-6)}); err == nil {
+		t.Error("Expected a difference beyond the tolerance to be rejected")
+	}
+}
+
+func TestCountTolerance(t *testing.T) {
+	count := int64(1000)
+	tests := []struct {
+		tolerance string
+		n         int64
+		wantErr   bool
+	}{
+		{"", 1000, false},
+		{"", 999, true},
+		{"1%", 990, false},
+		{"1%", 1010, false},
+		{"1%", 989, true},
+		{"1%", 1011, true},
+		{"5", 995, false},
+		{"5", 1006, true},
+		{"0.5 %", 1005, false},
+	}
+	for _, tt := range tests {
+		tc := config.TableConfig{Count: &count, CountTolerance: tt.tolerance}
+		if err := countError("Events", tt.n, tc); (err != nil) != tt.wantErr {
+			t.Errorf("countTolerance %q with %d rows: got err=%v, wantErr=%v", tt.tolerance, tt.n, err, tt.wantErr)
+		}
+	}
+
+	for _, src := range []string{
+		"tables:\n  T:\n    countTolerance: 1%\n",
+		"tables:\n  T:\n    count: 10\n    countTolerance: ten\n",
+		"tables:\n  T:\n    count: 10\n    countTolerance: -1%\n",
+	} {
+		if _, err := config.Parse([]byte(src), "."); err == nil {
+			t.Errorf("Expected %q to be rejected", src)
+		}
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	v := NewValidator(&config.Config{}, nil)
+	cfg, err := config.Parse([]byte(`tables:
+  Orders:
+    distribution:
+      column: Status
+      expect: {1: 2, 2: 1, null: 1}
+`), ".")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	d := *cfg.Tables["Orders"].Distribution
+	group := func(value any, n int64) map[string]any {
+		return map[string]any{"value": value, "n": spanner.NullInt64{Int64: n, Valid: true}}
+	}
+
+	tests := []struct {
+		name    string
+		groups  []map[string]any
+		wantErr string
+	}{
+		{"match", []map[string]any{group(spanner.NullInt64{Int64: 2, Valid: true}, 1), group(spanner.NullInt64{}, 1), group(spanner.NullInt64{Int64: 1, Valid: true}, 2)}, ""},
+		{"wrong count", []map[string]any{group(spanner.NullInt64{Int64: 1, Valid: true}, 3), group(spanner.NullInt64{Int64: 2, Valid: true}, 1), group(spanner.NullInt64{}, 1)}, "1: expected 2, got 3"},
+		{"missing bucket", []map[string]any{group(spanner.NullInt64{Int64: 1, Valid: true}, 2), group(spanner.NullInt64{}, 1)}, "2: expected 1, got 0"},
+		{"unexpected bucket", []map[string]any{group(spanner.NullInt64{Int64: 1, Valid: true}, 2), group(spanner.NullInt64{Int64: 2, Valid: true}, 1), group(spanner.NullInt64{}, 1), group(spanner.NullInt64{Int64: 3, Valid: true}, 4)}, "3: expected 0, got 4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.distributionError("Orders", tt.groups, d)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected a match: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if _, err := config.Parse([]byte("tables:\n  T:\n    distribution: {expect: {a: 1}}\n"), "."); err == nil {
+		t.Error("Expected a distribution without column to be rejected")
+	}
+}
+
+func TestCollector(t *testing.T) {
+	var reported int
+	c := newCollector(50, func(TableResult) { reported++ })
+	var wg sync.WaitGroup
+	for i := 49; i >= 0; i-- {
+		if i == 7 {
+			continue // never ran
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.add(i, TableResult{Table: fmt.Sprintf("T%02d", i)})
+		}()
+	}
+	wg.Wait()
+
+	res := c.result()
+	if len(res.Tables) != 49 || reported != 49 {
+		t.Fatalf("Expected 49 results and reports, got %d and %d", len(res.Tables), reported)
+	}
+	if !slices.IsSortedFunc(res.Tables, func(a, b TableResult) int { return strings.Compare(a.Table, b.Table) }) {
+		t.Errorf("Expected results in slot order, got %v", res.Tables)
+	}
+}
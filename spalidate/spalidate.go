@@ -0,0 +1,232 @@
+// Package spalidate validates Cloud Spanner data against spalidate configurations from Go
+// code, such as integration tests, without running the binary:
+//
+//	cfg, err := spalidate.LoadConfig("testdata/expected.yaml")
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	if err := spalidate.Validate(ctx, client, cfg).Err(); err != nil {
+//		t.Fatal(err)
+//	}
+//
+// Its API is stable; the packages under internal/ may change between releases.
+package spalidate
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/errkind"
+	spannerClient "github.com/nu0ma/spalidate/internal/spanner"
+	"github.com/nu0ma/spalidate/internal/validator"
+)
+
+// Config is a loaded validation configuration, in the format the CLI reads.
+type Config = config.Config
+
+// TableConfig holds the assertions of one table, for configurations built in Go.
+type TableConfig = config.TableConfig
+
+// ComparisonOptions tune how actual values are compared with expected values, like a
+// config's `options` block.
+type ComparisonOptions = config.ComparisonOptions
+
+// ConfigErrors lists the problems of a configuration that failed to load, for errors.As
+// on the errors of LoadConfig and ParseConfig when there are several.
+type ConfigErrors = config.Errors
+
+// LoadConfig reads a configuration file. Relative paths in it, such as external row
+// sources, are resolved against the file's directory.
+func LoadConfig(path string) (*Config, error) {
+	return config.LoadConfig(path)
+}
+
+// ParseConfig decodes a YAML (or JSON) configuration. Relative paths in it are resolved
+// against baseDir.
+func ParseConfig(data []byte, baseDir string) (*Config, error) {
+	return config.Parse(data, baseDir)
+}
+
+// Sentinel errors classifying failures, for errors.Is on Result.Err and TableResult.Err.
+var (
+	ErrConfig        = errkind.ErrConfig
+	ErrConnection    = errkind.ErrConnection
+	ErrTableNotFound = errkind.ErrTableNotFound
+	ErrRowMismatch   = errkind.ErrRowMismatch
+)
+
+// Database is what validation reads through. Validate reads through its Spanner client
+// unless WithDatabase substitutes another Database, such as a FakeDatabase.
+type Database = validator.Database
+
+// ReadOptions select the rows returned by Database.Read.
+type ReadOptions = validator.ReadOptions
+
+// TableSchema is what validation needs to know of a table's schema.
+type TableSchema = validator.TableSchema
+
+// GeneratedColumn describes a generated column, as returned by Database.GeneratedColumns.
+type GeneratedColumn = spannerClient.GeneratedColumn
+
+// DataChange is a row change read from a change stream by Database.ReadChangeStream.
+type DataChange = spannerClient.DataChange
+
+// FakeDatabase is an in-memory Database for unit tests that need no emulator:
+//
+//	db := &spalidate.FakeDatabase{Tables: map[string][]map[string]any{
+//		"Users": {{"ID": int64(1), "Name": "Alice"}},
+//	}}
+//	res := spalidate.Validate(ctx, nil, cfg, spalidate.WithDatabase(db))
+//
+// It cannot run SQL: where filters are rejected, and queries such as those of count
+// assertions must be registered in Queries.
+type FakeDatabase = validator.FakeDatabase
+
+type options struct {
+	concurrency   int
+	queryTimeout  time.Duration
+	readTimestamp time.Time
+	anchor        time.Time
+	db            Database
+	comparison    *ComparisonOptions
+	reporter      func(TableResult)
+}
+
+// Option configures Validate.
+type Option func(*options)
+
+// WithConcurrency validates up to n tables in parallel; the default is one at a time.
+func WithConcurrency(n int) Option {
+	return func(o *options) { o.concurrency = n }
+}
+
+// WithQueryTimeout bounds each query; zero, the default, means no limit.
+func WithQueryTimeout(d time.Duration) Option {
+	return func(o *options) { o.queryTimeout = d }
+}
+
+// WithReadTimestamp reads the data as of t, which must be within the database's version
+// retention period; the default is strong reads.
+func WithReadTimestamp(t time.Time) Option {
+	return func(o *options) { o.readTimestamp = t }
+}
+
+// WithAnchor binds t, typically when the run under test started, to the @runStart
+// parameter of where filters, like the CLI's --anchor.
+func WithAnchor(t time.Time) Option {
+	return func(o *options) { o.anchor = t }
+}
+
+// WithDatabase reads through db instead of the client, which may then be nil.
+func WithDatabase(db Database) Option {
+	return func(o *options) { o.db = db }
+}
+
+// WithComparisonOptions replaces the config's global comparison options. Per-table
+// options still apply on top of them.
+func WithComparisonOptions(opts ComparisonOptions) Option {
+	return func(o *options) { o.comparison = &opts }
+}
+
+// WithReporter calls r with each table result as soon as the table is validated, e.g. to
+// stream progress. Calls are never concurrent, but with WithConcurrency they follow
+// completion order rather than table name order.
+func WithReporter(r func(TableResult)) Option {
+	return func(o *options) { o.reporter = r }
+}
+
+// Validate checks the database of client against cfg, as the CLI would. The client stays
+// open and owned by the caller. Validation failures are reported in the Result, not as an
+// error.
+func Validate(ctx context.Context, client *spanner.Client, cfg *Config, opts ...Option) *Result {
+	o := options{concurrency: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	vopts := []validator.Option{validator.WithConcurrency(o.concurrency), validator.WithAnchor(o.anchor)}
+	if o.db != nil {
+		vopts = append(vopts, validator.WithDatabase(o.db))
+	}
+	if o.comparison != nil {
+		vopts = append(vopts, validator.WithComparisonOptions(*o.comparison))
+	}
+	if o.reporter != nil {
+		vopts = append(vopts, validator.WithReporter(func(t validator.TableResult) { o.reporter(newTableResult(t)) }))
+	}
+	var c *spannerClient.Client
+	if client != nil {
+		c = spannerClient.Wrap(client, spannerClient.Options{QueryTimeout: o.queryTimeout, ReadTimestamp: o.readTimestamp})
+	}
+	return newResult(validator.NewValidator(cfg, c, vopts...).Run(ctx))
+}
+
+func newResult(res *validator.Result) *Result {
+	r := &Result{Interrupted: res.Interrupted, res: res}
+	for _, t := range res.Tables {
+		r.Tables = append(r.Tables, newTableResult(t))
+	}
+	return r
+}
+
+func newTableResult(t validator.TableResult) TableResult {
+	return TableResult{
+		Table:           t.Table,
+		Skipped:         t.Skipped,
+		Err:             t.Err,
+		Report:          t.Report,
+		ExpectedFailure: t.ExpectedFailure,
+	}
+}
+
+// Result is the outcome of a validation run.
+type Result struct {
+	// Tables holds the table results in name order, followed by the change stream
+	// assertions as "changeStream:<name>".
+	Tables []TableResult
+	// Interrupted is set when ctx was cancelled before every table ran.
+	Interrupted bool
+
+	res *validator.Result
+}
+
+// TableResult is the outcome of validating one table.
+type TableResult struct {
+	Table   string
+	Skipped bool
+	Err     error
+	// Report is the detailed mismatch report of a failing table, if any.
+	Report string
+	// ExpectedFailure is the table's expectedFailure marker when Err was expected; such a
+	// table does not count as failed.
+	ExpectedFailure string
+}
+
+// Passed reports whether the table was validated without errors.
+func (t TableResult) Passed() bool {
+	return !t.Skipped && t.Err == nil
+}
+
+// Failed returns the results of the tables that failed unexpectedly.
+func (r *Result) Failed() []TableResult {
+	var failed []TableResult
+	for _, t := range r.Tables {
+		if t.Err != nil && t.ExpectedFailure == "" {
+			failed = append(failed, t)
+		}
+	}
+	return failed
+}
+
+// Err combines the errors of the failing tables, or returns nil when the run passed.
+func (r *Result) Err() error {
+	return r.res.Err()
+}
+
+// WriteText writes the report the CLI prints: one line per table, followed by the
+// mismatch report of each failing table.
+func (r *Result) WriteText(w io.Writer) error {
+	return r.res.WriteText(w)
+}
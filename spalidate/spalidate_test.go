@@ -0,0 +1,77 @@
+package spalidate
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+)
+
+func TestResult(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`tables:
+  Users:
+    columns:
+      - {ID: 1, Name: Alice}
+  Orders:
+    count: 1
+  Archive:
+    when: "false"
+    count: 0
+`), ".")
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+	db := &FakeDatabase{
+		Tables: map[string][]map[string]any{"Users": {
+			{"ID": spanner.NullInt64{Int64: 1, Valid: true}, "Name": spanner.NullString{StringVal: "Bob", Valid: true}},
+		}},
+		Queries: map[string][]map[string]any{
+			"SELECT COUNT(*) AS n FROM Orders": {{"n": spanner.NullInt64{Int64: 1, Valid: true}}},
+		},
+	}
+	var reported []string
+	res := Validate(context.Background(), nil, cfg, WithDatabase(db), WithReporter(func(tr TableResult) {
+		reported = append(reported, tr.Table)
+	}))
+
+	if len(res.Tables) != 3 || len(reported) != 3 {
+		t.Fatalf("Expected 3 table results, got %+v (reported %v)", res.Tables, reported)
+	}
+	byName := map[string]TableResult{}
+	for _, tr := range res.Tables {
+		byName[tr.Table] = tr
+	}
+	if !byName["Archive"].Skipped || !byName["Orders"].Passed() || byName["Users"].Report == "" {
+		t.Errorf("Unexpected table results: %+v", res.Tables)
+	}
+	if failed := res.Failed(); len(failed) != 1 || failed[0].Table != "Users" {
+		t.Errorf("Expected only Users to fail, got %+v", failed)
+	}
+	if err := res.Err(); !errors.Is(err, ErrRowMismatch) {
+		t.Errorf("Expected a row mismatch, got %v", err)
+	}
+	var b strings.Builder
+	if err := res.WriteText(&b); err != nil || !strings.Contains(b.String(), "table Archive: skipped") {
+		t.Errorf("Unexpected text report %q (%v)", b.String(), err)
+	}
+}
+
+func TestWithComparisonOptions(t *testing.T) {
+	cfg, err := ParseConfig([]byte("tables:\n  Prices:\n    columns:\n      - {ID: 1, Amount: 1.0}\n"), ".")
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+	db := &FakeDatabase{Tables: map[string][]map[string]any{"Prices": {
+		{"ID": spanner.NullInt64{Int64: 1, Valid: true}, "Amount": spanner.NullFloat64{Float64: 1.05, Valid: true}},
+	}}}
+	if err := Validate(context.Background(), nil, cfg, WithDatabase(db)).Err(); err == nil {
+		t.Error("Expected the exact comparison to fail")
+	}
+	tolerance := 0.1
+	res := Validate(context.Background(), nil, cfg, WithDatabase(db), WithComparisonOptions(ComparisonOptions{FloatTolerance: &tolerance}))
+	if err := res.Err(); err != nil {
+		t.Errorf("Expected the tolerance to apply, got %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+// Package spalidatetest is a harness for integration suites that validate Spanner data
+// with spalidate. It boots one emulator per test binary and hands each test a fresh
+// database with the schema and fixtures applied:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(spalidatetest.Main(m, spalidatetest.WithSchema(schema)))
+//	}
+//
+//	func TestOrders(t *testing.T) {
+//		db := spalidatetest.NewDatabase(t, spalidatetest.WithFixtures("testdata/orders.seed.yaml"))
+//		// ... exercise the code under test against db.Client ...
+//		spalidatetest.Assert(t, db.Client, "testdata/orders.expected.yaml")
+//	}
+package spalidatetest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/spanner"
+	"github.com/apstndb/spanemuboost"
+	"github.com/nu0ma/spalidate/internal/config"
+	"github.com/nu0ma/spalidate/internal/seed"
+	spannerClient "github.com/nu0ma/spalidate/internal/spanner"
+	"github.com/nu0ma/spalidate/spalidate"
+	tcspanner "github.com/testcontainers/testcontainers-go/modules/gcloud/spanner"
+)
+
+var (
+	emulator     *tcspanner.Container
+	emulatorHost string
+	defaults     options
+)
+
+type options struct {
+	ddl             []string
+	fixtures        []string
+	emulatorOptions []spanemuboost.Option
+}
+
+// Option configures Main (defaults for every database) or a single NewDatabase call.
+type Option func(*options)
+
+// WithDDL adds schema statements applied to every new database.
+func WithDDL(statements ...string) Option {
+	return func(o *options) { o.ddl = append(o.ddl, statements...) }
+}
+
+// WithSchema adds the statements of a schema file's contents, separated by semicolons.
+// Lines starting with `--` are ignored.
+func WithSchema(schema string) Option {
+	return WithDDL(spannerClient.ParseDDL(schema)...)
+}
+
+// WithFixtures adds seed fixture files (a YAML `mutations:` list, as used by the seed
+// section of a spalidate config) written to every new database.
+func WithFixtures(paths ...string) Option {
+	return func(o *options) { o.fixtures = append(o.fixtures, paths...) }
+}
+
+// WithEmulatorOptions passes options to spanemuboost when Main starts the emulator.
+func WithEmulatorOptions(opts ...spanemuboost.Option) Option {
+	return func(o *options) { o.emulatorOptions = append(o.emulatorOptions, opts...) }
+}
+
+// Main starts the emulator, runs the tests and stops the emulator, returning the exit code
+// for os.Exit. SPANNER_EMULATOR_HOST is set so spalidate binaries started by tests connect
+// to the emulator too.
+func Main(m *testing.M, opts ...Option) int {
+	ctx := context.Background()
+	for _, opt := range opts {
+		opt(&defaults)
+	}
+
+	emuOpts := append([]spanemuboost.Option{spanemuboost.EnableInstanceAutoConfigOnly()}, defaults.emulatorOptions...)
+	em, teardown, err := spanemuboost.NewEmulator(ctx, emuOpts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "spalidatetest: failed to start emulator: %v\n", err)
+		return 1
+	}
+	defer teardown()
+
+	host, err := em.Host(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "spalidatetest: failed to get emulator host: %v\n", err)
+		return 1
+	}
+	port, err := em.MappedPort(ctx, "9010/tcp")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "spalidatetest: failed to get emulator port: %v\n", err)
+		return 1
+	}
+	emulator = em
+	emulatorHost = fmt.Sprintf("%s:%s", host, port.Port())
+	if err := os.Setenv("SPANNER_EMULATOR_HOST", emulatorHost); err != nil {
+		fmt.Fprintf(os.Stderr, "spalidatetest: failed to set SPANNER_EMULATOR_HOST: %v\n", err)
+		return 1
+	}
+
+	return m.Run()
+}
+
+// EmulatorHost returns the host:port of the emulator started by Main.
+func EmulatorHost() string {
+	return emulatorHost
+}
+
+// Database is a fresh emulator database created for one test.
+type Database struct {
+	ProjectID  string
+	InstanceID string
+	DatabaseID string
+	// Client is connected to the database and closed when the test ends.
+	Client *spanner.Client
+}
+
+// NewDatabase creates a database with a random ID, applies the DDL and fixtures given to
+// Main and opts, and drops it when the test ends.
+func NewDatabase(t testing.TB, opts ...Option) *Database {
+	t.Helper()
+	if emulator == nil {
+		t.Fatal("spalidatetest: NewDatabase requires spalidatetest.Main in TestMain")
+	}
+	ctx := context.Background()
+
+	o := options{
+		ddl:      append([]string(nil), defaults.ddl...),
+		fixtures: append([]string(nil), defaults.fixtures...),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	clients, teardown, err := spanemuboost.NewClients(ctx, emulator,
+		spanemuboost.EnableDatabaseAutoConfigOnly(),
+		spanemuboost.WithRandomDatabaseID(),
+		spanemuboost.WithSetupDDLs(o.ddl),
+	)
+	if err != nil {
+		t.Fatalf("spalidatetest: failed to create database: %v", err)
+	}
+	t.Cleanup(teardown)
+
+	for _, path := range o.fixtures {
+		mutations, err := config.LoadSeedFixture(path)
+		if err != nil {
+			t.Fatalf("spalidatetest: %v", err)
+		}
+		ms, err := seed.Mutations(&config.Seed{Mutations: mutations})
+		if err != nil {
+			t.Fatalf("spalidatetest: fixture %s: %v", path, err)
+		}
+		if _, err := clients.Client.Apply(ctx, ms); err != nil {
+			t.Fatalf("spalidatetest: failed to apply fixture %s: %v", path, err)
+		}
+	}
+
+	return &Database{
+		ProjectID:  clients.ProjectID,
+		InstanceID: clients.InstanceID,
+		DatabaseID: clients.DatabaseID,
+		Client:     clients.Client,
+	}
+}
+
+// Validate checks the database against a spalidate config file, as the CLI would.
+// The returned error lists the failing tables followed by their mismatch reports.
+func (d *Database) Validate(configPath string) error {
+	cfg, err := spalidate.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	return validate(d.Client, cfg, nil)
+}
+
+// Assert checks the database of client against a spalidate config file and marks the test
+// failed, with the mismatch report of each failing table, when it does not pass. The test
+// continues, so several assertions can report in one run. It works with any client, not
+// only those of NewDatabase.
+func Assert(t testing.TB, client *spanner.Client, configPath string, opts ...spalidate.Option) {
+	t.Helper()
+	cfg, err := spalidate.LoadConfig(configPath)
+	if err != nil {
+		t.Errorf("spalidatetest: %v", err)
+		return
+	}
+	if err := validate(client, cfg, opts); err != nil {
+		t.Errorf("spalidatetest: %s: %v", configPath, err)
+	}
+}
+
+// AssertTable is Assert for a single table configured in Go:
+//
+//	spalidatetest.AssertTable(t, db.Client, "Users", spalidate.TableConfig{
+//		Columns: []map[string]any{{"UserID": "user-001", "Name": "Alice"}},
+//	})
+func AssertTable(t testing.TB, client *spanner.Client, table string, tableConfig spalidate.TableConfig, opts ...spalidate.Option) {
+	t.Helper()
+	cfg := &spalidate.Config{Tables: map[string]spalidate.TableConfig{table: tableConfig}}
+	if err := validate(client, cfg, opts); err != nil {
+		t.Errorf("spalidatetest: %v", err)
+	}
+}
+
+// validate runs a validation and returns its error followed by the mismatch reports, so a
+// failing test shows what differed.
+func validate(client *spanner.Client, cfg *spalidate.Config, opts []spalidate.Option) error {
+	res := spalidate.Validate(context.Background(), client, cfg, opts...)
+	if err := res.Err(); err != nil {
+		var b strings.Builder
+		_ = res.WriteText(&b)
+		return fmt.Errorf("%w\n%s", err, b.String())
+	}
+	return nil
+}